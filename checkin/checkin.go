@@ -0,0 +1,224 @@
+// Package checkin lets participants check themselves in to an event by
+// sharing their location, from the bot or the web app, and flags suspicious
+// check-ins for officers to review.
+package checkin
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+	"worker-bot/geo"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	// maxSpeed is the fastest plausible travel between two check-in
+	// attempts, in meters per second (about 150 km/h).
+	maxSpeed = 150 * 1000 / 3600.0
+	// travelWindow is how far back attempts are compared for travel speed.
+	travelWindow = 6 * time.Hour
+	// minTravelDistance ignores GPS jitter when checking travel speed.
+	minTravelDistance = 1000
+	// deviceWindow is how far back another user's check-in from the same
+	// device counts as shared.
+	deviceWindow = 24 * time.Hour
+)
+
+const (
+	FlagSharedDevice     = "shared_device"
+	FlagImpossibleTravel = "impossible_travel"
+)
+
+var (
+	// ErrNoEvent is returned when the user has no registered event running.
+	ErrNoEvent = errors.New("no registered event is running")
+	// ErrAlreadyCheckedIn is returned when the user is already checked in
+	// to the nearest running event.
+	ErrAlreadyCheckedIn = errors.New("already checked in")
+)
+
+// TooFarError is returned when the point is outside the event's radius.
+type TooFarError struct {
+	EventName string
+	Distance  float64
+	Radius    int
+}
+
+func (e *TooFarError) Error() string {
+	return fmt.Sprintf("%.0f m from %s, check-in radius is %d m", e.Distance, e.EventName, e.Radius)
+}
+
+// Attempt is a location shared by a user to check in.
+type Attempt struct {
+	UserID   int64
+	Lat, Lng float64
+	// Accuracy is the reported horizontal accuracy in meters, 0 if unknown.
+	Accuracy float64
+	// DeviceID identifies the device when the web app sends one.
+	DeviceID string
+}
+
+// Result describes a successful check-in.
+type Result struct {
+	EventID     string    `json:"event_id"`
+	EventName   string    `json:"event_name"`
+	Distance    float64   `json:"distance_m"`
+	CheckedInAt time.Time `json:"checked_in_at"`
+	// Flags lists the reasons the check-in was flagged for review.
+	Flags []string `json:"flags"`
+}
+
+type Service struct {
+	db *sqlx.DB
+}
+
+func NewService(db *sqlx.DB) *Service {
+	return &Service{db: db}
+}
+
+type candidate struct {
+	RegistrationID int64      `db:"registration_id"`
+	EventID        string     `db:"event_id"`
+	Name           string     `db:"name"`
+	Lat            float64    `db:"latitude"`
+	Lng            float64    `db:"longitude"`
+	Radius         int        `db:"checkin_radius_m"`
+	CheckedInAt    *time.Time `db:"checked_in_at"`
+}
+
+// SelfCheckIn checks the user in to the nearest running event they are
+// registered for, provided the point is within the event's check-in radius.
+// Every attempt is recorded; suspicious ones still succeed but are flagged.
+func (s *Service) SelfCheckIn(ctx context.Context, a Attempt) (Result, error) {
+	if !geo.ValidPoint(a.Lat, a.Lng) {
+		return Result{}, errors.New("invalid coordinates")
+	}
+
+	var events []candidate
+	err := s.db.SelectContext(ctx, &events, `SELECT r.id AS registration_id, e.id AS event_id, e.name,
+				e.latitude, e.longitude, e.checkin_radius_m, r.checked_in_at
+			  FROM event_registrations r JOIN events e ON e.id = r.event_id
+			  WHERE r.user_id = $1 AND r.status = 'registered' AND e.status IN ('published', 'ongoing')
+				AND e.latitude IS NOT NULL AND e.longitude IS NOT NULL
+				AND e.start_date <= CURRENT_TIMESTAMP AND e.end_date > CURRENT_TIMESTAMP`, a.UserID)
+	if err != nil {
+		return Result{}, err
+	}
+	if len(events) == 0 {
+		if err := s.record(ctx, s.db, a, "", false); err != nil {
+			return Result{}, err
+		}
+		return Result{}, ErrNoEvent
+	}
+
+	nearest, distance := events[0], math.Inf(1)
+	for _, e := range events {
+		if d := geo.Distance(a.Lat, a.Lng, e.Lat, e.Lng); d < distance {
+			nearest, distance = e, d
+		}
+	}
+	if nearest.CheckedInAt != nil {
+		return Result{}, ErrAlreadyCheckedIn
+	}
+	if distance > float64(nearest.Radius) {
+		if err := s.record(ctx, s.db, a, nearest.EventID, false); err != nil {
+			return Result{}, err
+		}
+		return Result{}, &TooFarError{EventName: nearest.Name, Distance: distance, Radius: nearest.Radius}
+	}
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return Result{}, err
+	}
+	defer tx.Rollback()
+
+	flags, err := s.suspicious(ctx, tx, a)
+	if err != nil {
+		return Result{}, err
+	}
+	for reason, details := range flags {
+		_, err := tx.ExecContext(ctx, `INSERT INTO checkin_flags (user_id, event_id, reason, details) VALUES ($1, $2, $3, $4)`,
+			a.UserID, nearest.EventID, reason, details)
+		if err != nil {
+			return Result{}, err
+		}
+	}
+
+	if err := s.record(ctx, tx, a, nearest.EventID, true); err != nil {
+		return Result{}, err
+	}
+
+	result := Result{EventID: nearest.EventID, EventName: nearest.Name, Distance: distance, Flags: []string{}}
+	err = tx.GetContext(ctx, &result.CheckedInAt, `UPDATE event_registrations
+			  SET checked_in_at = CURRENT_TIMESTAMP, checkin_method = 'geo', updated_at = CURRENT_TIMESTAMP
+			  WHERE id = $1 AND checked_in_at IS NULL RETURNING checked_in_at`, nearest.RegistrationID)
+	if err == sql.ErrNoRows {
+		return Result{}, ErrAlreadyCheckedIn
+	}
+	if err != nil {
+		return Result{}, err
+	}
+
+	for reason := range flags {
+		result.Flags = append(result.Flags, reason)
+	}
+	return result, tx.Commit()
+}
+
+// suspicious compares the attempt with earlier ones and returns the flags it
+// raises with a human readable explanation.
+func (s *Service) suspicious(ctx context.Context, tx *sqlx.Tx, a Attempt) (map[string]string, error) {
+	flags := map[string]string{}
+
+	if a.DeviceID != "" {
+		var others []int64
+		err := tx.SelectContext(ctx, &others, `SELECT DISTINCT user_id FROM checkin_locations
+				  WHERE device_id = $1 AND user_id <> $2 AND accepted AND created_at > $3`,
+			a.DeviceID, a.UserID, time.Now().Add(-deviceWindow))
+		if err != nil {
+			return nil, err
+		}
+		if len(others) > 0 {
+			flags[FlagSharedDevice] = fmt.Sprintf("device also used to check in users %v", others)
+		}
+	}
+
+	var last struct {
+		Lat       float64   `db:"latitude"`
+		Lng       float64   `db:"longitude"`
+		CreatedAt time.Time `db:"created_at"`
+	}
+	err := tx.GetContext(ctx, &last, `SELECT latitude, longitude, created_at FROM checkin_locations
+			  WHERE user_id = $1 AND created_at > $2 ORDER BY created_at DESC LIMIT 1`,
+		a.UserID, time.Now().Add(-travelWindow))
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+	if err == nil {
+		distance := geo.Distance(last.Lat, last.Lng, a.Lat, a.Lng)
+		elapsed := time.Since(last.CreatedAt).Seconds()
+		if distance > minTravelDistance && (elapsed <= 0 || distance/elapsed > maxSpeed) {
+			flags[FlagImpossibleTravel] = fmt.Sprintf("moved %.1f km in %s", distance/1000, time.Since(last.CreatedAt).Round(time.Second))
+		}
+	}
+	return flags, nil
+}
+
+func (s *Service) record(ctx context.Context, ex sqlx.ExecerContext, a Attempt, eventID string, accepted bool) error {
+	var accuracy *float64
+	if a.Accuracy > 0 {
+		accuracy = &a.Accuracy
+	}
+	var event *string
+	if eventID != "" {
+		event = &eventID
+	}
+	_, err := ex.ExecContext(ctx, `INSERT INTO checkin_locations (user_id, event_id, latitude, longitude, accuracy_m, device_id, accepted)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7)`, a.UserID, event, a.Lat, a.Lng, accuracy, a.DeviceID, accepted)
+	return err
+}
@@ -2,8 +2,13 @@ package config
 
 import (
 	"os"
+	"time"
 )
 
+// Tashkent is the time zone of the users: leaderboard periods start in it
+// and times are shown to users in it. Uzbekistan has no DST.
+var Tashkent = time.FixedZone("UZT", 5*60*60)
+
 // Config ...
 type Config struct {
 	Environment string
@@ -23,13 +28,53 @@ type Config struct {
 	RedisDatabase string
 	RedisPassword string
 
+	// SigningKey signs event tickets. It is required and at least 32 bytes
+	// long.
 	SigningKey          string
+	CheckinTicketTTL    string
 	AccessTokenTimeout  string
 	RefreshTokenTimeout string
+	// WebAuthMaxAge is how long the Telegram init data identifying web app
+	// users is accepted for.
+	WebAuthMaxAge string
 
 	AuthConfigPath string
 	CSVFilePath    string
 
+	// QuizProviders is the comma separated order quiz generators are tried
+	// in: gemini, openai or fake.
+	QuizProviders string
+	QuizTimeout   string
+	// QuizBankMin is the number of questions per difficulty the question
+	// bank is topped up to.
+	QuizBankMin string
+	// EventQuizWindow is how long after an event ends attendees can take
+	// its quiz, for up to EventQuizBonusXP on top of the usual reward.
+	EventQuizWindow  string
+	EventQuizBonusXP string
+	// QuizTimeLimits are the time limits of quiz questions by difficulty,
+	// e.g. EASY=30s,MEDIUM=45s,HARD=1m. Quizzes of other difficulties are
+	// untimed.
+	QuizTimeLimits string
+	// XPRulesFile is a YAML file of XP rules, applying while no rule set is
+	// active. The built-in rules apply when it is empty.
+	XPRulesFile string
+	// Accounts earning more XP within AnomalyWindow than AnomalyDeviations
+	// standard deviations above the mean, and at least AnomalyMinXP, are
+	// flagged for review, as are perfect quizzes answered in less than
+	// AnomalyMinAnswerTime per question.
+	AnomalyWindow        string
+	AnomalyDeviations    string
+	AnomalyMinXP         string
+	AnomalyMinAnswerTime string
+	GeminiAPIKey         string
+	GeminiModel          string
+	// OpenAIBaseURL is any OpenAI compatible API, e.g. a local Ollama
+	// server.
+	OpenAIBaseURL string
+	OpenAIAPIKey  string
+	OpenAIModel   string
+
 	SMTPEmail     string
 	SMTPEmailPass string
 	SMTPHost      string
@@ -56,13 +101,32 @@ func Load() Config {
 	c.RedisDatabase = getEnv("REDIS_DATABASE", "0")
 	c.RedisPassword = getEnv("REDIS_PASSWORD", "")
 
-	c.SigningKey = getEnv("SIGNING_KEY", "template")
+	c.SigningKey = getEnv("SIGNING_KEY", "")
+	c.CheckinTicketTTL = getEnv("CHECKIN_TICKET_TTL", "5m")
 	c.AccessTokenTimeout = getEnv("ACCESS_TOKEN_TIMEOUT", "10800")   // 3h
 	c.RefreshTokenTimeout = getEnv("REFRESH_TOKEN_TIMEOUT", "86400") // 24h
+	c.WebAuthMaxAge = getEnv("WEBAUTH_MAX_AGE", "24h")
 
 	c.CSVFilePath = getEnv("CSV_FILE_PATH", "./config/policy.csv")
 	c.AuthConfigPath = getEnv("AUTH_PATH", "./config/model.conf")
 
+	c.QuizProviders = getEnv("QUIZ_PROVIDERS", "gemini")
+	c.QuizTimeout = getEnv("QUIZ_TIMEOUT", "60s")
+	c.QuizBankMin = getEnv("QUIZ_BANK_MIN", "100")
+	c.EventQuizWindow = getEnv("EVENT_QUIZ_WINDOW", "72h")
+	c.EventQuizBonusXP = getEnv("EVENT_QUIZ_BONUS_XP", "20")
+	c.QuizTimeLimits = getEnv("QUIZ_TIME_LIMITS", "EASY=30s,MEDIUM=45s,HARD=1m")
+	c.XPRulesFile = getEnv("XP_RULES_FILE", "")
+	c.AnomalyWindow = getEnv("ANOMALY_WINDOW", "24h")
+	c.AnomalyDeviations = getEnv("ANOMALY_DEVIATIONS", "4")
+	c.AnomalyMinXP = getEnv("ANOMALY_MIN_XP", "200")
+	c.AnomalyMinAnswerTime = getEnv("ANOMALY_MIN_ANSWER_TIME", "2s")
+	c.GeminiAPIKey = getEnv("GEMINI_API_KEY", "")
+	c.GeminiModel = getEnv("GEMINI_MODEL", "gemini-1.5-flash")
+	c.OpenAIBaseURL = getEnv("OPENAI_BASE_URL", "http://localhost:11434/v1")
+	c.OpenAIAPIKey = getEnv("OPENAI_API_KEY", "")
+	c.OpenAIModel = getEnv("OPENAI_MODEL", "llama3.1")
+
 	c.SMTPHost = getEnv("SMTP_HOST", "smtp.gmail.com")
 	c.SMTPPort = getEnv("SMTP_PORT", "587")
 	c.SMTPEmail = getEnv("SMTP_EMAIL", "your_email")
@@ -15,39 +15,34 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/event": {
-            "post": {
-                "description": "This API creates a new event",
-                "consumes": [
-                    "application/json"
-                ],
+        "/calendar/{token}": {
+            "get": {
+                "description": "This API returns an iCalendar feed of the events a user registered for, waitlisted ones as tentative and cancelled ones as cancelled. The token comes from GET /user/{id}/calendar. Events the user leaves drop out of the feed.",
                 "produces": [
-                    "application/json"
+                    "text/calendar"
                 ],
                 "tags": [
-                    "Event"
+                    "Calendar"
                 ],
-                "summary": "Create Event",
+                "summary": "Personal Calendar Feed",
                 "parameters": [
                     {
-                        "description": "Event Data",
-                        "name": "event",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.Event"
-                        }
+                        "type": "string",
+                        "description": "Calendar token, optionally followed by .ics",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "iCalendar file",
                         "schema": {
-                            "$ref": "#/definitions/models.Event"
+                            "type": "string"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/webhandlers.ErrorResponse"
                         }
@@ -61,9 +56,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/event/{id}": {
+        "/checkin-flags": {
             "get": {
-                "description": "This API returns event details based on the provided event ID",
+                "description": "This API lists self check-ins flagged as suspicious. Officers see the flags of their events, admins all flags.",
                 "consumes": [
                     "application/json"
                 ],
@@ -71,23 +66,55 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Event"
+                    "Attendance"
                 ],
-                "summary": "Get Event",
+                "summary": "List Check-in Flags",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Event ID",
-                        "name": "id",
-                        "in": "path",
+                        "description": "tma and the web app init data",
+                        "name": "Authorization",
+                        "in": "header",
                         "required": true
+                    },
+                    {
+                        "enum": [
+                            "open",
+                            "dismissed",
+                            "confirmed"
+                        ],
+                        "type": "string",
+                        "description": "Review status, open by default",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only flags of this event",
+                        "name": "event_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (1-100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Event"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.CheckinFlag"
+                            }
                         }
                     },
                     "400": {
@@ -96,8 +123,14 @@ const docTemplate = `{
                             "$ref": "#/definitions/webhandlers.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/webhandlers.ErrorResponse"
                         }
@@ -109,9 +142,11 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
+            }
+        },
+        "/checkin-flags/{id}": {
             "put": {
-                "description": "This API updates event details",
+                "description": "This API dismisses or confirms a flagged check-in. Confirming it revokes the check-in unless the participant has already checked out. Officers of the flag's event and admins only.",
                 "consumes": [
                     "application/json"
                 ],
@@ -119,24 +154,31 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Event"
+                    "Attendance"
                 ],
-                "summary": "Update Event",
+                "summary": "Review Check-in Flag",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Event ID",
+                        "description": "tma and the web app init data",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Flag ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Updated Event Data",
-                        "name": "event",
+                        "description": "Review",
+                        "name": "review",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.Event"
+                            "$ref": "#/definitions/models.FlagReview"
                         }
                     }
                 ],
@@ -144,7 +186,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Event"
+                            "$ref": "#/definitions/models.CheckinFlag"
                         }
                     },
                     "400": {
@@ -153,47 +195,14 @@ const docTemplate = `{
                             "$ref": "#/definitions/webhandlers.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
-                        "schema": {
-                            "$ref": "#/definitions/webhandlers.ErrorResponse"
-                        }
-                    },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/webhandlers.ErrorResponse"
                         }
-                    }
-                }
-            },
-            "delete": {
-                "description": "This API deletes an event based on the provided event ID",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Event"
-                ],
-                "summary": "Delete Event",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Event ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
-                ],
-                "responses": {
-                    "204": {
-                        "description": "No Content"
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/webhandlers.ErrorResponse"
                         }
@@ -213,71 +222,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/events": {
-            "get": {
-                "description": "This API returns a list of all events",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Event"
-                ],
-                "summary": "List Events",
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.Event"
-                            }
-                        }
-                    },
-                    "500": {
-                        "description": "Internal Server Error",
-                        "schema": {
-                            "$ref": "#/definitions/webhandlers.ErrorResponse"
-                        }
-                    }
-                }
-            }
-        },
-        "/history": {
-            "get": {
-                "description": "This API returns a list of all history records",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "History"
-                ],
-                "summary": "List History",
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.History"
-                            }
-                        }
-                    },
-                    "500": {
-                        "description": "Internal Server Error",
-                        "schema": {
-                            "$ref": "#/definitions/webhandlers.ErrorResponse"
-                        }
-                    }
-                }
-            },
+        "/event": {
             "post": {
-                "description": "This API creates a new history record",
+                "description": "This API creates a new event, as a draft unless created with status published. officers must name one lead officer and may add assistants, all users with the officer or admin role. Admins only.",
                 "consumes": [
                     "application/json"
                 ],
@@ -285,17 +232,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "History"
+                    "Event"
                 ],
-                "summary": "Create History",
+                "summary": "Create Event",
                 "parameters": [
                     {
-                        "description": "History Data",
-                        "name": "body",
+                        "type": "string",
+                        "description": "tma and the web app init data",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Event Data, with status published to publish it at once",
+                        "name": "event",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.History"
+                            "$ref": "#/definitions/models.Event"
                         }
                     }
                 ],
@@ -303,7 +257,7 @@ const docTemplate = `{
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/models.History"
+                            "$ref": "#/definitions/models.Event"
                         }
                     },
                     "400": {
@@ -312,6 +266,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/webhandlers.ErrorResponse"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -321,9 +287,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/history/{id}": {
+        "/event/{id}": {
             "get": {
-                "description": "This API returns history details based on the provided history ID",
+                "description": "This API returns event details based on the provided event ID",
                 "consumes": [
                     "application/json"
                 ],
@@ -331,13 +297,13 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "History"
+                    "Event"
                 ],
-                "summary": "Get History",
+                "summary": "Get Event",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "History ID",
+                        "description": "Event ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -347,7 +313,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.History"
+                            "$ref": "#/definitions/models.Event"
                         }
                     },
                     "400": {
@@ -371,7 +337,7 @@ const docTemplate = `{
                 }
             },
             "put": {
-                "description": "This API updates an existing history record",
+                "description": "This API updates event details. Updating an occurrence of a recurring event changes this occurrence only, and later edits of the series leave it alone. The officers are replaced when officers is given. The status is changed with the publish and cancel endpoints. Admins only.",
                 "consumes": [
                     "application/json"
                 ],
@@ -379,24 +345,31 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "History"
+                    "Event"
                 ],
-                "summary": "Update History",
+                "summary": "Update Event",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "History ID",
+                        "description": "tma and the web app init data",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Event ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Updated History Data",
-                        "name": "body",
+                        "description": "Updated Event Data",
+                        "name": "event",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.History"
+                            "$ref": "#/definitions/models.Event"
                         }
                     }
                 ],
@@ -404,7 +377,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.History"
+                            "$ref": "#/definitions/models.Event"
                         }
                     },
                     "400": {
@@ -413,6 +386,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/webhandlers.ErrorResponse"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
@@ -428,7 +413,7 @@ const docTemplate = `{
                 }
             },
             "delete": {
-                "description": "This API deletes a history record based on the provided history ID",
+                "description": "This API deletes an event based on the provided event ID. Deleting an occurrence of a recurring event cancels it so it is not created again. Events with attendance history cannot be deleted, cancel them instead. Admins only.",
                 "consumes": [
                     "application/json"
                 ],
@@ -436,13 +421,20 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "History"
+                    "Event"
                 ],
-                "summary": "Delete History",
+                "summary": "Delete Event",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "History ID",
+                        "description": "tma and the web app init data",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Event ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -458,42 +450,28 @@ const docTemplate = `{
                             "$ref": "#/definitions/webhandlers.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/webhandlers.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/webhandlers.ErrorResponse"
                         }
-                    }
-                }
-            }
-        },
-        "/market": {
-            "get": {
-                "description": "This API lists all market records",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Market"
-                ],
-                "summary": "List Markets",
-                "responses": {
-                    "200": {
-                        "description": "OK",
+                    },
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.Market"
-                            }
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
                         }
                     },
                     "500": {
@@ -503,9 +481,11 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
+            }
+        },
+        "/event/{id}/cancel": {
             "post": {
-                "description": "This API creates a new market record",
+                "description": "This API cancels an event that has not completed and notifies everyone registered or waitlisted through the bot. XP already credited at check-out is kept. Admins only.",
                 "consumes": [
                     "application/json"
                 ],
@@ -513,25 +493,68 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Market"
+                    "Event"
                 ],
-                "summary": "Create Market",
+                "summary": "Cancel Event",
                 "parameters": [
                     {
-                        "description": "Market",
-                        "name": "market",
+                        "type": "string",
+                        "description": "tma and the web app init data",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Event ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Reason sent to registrants",
+                        "name": "cancellation",
                         "in": "body",
-                        "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.Market"
+                            "$ref": "#/definitions/models.EventCancellation"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Market"
+                            "$ref": "#/definitions/models.EventStatus"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
                         }
                     },
                     "500": {
@@ -543,9 +566,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/market/check/{userId}/{itemId}": {
-            "get": {
-                "description": "This API checks if the user's XP is enough to buy an item from the market",
+        "/event/{id}/checkin": {
+            "post": {
+                "description": "This API checks a participant in by their scanned QR code. Officers of the event and admins only.",
                 "consumes": [
                     "application/json"
                 ],
@@ -553,30 +576,39 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Market"
+                    "Attendance"
                 ],
-                "summary": "Check User XP",
+                "summary": "Check In",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "User ID",
-                        "name": "userId",
-                        "in": "path",
+                        "type": "string",
+                        "description": "tma and the web app init data",
+                        "name": "Authorization",
+                        "in": "header",
                         "required": true
                     },
                     {
-                        "type": "integer",
-                        "description": "Item ID",
-                        "name": "itemId",
+                        "type": "string",
+                        "description": "Event ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Scanned QR code",
+                        "name": "ticket",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.TicketScan"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Message"
+                            "$ref": "#/definitions/models.Attendance"
                         }
                     },
                     "400": {
@@ -585,12 +617,30 @@ const docTemplate = `{
                             "$ref": "#/definitions/webhandlers.ErrorResponse"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/webhandlers.ErrorResponse"
                         }
                     },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -600,9 +650,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/market/order/{userId}/{itemId}": {
+        "/event/{id}/checkout": {
             "post": {
-                "description": "This API allows a user to order an item from the market if they have enough XP",
+                "description": "This API checks a participant out by their scanned QR code, records the attendance in history and credits the event's XP, pro-rated by the time attended when the event has prorate_xp set. Officers of the event and admins only.",
                 "consumes": [
                     "application/json"
                 ],
@@ -610,30 +660,39 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Market"
+                    "Attendance"
                 ],
-                "summary": "Order Item",
+                "summary": "Check Out",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "User ID",
-                        "name": "userId",
-                        "in": "path",
+                        "type": "string",
+                        "description": "tma and the web app init data",
+                        "name": "Authorization",
+                        "in": "header",
                         "required": true
                     },
                     {
-                        "type": "integer",
-                        "description": "Item ID",
-                        "name": "itemId",
+                        "type": "string",
+                        "description": "Event ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Scanned QR code",
+                        "name": "ticket",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.TicketScan"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Message"
+                            "$ref": "#/definitions/models.Attendance"
                         }
                     },
                     "400": {
@@ -642,12 +701,30 @@ const docTemplate = `{
                             "$ref": "#/definitions/webhandlers.ErrorResponse"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/webhandlers.ErrorResponse"
                         }
                     },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -657,23 +734,20 @@ const docTemplate = `{
                 }
             }
         },
-        "/market/{id}": {
+        "/event/{id}/ics": {
             "get": {
-                "description": "This API retrieves a market record by ID",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "This API returns a single event as an iCalendar file to add it to a calendar",
                 "produces": [
-                    "application/json"
+                    "text/calendar"
                 ],
                 "tags": [
-                    "Market"
+                    "Calendar"
                 ],
-                "summary": "Get Market",
+                "summary": "Event Calendar File",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Market ID",
+                        "type": "string",
+                        "description": "Event ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -681,9 +755,9 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "iCalendar file",
                         "schema": {
-                            "$ref": "#/definitions/models.Market"
+                            "type": "string"
                         }
                     },
                     "404": {
@@ -691,11 +765,19 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/webhandlers.ErrorResponse"
                         }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
                     }
                 }
-            },
-            "put": {
-                "description": "This API updates an existing market record",
+            }
+        },
+        "/event/{id}/participants": {
+            "get": {
+                "description": "This API returns the roster of an event: registered participants followed by the waitlist in order. Officers of the event and admins only.",
                 "consumes": [
                     "application/json"
                 ],
@@ -703,32 +785,67 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Market"
+                    "Event"
                 ],
-                "summary": "Update Market",
+                "summary": "List Participants",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Market ID",
+                        "type": "string",
+                        "description": "tma and the web app init data",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Event ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Market",
-                        "name": "market",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.Market"
-                        }
+                        "enum": [
+                            "registered",
+                            "waitlisted"
+                        ],
+                        "type": "string",
+                        "description": "Only registered or waitlisted users",
+                        "name": "status",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Market"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Participant"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
                         }
                     },
                     "500": {
@@ -738,9 +855,11 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "This API deletes a market record by ID",
+            }
+        },
+        "/event/{id}/publish": {
+            "post": {
+                "description": "This API publishes a draft event so users can see it and register. Admins only.",
                 "consumes": [
                     "application/json"
                 ],
@@ -748,34 +867,68 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Market"
+                    "Event"
                 ],
-                "summary": "Delete Market",
+                "summary": "Publish Event",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Market ID",
+                        "type": "string",
+                        "description": "tma and the web app init data",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Event ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content"
-                    },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                            "$ref": "#/definitions/models.EventStatus"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/questions/{difficulty}": {
+        "/events": {
             "get": {
-                "description": "This API generates 10 questions with Gemini AI providing their answers too.",
+                "description": "This API returns a page of events. Drafts are only listed for admins.",
                 "consumes": [
                     "application/json"
                 ],
@@ -783,28 +936,101 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Question"
+                    "Event"
                 ],
-                "summary": "Get Questions",
+                "summary": "List Events",
                 "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page size (1-100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
                     {
                         "enum": [
-                            "easy",
-                            "medium",
-                            "hard"
+                            "start_date",
+                            "-start_date",
+                            "end_date",
+                            "-end_date",
+                            "name",
+                            "-name",
+                            "total_xp",
+                            "-total_xp"
                         ],
                         "type": "string",
-                        "description": "Difficulty Level",
-                        "name": "difficulty",
-                        "in": "path",
-                        "required": true
+                        "description": "Sort key, prefix with - for descending",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Events ending on or after this date (YYYY-MM-DD or RFC3339)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Events starting on or before this date (YYYY-MM-DD or RFC3339)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "upcoming",
+                            "ongoing",
+                            "past"
+                        ],
+                        "type": "string",
+                        "description": "Event status",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Part of the event location",
+                        "name": "location",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only occurrences of this recurring event",
+                        "name": "series_id",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "draft",
+                            "published",
+                            "ongoing",
+                            "completed",
+                            "cancelled"
+                        ],
+                        "type": "string",
+                        "description": "Lifecycle status",
+                        "name": "state",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "tma and the web app init data, drafts are listed for admins",
+                        "name": "Authorization",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Event"
+                            }
                         }
                     },
                     "400": {
@@ -813,11 +1039,31 @@ const docTemplate = `{
                             "$ref": "#/definitions/webhandlers.ErrorResponse"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/webhandlers.ErrorResponse"
                         }
+                    }
+                }
+            }
+        },
+        "/events.ics": {
+            "get": {
+                "description": "This API returns an iCalendar feed of upcoming events and those that ended in the last 30 days, to subscribe to from a phone calendar. Cancelled events stay in the feed marked cancelled.",
+                "produces": [
+                    "text/calendar"
+                ],
+                "tags": [
+                    "Calendar"
+                ],
+                "summary": "Events Calendar Feed",
+                "responses": {
+                    "200": {
+                        "description": "iCalendar file",
+                        "schema": {
+                            "type": "string"
+                        }
                     },
                     "500": {
                         "description": "Internal Server Error",
@@ -828,9 +1074,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/ranking": {
+        "/events/nearby": {
             "get": {
-                "description": "This API returns the ranking of users based on XP",
+                "description": "This API returns events within a radius of a point, nearest first. By default only events that have not ended are returned.",
                 "consumes": [
                     "application/json"
                 ],
@@ -838,16 +1084,55 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Ranking"
+                    "Event"
+                ],
+                "summary": "Nearby Events",
+                "parameters": [
+                    {
+                        "type": "number",
+                        "description": "Latitude",
+                        "name": "lat",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "number",
+                        "description": "Longitude",
+                        "name": "lng",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "number",
+                        "description": "Radius in meters (up to 200000, default 10000)",
+                        "name": "radius",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "upcoming",
+                            "ongoing",
+                            "past"
+                        ],
+                        "type": "string",
+                        "description": "Event status",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of events (1-100)",
+                        "name": "limit",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Get Rankings",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/models.RankingResponse"
+                                "$ref": "#/definitions/models.NearbyEvent"
                             }
                         }
                     },
@@ -866,9 +1151,85 @@ const docTemplate = `{
                 }
             }
         },
-        "/user": {
+        "/history": {
+            "get": {
+                "description": "This API returns a page of history records",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "History"
+                ],
+                "summary": "List History",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page size (1-100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "start_date",
+                            "-start_date",
+                            "end_date",
+                            "-end_date",
+                            "xp_earned",
+                            "-xp_earned"
+                        ],
+                        "type": "string",
+                        "description": "Sort key, prefix with - for descending",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Event ID",
+                        "name": "event_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.History"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    }
+                }
+            },
             "post": {
-                "description": "This API creates a new user",
+                "description": "This API creates a new history record",
                 "consumes": [
                     "application/json"
                 ],
@@ -876,17 +1237,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "User"
+                    "History"
                 ],
-                "summary": "Create User",
+                "summary": "Create History",
                 "parameters": [
                     {
-                        "description": "User Data",
-                        "name": "user",
+                        "description": "History Data",
+                        "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.User"
+                            "$ref": "#/definitions/models.History"
                         }
                     }
                 ],
@@ -894,7 +1255,7 @@ const docTemplate = `{
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/models.User"
+                            "$ref": "#/definitions/models.History"
                         }
                     },
                     "400": {
@@ -912,9 +1273,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/user/{id}": {
+        "/history/{id}": {
             "get": {
-                "description": "This API returns user details based on the provided user ID",
+                "description": "This API returns history details based on the provided history ID",
                 "consumes": [
                     "application/json"
                 ],
@@ -922,13 +1283,13 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "User"
+                    "History"
                 ],
-                "summary": "Get User",
+                "summary": "Get History",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "User ID",
+                        "type": "string",
+                        "description": "History ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -938,7 +1299,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.User"
+                            "$ref": "#/definitions/models.History"
                         }
                     },
                     "400": {
@@ -962,7 +1323,7 @@ const docTemplate = `{
                 }
             },
             "put": {
-                "description": "This API updates user details",
+                "description": "This API updates an existing history record",
                 "consumes": [
                     "application/json"
                 ],
@@ -970,24 +1331,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "User"
+                    "History"
                 ],
-                "summary": "Update User",
+                "summary": "Update History",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "User ID",
+                        "type": "string",
+                        "description": "History ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Updated User Data",
-                        "name": "user",
+                        "description": "Updated History Data",
+                        "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.User"
+                            "$ref": "#/definitions/models.History"
                         }
                     }
                 ],
@@ -995,7 +1356,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.User"
+                            "$ref": "#/definitions/models.History"
                         }
                     },
                     "400": {
@@ -1019,7 +1380,7 @@ const docTemplate = `{
                 }
             },
             "delete": {
-                "description": "This API deletes a user based on the provided user ID",
+                "description": "This API deletes a history record based on the provided history ID",
                 "consumes": [
                     "application/json"
                 ],
@@ -1027,13 +1388,13 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "User"
+                    "History"
                 ],
-                "summary": "Delete User",
+                "summary": "Delete History",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "User ID",
+                        "type": "string",
+                        "description": "History ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -1064,9 +1425,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/users": {
+        "/map/events": {
             "get": {
-                "description": "This API returns a list of all users",
+                "description": "This API returns events with coordinates as a GeoJSON FeatureCollection of points, for the map view",
                 "consumes": [
                     "application/json"
                 ],
@@ -1074,17 +1435,51 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "User"
+                    "Map"
+                ],
+                "summary": "Events Map",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bounding box minLng,minLat,maxLng,maxLat",
+                        "name": "bbox",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Events ending on or after this date (YYYY-MM-DD or RFC3339)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Events starting on or before this date (YYYY-MM-DD or RFC3339)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "upcoming",
+                            "ongoing",
+                            "past"
+                        ],
+                        "type": "string",
+                        "description": "Event status, all by default",
+                        "name": "status",
+                        "in": "query"
+                    }
                 ],
-                "summary": "List Users",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.User"
-                            }
+                            "$ref": "#/definitions/geo.FeatureCollection"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
                         }
                     },
                     "500": {
@@ -1096,9 +1491,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/xp": {
-            "post": {
-                "description": "Adds XP by given data",
+        "/map/regions": {
+            "get": {
+                "description": "This API returns the region boundaries of Uzbekistan as a GeoJSON FeatureCollection of polygons with the events held, distinct participants and XP earned in each, for a choropleth of eco activity. Completed events count towards the region they took place in.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1106,23 +1501,208 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "User"
+                    "Map"
                 ],
-                "summary": "EarnXP",
+                "summary": "Regional Impact Map",
                 "parameters": [
                     {
-                        "description": "XP Data",
-                        "name": "user",
-                        "in": "body",
+                        "type": "string",
+                        "description": "Events ending on or after this date (YYYY-MM-DD or RFC3339)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Events starting on or before this date (YYYY-MM-DD or RFC3339)",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/geo.FeatureCollection"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/market": {
+            "get": {
+                "description": "This API lists a page of market records",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Market"
+                ],
+                "summary": "List Markets",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page size (1-100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "id",
+                            "-id",
+                            "name",
+                            "-name",
+                            "xp",
+                            "-xp",
+                            "count",
+                            "-count"
+                        ],
+                        "type": "string",
+                        "description": "Sort key, prefix with - for descending",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Category name",
+                        "name": "category",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Minimum price in XP",
+                        "name": "min_xp",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum price in XP",
+                        "name": "max_xp",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Market"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "This API creates a new market record",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Market"
+                ],
+                "summary": "Create Market",
+                "parameters": [
+                    {
+                        "description": "Market",
+                        "name": "market",
+                        "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.EarnXP"
+                            "$ref": "#/definitions/models.Market"
                         }
                     }
                 ],
                 "responses": {
                     "201": {
                         "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Market"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/market/check/{userId}/{itemId}": {
+            "get": {
+                "description": "This API checks if the user's XP is enough to buy an item from the market",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Market"
+                ],
+                "summary": "Check User XP",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "tma and the web app init data",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Item ID",
+                        "name": "itemId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
                         "schema": {
                             "$ref": "#/definitions/models.Message"
                         }
@@ -1133,6 +1713,24 @@ const docTemplate = `{
                             "$ref": "#/definitions/webhandlers.ErrorResponse"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -1141,163 +1739,6093 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "models.EarnXP": {
+        },
+        "/market/order/{userId}/{itemId}": {
+            "post": {
+                "description": "This API allows a user to order an item from the market if they have enough XP and their account is not frozen",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Market"
+                ],
+                "summary": "Order Item",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "tma and the web app init data",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Item ID",
+                        "name": "itemId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Message"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/market/{id}": {
+            "get": {
+                "description": "This API retrieves a market record by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Market"
+                ],
+                "summary": "Get Market",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Market ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Market"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "This API updates an existing market record",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Market"
+                ],
+                "summary": "Update Market",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Market ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Market",
+                        "name": "market",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Market"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Market"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "This API deletes a market record by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Market"
+                ],
+                "summary": "Delete Market",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Market ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/officer/events": {
+            "get": {
+                "description": "This API returns a page of the events the caller runs as lead officer or assistant, drafts included. Officers and admins only.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Event"
+                ],
+                "summary": "List My Officer Events",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "tma and the web app init data",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "lead",
+                            "assistant"
+                        ],
+                        "type": "string",
+                        "description": "Only events where the caller has this role",
+                        "name": "role",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (1-100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "start_date",
+                            "-start_date",
+                            "end_date",
+                            "-end_date",
+                            "name",
+                            "-name",
+                            "total_xp",
+                            "-total_xp"
+                        ],
+                        "type": "string",
+                        "description": "Sort key, prefix with - for descending",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Events ending on or after this date (YYYY-MM-DD or RFC3339)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Events starting on or before this date (YYYY-MM-DD or RFC3339)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "upcoming",
+                            "ongoing",
+                            "past"
+                        ],
+                        "type": "string",
+                        "description": "Event status",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "draft",
+                            "published",
+                            "ongoing",
+                            "completed",
+                            "cancelled"
+                        ],
+                        "type": "string",
+                        "description": "Lifecycle status",
+                        "name": "state",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Event"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/officers/unassigned": {
+            "get": {
+                "description": "This API lists the officer names events and series had before officers were user accounts, for which no officer is assigned yet. Assigning the officers of the event or series resolves them. Admins only.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Event"
+                ],
+                "summary": "List Unassigned Officers",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "tma and the web app init data",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.UnassignedOfficer"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders": {
+            "get": {
+                "description": "This API returns all orders for admins and officers, optionally filtered by status. Pickup codes are hidden.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Order"
+                ],
+                "summary": "List Orders",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "tma and the web app init data",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "pending",
+                            "ready_for_pickup",
+                            "fulfilled",
+                            "cancelled",
+                            "refunded"
+                        ],
+                        "type": "string",
+                        "description": "Order status",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Order"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/{id}/fulfil": {
+            "post": {
+                "description": "This API hands an order over to the user once the officer has verified the pickup code shown by the user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Order"
+                ],
+                "summary": "Fulfil Order",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "tma and the web app init data",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Pickup code",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.PickupVerification"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Order"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/{id}/status": {
+            "put": {
+                "description": "This API moves an order to ready_for_pickup, cancelled or refunded. Cancelling or refunding returns the XP and restocks the item.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Order"
+                ],
+                "summary": "Update Order Status",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "tma and the web app init data",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New status",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.OrderStatusUpdate"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Order"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/questions": {
+            "get": {
+                "description": "This API lists the questions of the quiz question bank, those waiting for review by default. Teachers and admins only.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Question"
+                ],
+                "summary": "List Bank Questions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "tma and the web app init data",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "pending",
+                            "approved",
+                            "rejected"
+                        ],
+                        "type": "string",
+                        "description": "Review status, pending by default",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "EASY",
+                            "MEDIUM",
+                            "HARD"
+                        ],
+                        "type": "string",
+                        "description": "Difficulty",
+                        "name": "difficulty",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Topic",
+                        "name": "topic",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Language",
+                        "name": "language",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Provider that generated the question, import or seed",
+                        "name": "source",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only the questions of this event's quiz",
+                        "name": "event_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (1-100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "created_at",
+                            "-created_at",
+                            "updated_at",
+                            "-updated_at"
+                        ],
+                        "type": "string",
+                        "description": "Sort key, prefix with - for descending",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/quiz.BankQuestion"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/questions/export": {
+            "get": {
+                "description": "This API exports the questions of the quiz question bank as a CSV or JSON file that can be imported again. Teachers and admins only.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "text/csv",
+                    "application/json"
+                ],
+                "tags": [
+                    "Question"
+                ],
+                "summary": "Export Questions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "tma and the web app init data",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "csv",
+                            "json"
+                        ],
+                        "type": "string",
+                        "description": "File format, csv by default",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "pending",
+                            "approved",
+                            "rejected"
+                        ],
+                        "type": "string",
+                        "description": "Review status, all by default",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "EASY",
+                            "MEDIUM",
+                            "HARD"
+                        ],
+                        "type": "string",
+                        "description": "Difficulty",
+                        "name": "difficulty",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Topic",
+                        "name": "topic",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Language",
+                        "name": "language",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/quiz.BankQuestion"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/questions/import": {
+            "post": {
+                "description": "This API imports questions into the quiz question bank from a CSV or JSON file, sent as the request body or as the multipart field \"file\". CSV files have a header row with the columns difficulty, topic, language, question, a, b, c, d and answer; topic and language are optional. JSON files are an array of questions as exported. Imported questions wait for review. Invalid and duplicate rows are skipped and reported by row number, counting data rows from 1. Teachers and admins only.",
+                "consumes": [
+                    "text/csv",
+                    "application/json",
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Question"
+                ],
+                "summary": "Import Questions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "tma and the web app init data",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "csv",
+                            "json"
+                        ],
+                        "type": "string",
+                        "description": "File format, taken from the content type or file name by default",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "file",
+                        "description": "CSV or JSON file",
+                        "name": "file",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/quiz.ImportReport"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/questions/timings": {
+            "get": {
+                "description": "This API reports how bank questions fared in timed quizzes: how often they were served, the share answered correctly in time, the share answered late or not at all, the mean share of the time limit used and the median answer time. Questions served often enough get a suggested difficulty: EASY when most answer correctly with time to spare, HARD when most fail or take nearly all the time, MEDIUM otherwise. Teachers and admins only.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Question"
+                ],
+                "summary": "List Question Timings",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "tma and the web app init data",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "EASY",
+                            "MEDIUM",
+                            "HARD"
+                        ],
+                        "type": "string",
+                        "description": "Difficulty",
+                        "name": "difficulty",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Topic",
+                        "name": "topic",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only questions whose suggested difficulty differs from their difficulty",
+                        "name": "mismatched",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "served",
+                            "-served",
+                            "correct_rate",
+                            "-correct_rate",
+                            "late_rate",
+                            "-late_rate",
+                            "time_used",
+                            "-time_used"
+                        ],
+                        "type": "string",
+                        "description": "Sort key, prefixed with - for descending order, -served by default",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (1-100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/quiz.QuestionTiming"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/questions/{id}": {
+            "put": {
+                "description": "This API replaces the difficulty, topic, language, text, variants and answer of a bank question, keeping its review status. The edit is recorded in the question's history. Teachers and admins only.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Question"
+                ],
+                "summary": "Edit Question",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "tma and the web app init data",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Question ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Question",
+                        "name": "question",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/quiz.QuestionInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/quiz.BankQuestion"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/questions/{id}/history": {
+            "get": {
+                "description": "This API returns the imports, edits and reviews of a bank question, oldest first, each with the question before and after it. Teachers and admins only.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Question"
+                ],
+                "summary": "Question History",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "tma and the web app init data",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Question ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/quiz.AuditEntry"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/questions/{id}/review": {
+            "put": {
+                "description": "This API approves a bank question, adding it to quizzes, or rejects it. Approved questions can be rejected later to take them out of quizzes. The review is recorded in the question's history. Teachers and admins only.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Question"
+                ],
+                "summary": "Review Question",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "tma and the web app init data",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Question ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "approved or rejected, with an optional note",
+                        "name": "review",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.QuestionReview"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/quiz.BankQuestion"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/quiz/topics": {
+            "get": {
+                "description": "This API lists the topics quizzes can be started with.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Question"
+                ],
+                "summary": "List Quiz Topics",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/quiz.Topic"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/ranking": {
+            "get": {
+                "description": "This API returns a leaderboard page by page. Boards can be limited to a region or to the caller and their friends, and to XP earned today, this week, this month or this season. Past seasons are served from their archived final standings. Users with equal XP share a rank. When Authorization is sent the caller's own entry is returned as \"me\", even outside the page.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Ranking"
+                ],
+                "summary": "Get Rankings",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "tma and the web app init data",
+                        "name": "Authorization",
+                        "in": "header"
+                    },
+                    {
+                        "enum": [
+                            "global",
+                            "region",
+                            "friends"
+                        ],
+                        "type": "string",
+                        "description": "Who is ranked",
+                        "name": "scope",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Region for the region scope, the caller's region by default",
+                        "name": "region",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "all",
+                            "today",
+                            "week",
+                            "month",
+                            "season"
+                        ],
+                        "type": "string",
+                        "description": "XP earned within",
+                        "name": "period",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Season ID, overrides period",
+                        "name": "season",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "competition",
+                            "dense"
+                        ],
+                        "type": "string",
+                        "description": "competition (1, 2, 2, 4) or dense (1, 2, 2, 3)",
+                        "name": "rank",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (1-100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.RankingResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/ranking/user/{id}": {
+            "get": {
+                "description": "This API returns the rank of a single user on the global, regional or friends board",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Ranking"
+                ],
+                "summary": "Get User Rank",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "global",
+                            "region",
+                            "friends"
+                        ],
+                        "type": "string",
+                        "description": "Who is ranked",
+                        "name": "scope",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Region for the region scope, the user's region by default",
+                        "name": "region",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "all",
+                            "today",
+                            "week",
+                            "month",
+                            "season"
+                        ],
+                        "type": "string",
+                        "description": "XP earned within",
+                        "name": "period",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Season ID, overrides period",
+                        "name": "season",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "competition",
+                            "dense"
+                        ],
+                        "type": "string",
+                        "description": "competition (1, 2, 2, 4) or dense (1, 2, 2, 3)",
+                        "name": "rank",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.RankingResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/ranking/user/{id}/around": {
+            "get": {
+                "description": "This API returns the users ranked directly above and below a user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Ranking"
+                ],
+                "summary": "Get Rankings Around User",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of users on each side (1-50, default 5)",
+                        "name": "radius",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "global",
+                            "region",
+                            "friends"
+                        ],
+                        "type": "string",
+                        "description": "Who is ranked",
+                        "name": "scope",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Region for the region scope, the user's region by default",
+                        "name": "region",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "all",
+                            "today",
+                            "week",
+                            "month",
+                            "season"
+                        ],
+                        "type": "string",
+                        "description": "XP earned within",
+                        "name": "period",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Season ID, overrides period",
+                        "name": "season",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "competition",
+                            "dense"
+                        ],
+                        "type": "string",
+                        "description": "competition (1, 2, 2, 4) or dense (1, 2, 2, 3)",
+                        "name": "rank",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.RankingResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/search": {
+            "get": {
+                "description": "This API searches events, market items and users by text and returns ranked results with highlighted matches",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Search"
+                ],
+                "summary": "Search",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Search text",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated result types (event, market, user), all by default",
+                        "name": "types",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of results (1-100)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.SearchResult"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/seasons": {
+            "get": {
+                "description": "This API lists seasons, newest first by default. Closed seasons have closed_at set and their final standings can be browsed with /ranking?season=ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Seasons"
+                ],
+                "summary": "List Seasons",
+                "parameters": [
+                    {
+                        "enum": [
+                            "running",
+                            "upcoming",
+                            "closed"
+                        ],
+                        "type": "string",
+                        "description": "Only running, upcoming or closed seasons",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "starts_at",
+                            "-starts_at",
+                            "ends_at",
+                            "-ends_at"
+                        ],
+                        "type": "string",
+                        "description": "Sort key, prefixed with - for descending order",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (1-100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Season"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "This API creates a season with the prizes awarded to the top users of every region when it closes. Admins only.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Seasons"
+                ],
+                "summary": "Create Season",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "tma and the web app init data",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Season",
+                        "name": "season",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Season"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Season"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/seasons/{id}": {
+            "get": {
+                "description": "This API returns a season with its prizes",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Seasons"
+                ],
+                "summary": "Get Season",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Season ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Season"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/series": {
+            "post": {
+                "description": "This API creates a recurring event from an iCalendar RRULE, e.g. FREQ=WEEKLY;BYDAY=SA for weekly park clean-ups. Every occurrence starts at the wall clock time of start in timezone and becomes an event of its own, created up to 90 days ahead, that users register for and check in to like any other. The officers, one lead and any assistants, run every occurrence. Admins only.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Event"
+                ],
+                "summary": "Create Event Series",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "tma and the web app init data",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Series",
+                        "name": "series",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.EventSeries"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.EventSeries"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/series/{id}": {
+            "get": {
+                "description": "This API returns a recurring event with its occurrences that have not ended",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Event"
+                ],
+                "summary": "Get Event Series",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Series ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.EventSeries"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "This API replaces the definition of a recurring event for all future occurrences, starting with the occurrence given by from or with the next one. Earlier occurrences keep the old definition, in which case the future ones move to a new series that is returned. Upcoming occurrences keep their registrations as they move, in order. Occurrences edited on their own with PUT /event/{id} are left alone. To edit a single occurrence, update its event instead. Admins only.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Event"
+                ],
+                "summary": "Update Future Occurrences",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "tma and the web app init data",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Series ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Event ID of the first occurrence to change",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "description": "New definition",
+                        "name": "series",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.EventSeries"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.EventSeries"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/user": {
+            "post": {
+                "description": "This API creates a new user. Users start without XP, the xp field is ignored.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Create User",
+                "parameters": [
+                    {
+                        "description": "User Data",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.User"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/{id}": {
+            "get": {
+                "description": "This API returns user details based on the provided user ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Get User",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "This API updates user details. XP is not updated here, the xp field is ignored.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Update User",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Updated User Data",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.User"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "This API deletes a user based on the provided user ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Delete User",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/{id}/badges": {
+            "get": {
+                "description": "This API lists the badges a user has won",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Seasons"
+                ],
+                "summary": "List User Badges",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Badge"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/{id}/calendar": {
+            "get": {
+                "description": "This API returns the URLs of a user's personal calendar feed of the events they registered for, creating it on first use",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Calendar"
+                ],
+                "summary": "Get Calendar Feed",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "tma and the web app init data",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.CalendarFeed"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/{id}/calendar/reset": {
+            "post": {
+                "description": "This API replaces the URL of a user's personal calendar feed, e.g. after it was shared by mistake. The old URL stops working.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Calendar"
+                ],
+                "summary": "Reset Calendar Feed",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "tma and the web app init data",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.CalendarFeed"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/{id}/checkin": {
+            "post": {
+                "description": "This API checks a user in to the running event they are registered for when the shared location is within the event's check-in radius. Suspicious check-ins, e.g. one device used by several users or an impossible travel speed, succeed but are flagged for review.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Attendance"
+                ],
+                "summary": "Self Check In",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "tma and the web app init data",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Current location",
+                        "name": "location",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.LocationCheckIn"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/checkin.Result"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/{id}/events": {
+            "get": {
+                "description": "This API lists the events a user is registered or waitlisted for, latest events first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Event"
+                ],
+                "summary": "List User Registrations",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.EventRegistration"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/{id}/events/{eventId}": {
+            "post": {
+                "description": "This API registers a user for an event. When the event is full the user is put on the waitlist and gets a place, with a Telegram notification, as soon as someone leaves. Registration closes registration_cutoff_minutes before the event starts.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Event"
+                ],
+                "summary": "Join Event",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "tma and the web app init data",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Event ID",
+                        "name": "eventId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.EventRegistration"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "This API cancels a user's registration for an event that has not started. The first user on the waitlist takes the freed place.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Event"
+                ],
+                "summary": "Leave Event",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "tma and the web app init data",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Event ID",
+                        "name": "eventId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/{id}/events/{eventId}/quiz": {
+            "post": {
+                "description": "This API starts the quiz of an event the user checked in to. It opens when the event is completed and closes after the event quiz window, and earns bonus XP on top of the usual reward. It is timed as MEDIUM quizzes are. Each user has one session of it open at a time and submits it once.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Question"
+                ],
+                "summary": "Start Event Quiz",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "tma and the web app init data",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Event ID",
+                        "name": "eventId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/quiz.Session"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/{id}/events/{eventId}/ticket": {
+            "get": {
+                "description": "This API returns the check-in QR code of a registered participant as a PNG image, or as JSON with format=json. The code expires after a few minutes and is shown to an officer at check-in and check-out.",
+                "produces": [
+                    "image/png",
+                    "application/json"
+                ],
+                "tags": [
+                    "Attendance"
+                ],
+                "summary": "Get Event Ticket",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "tma and the web app init data",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Event ID",
+                        "name": "eventId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "png",
+                            "json"
+                        ],
+                        "type": "string",
+                        "description": "png (default) or json",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Ticket"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/{id}/freeze": {
+            "post": {
+                "description": "This API freezes an account: it earns no XP and cannot start quizzes or order from the market until unfrozen. Admins only.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "XP Rules"
+                ],
+                "summary": "Freeze User",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "tma and the web app init data",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/{id}/friends": {
+            "get": {
+                "description": "This API returns the friends of a user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Friend"
+                ],
+                "summary": "List Friends",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Friend"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/{id}/friends/{friendId}": {
+            "post": {
+                "description": "This API makes two users friends of each other",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Friend"
+                ],
+                "summary": "Add Friend",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "tma and the web app init data",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Friend user ID",
+                        "name": "friendId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Message"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "This API removes the friendship between two users",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Friend"
+                ],
+                "summary": "Remove Friend",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "tma and the web app init data",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Friend user ID",
+                        "name": "friendId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/{id}/orders": {
+            "get": {
+                "description": "This API returns the orders placed by a user, newest first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Order"
+                ],
+                "summary": "List My Orders",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "tma and the web app init data",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "pending",
+                            "ready_for_pickup",
+                            "fulfilled",
+                            "cancelled",
+                            "refunded"
+                        ],
+                        "type": "string",
+                        "description": "Order status",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Order"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/{id}/orders/{orderId}": {
+            "get": {
+                "description": "This API returns a single order of a user including its pickup code",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Order"
+                ],
+                "summary": "Get My Order",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "tma and the web app init data",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Order ID",
+                        "name": "orderId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Order"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/{id}/orders/{orderId}/cancel": {
+            "post": {
+                "description": "This API lets a user cancel an order that has not been handed over yet. The XP is returned and the item restocked.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Order"
+                ],
+                "summary": "Cancel My Order",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "tma and the web app init data",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Order ID",
+                        "name": "orderId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Order"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/{id}/quizzes": {
+            "post": {
+                "description": "This API assembles 10 questions of a difficulty, and optionally a topic, from the question bank. ADAPTIVE picks the difficulty nearest the user's rating in the topic. It prefers questions the user has not seen and starts a quiz session. The bank is topped up with generated questions in the background. Only the questions and their variants are returned, the answers stay on the server until the session is submitted within 30 minutes. Quizzes of a difficulty with a time limit are timed: their questions are left out and served one at a time, each to be answered within the time limit. Frozen accounts cannot start quizzes, and the XP rules can limit the quizzes started per day and how soon after one another.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Question"
+                ],
+                "summary": "Start Quiz",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "tma and the web app init data",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Difficulty: EASY, MEDIUM, HARD or ADAPTIVE, and an optional topic",
+                        "name": "quiz",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.QuizRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/quiz.Session"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/{id}/quizzes/{sessionId}/answers": {
+            "post": {
+                "description": "Grades the answers to a quiz session, updates the user's ratings and adds the XP earned. Each correct answer earns the quiz_answer reward of the XP rules for a question at the user's rating, up to twice as much for harder questions and less for easier ones, and timed quizzes earn the quiz_speed reward in proportion to the time left on correct answers, all subject to the multipliers and caps of the rules. Timed quizzes are graded on the answers given as questions were served; answers submitted here for served questions not answered yet count as given now, late answers count wrong and unserved questions are ignored. A session can be submitted once, within 30 minutes of being started. Event quizzes earn the event quiz bonus on top, in proportion to the score, and are graded once per user.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "EarnXP",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "tma and the web app init data",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Quiz session ID",
+                        "name": "sessionId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Chosen variants by question number",
+                        "name": "answers",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.QuizAnswers"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/quiz.Result"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "410": {
+                        "description": "Gone",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/{id}/quizzes/{sessionId}/questions/{number}": {
+            "post": {
+                "description": "This API serves a question of a timed quiz, by number from 1, and starts its time limit. Serving it again returns the same deadline. Answers after the deadline count wrong.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Question"
+                ],
+                "summary": "Serve Quiz Question",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "tma and the web app init data",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Quiz session ID",
+                        "name": "sessionId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Question number",
+                        "name": "number",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/quiz.Served"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "410": {
+                        "description": "Gone",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/{id}/quizzes/{sessionId}/questions/{number}/answer": {
+            "post": {
+                "description": "This API records the answer to a served question of a timed quiz. Each question is answered once, and late answers count wrong. Whether it is correct is told when the quiz is submitted.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Question"
+                ],
+                "summary": "Answer Quiz Question",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "tma and the web app init data",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Quiz session ID",
+                        "name": "sessionId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Question number",
+                        "name": "number",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Chosen variant",
+                        "name": "answer",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.QuizAnswer"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/quiz.Answered"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "410": {
+                        "description": "Gone",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/{id}/ratings": {
+            "get": {
+                "description": "This API lists the skill ratings of a user, overall under general and per topic. Ratings start at 1200, the rating of MEDIUM questions, with EASY questions at 1000 and HARD at 1400, and move with every answered question.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Question"
+                ],
+                "summary": "List User Ratings",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "tma and the web app init data",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/quiz.Rating"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/{id}/role": {
+            "put": {
+                "description": "This API gives a user the user, teacher, officer or admin role. Admins cannot change their own role. Admins only.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Set User Role",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "tma and the web app init data",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New role",
+                        "name": "role",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.RoleUpdate"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/{id}/unfreeze": {
+            "post": {
+                "description": "This API unfreezes a frozen account. XP reversed while it was frozen stays reversed. Admins only.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "XP Rules"
+                ],
+                "summary": "Unfreeze User",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "tma and the web app init data",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users": {
+            "get": {
+                "description": "This API returns a page of users",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "List Users",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page size (1-100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "id",
+                            "-id",
+                            "xp",
+                            "-xp",
+                            "first_name",
+                            "-first_name",
+                            "last_name",
+                            "-last_name"
+                        ],
+                        "type": "string",
+                        "description": "Sort key, prefix with - for descending",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Region",
+                        "name": "region",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.User"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/xp/flags": {
+            "get": {
+                "description": "This API lists accounts flagged by the anomaly detector: earning_rate flags for earning far more XP than other users, fast_quiz flags for perfect quizzes answered faster than a person reads. Admins only.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "XP Rules"
+                ],
+                "summary": "List XP Flags",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "tma and the web app init data",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "open",
+                            "dismissed",
+                            "confirmed"
+                        ],
+                        "type": "string",
+                        "description": "Review status, open by default",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "earning_rate",
+                            "fast_quiz"
+                        ],
+                        "type": "string",
+                        "description": "Only flags for this reason",
+                        "name": "reason",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only flags of this user",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (1-100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/xp.Flag"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/xp/flags/{id}": {
+            "put": {
+                "description": "This API dismisses or confirms an XP anomaly flag. Confirming it can also freeze the account, so it earns no XP and cannot start quizzes or order from the market, and reverse the XP the flag covers: the quiz's XP for fast_quiz flags, all XP earned over the flagged period for earning_rate flags. Reversed XP already spent leaves a negative balance. Admins only.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "XP Rules"
+                ],
+                "summary": "Review XP Flag",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "tma and the web app init data",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Flag ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Review",
+                        "name": "review",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.XPFlagReview"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/xp.Flag"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/xp/rule-sets": {
+            "get": {
+                "description": "This API lists the stored XP rule sets, newest first by default. Admins only.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "XP Rules"
+                ],
+                "summary": "List XP Rule Sets",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "tma and the web app init data",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "created_at",
+                            "-created_at"
+                        ],
+                        "type": "string",
+                        "description": "Sort key, prefixed with - for descending order",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (1-100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/xp.RuleSet"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "This API stores an XP rule set, inactive, from a YAML or JSON body. Rules have rewards per action (signup, quiz_answer, quiz_speed), multipliers, daily_caps and hourly_caps per source, and limits on quizzes (quizzes_per_day, and quiz_cooldown in seconds). A multiplier has a name and a factor and applies while all of its conditions hold: sources, weekdays, min_streak (consecutive days with earned XP), from and until (campaigns) and season (a season ID). Multipliers applying together multiply. Rule sets cannot be edited; preview a new one and activate it instead. Admins only.",
+                "consumes": [
+                    "text/plain",
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "XP Rules"
+                ],
+                "summary": "Create XP Rule Set",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "tma and the web app init data",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Name of the rule set",
+                        "name": "name",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "description": "Rules, as YAML or JSON",
+                        "name": "rules",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/xp.Rules"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/xp.RuleSet"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/xp/rule-sets/{id}": {
+            "get": {
+                "description": "This API returns a stored XP rule set. Admins only.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "XP Rules"
+                ],
+                "summary": "Get XP Rule Set",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "tma and the web app init data",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Rule set ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/xp.RuleSet"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/xp/rule-sets/{id}/activate": {
+            "post": {
+                "description": "This API puts a rule set in force in place of the active one. XP earned from then on is credited under it. Admins only.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "XP Rules"
+                ],
+                "summary": "Activate XP Rule Set",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "tma and the web app init data",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Rule set ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/xp.RuleSet"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/xp/rule-sets/{id}/preview": {
+            "post": {
+                "description": "This API is a dry run of a rule set: it replays the XP earned in the last days under the rule set, without crediting anything, and compares it with the XP actually credited, per source. Base XP is scaled by the rule set's rewards relative to the rules in force, and multipliers and caps are applied anew. Admins only.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "XP Rules"
+                ],
+                "summary": "Preview XP Rule Set",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "tma and the web app init data",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Rule set ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Days to replay, counting today (1-90, default 7)",
+                        "name": "days",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/xp.Preview"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/xp/rules": {
+            "get": {
+                "description": "This API returns the XP rules in force and where they come from: the active rule set, the rules file or the built-in rules. Admins only.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "XP Rules"
+                ],
+                "summary": "Get XP Rules",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "tma and the web app init data",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/xp.ActiveRules"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/webhandlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "checkin.Result": {
+            "type": "object",
+            "properties": {
+                "checked_in_at": {
+                    "type": "string"
+                },
+                "distance_m": {
+                    "type": "number"
+                },
+                "event_id": {
+                    "type": "string"
+                },
+                "event_name": {
+                    "type": "string"
+                },
+                "flags": {
+                    "description": "Flags lists the reasons the check-in was flagged for review.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "geo.Feature": {
+            "type": "object",
+            "properties": {
+                "geometry": {
+                    "$ref": "#/definitions/geo.Geometry"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "properties": {
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "geo.FeatureCollection": {
+            "type": "object",
+            "properties": {
+                "features": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/geo.Feature"
+                    }
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "geo.Geometry": {
+            "type": "object",
+            "properties": {
+                "coordinates": {},
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "geo.Polygon": {
+            "type": "object",
+            "properties": {
+                "coordinates": {
+                    "type": "array",
+                    "items": {
+                        "type": "array",
+                        "items": {
+                            "type": "array",
+                            "items": {
+                                "type": "number"
+                            }
+                        }
+                    }
+                },
+                "type": {
+                    "type": "string",
+                    "example": "Polygon"
+                }
+            }
+        },
+        "models.Attendance": {
+            "type": "object",
+            "properties": {
+                "checked_in_at": {
+                    "type": "string"
+                },
+                "checked_out_at": {
+                    "type": "string"
+                },
+                "event_id": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                },
+                "xp_earned": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Badge": {
+            "type": "object",
+            "properties": {
+                "awarded_at": {
+                    "type": "string"
+                },
+                "badge": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "rank": {
+                    "type": "integer"
+                },
+                "region": {
+                    "type": "string"
+                },
+                "season_id": {
+                    "type": "integer"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.CalendarFeed": {
+            "type": "object",
+            "properties": {
+                "url": {
+                    "type": "string"
+                },
+                "webcal_url": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CheckinFlag": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "details": {
+                    "type": "string"
+                },
+                "event_id": {
+                    "type": "string"
+                },
+                "event_name": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "reviewed_at": {
+                    "type": "string"
+                },
+                "reviewed_by": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                },
+                "user_name": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Event": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string"
+                },
+                "area": {
+                    "$ref": "#/definitions/geo.Polygon"
+                },
+                "capacity": {
+                    "description": "Capacity is the number of places, nil for unlimited.",
+                    "type": "integer"
+                },
+                "checkin_radius_m": {
+                    "description": "CheckinRadiusM is how close to the coordinates participants must be\nto check themselves in. It defaults to DefaultCheckinRadius.",
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "end_date": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "image": {
+                    "type": "string"
+                },
+                "latitude": {
+                    "type": "number"
+                },
+                "location": {
+                    "type": "string"
+                },
+                "longitude": {
+                    "type": "number"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "officers": {
+                    "description": "Officers are the lead officer, first, and the assistants. An event is\ncreated with a lead.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.EventOfficer"
+                    }
+                },
+                "prorate_xp": {
+                    "description": "ProrateXP credits TotalXP in proportion to the time attended.",
+                    "type": "boolean"
+                },
+                "region": {
+                    "type": "string"
+                },
+                "registration_cutoff_minutes": {
+                    "description": "RegistrationCutoffMinutes closes registration this long before\nStartDate. It defaults to DefaultRegistrationCutoff.",
+                    "type": "integer"
+                },
+                "series_id": {
+                    "description": "SeriesID is set on the occurrences of a recurring event.",
+                    "type": "string"
+                },
+                "start_date": {
+                    "type": "string"
+                },
+                "status": {
+                    "description": "Status is the lifecycle status, one of the Event* constants. New\nevents are published unless created as drafts.",
+                    "type": "string"
+                },
+                "total_xp": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.EventCancellation": {
+            "type": "object",
+            "properties": {
+                "reason": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.EventOfficer": {
+            "type": "object",
+            "properties": {
+                "avatar": {
+                    "type": "string"
+                },
+                "first_name": {
+                    "type": "string"
+                },
+                "last_name": {
+                    "type": "string"
+                },
+                "phone_number": {
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.EventRegistration": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "event_id": {
+                    "type": "string"
+                },
+                "event_name": {
+                    "type": "string"
+                },
+                "position": {
+                    "description": "Position is the place in the waitlist, starting at 1.",
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.EventSeries": {
+            "type": "object",
+            "required": [
+                "duration_minutes",
+                "name",
+                "rrule",
+                "start"
+            ],
+            "properties": {
+                "address": {
+                    "type": "string"
+                },
+                "area": {
+                    "$ref": "#/definitions/geo.Polygon"
+                },
+                "capacity": {
+                    "type": "integer"
+                },
+                "checkin_radius_m": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "duration_minutes": {
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "exdates": {
+                    "description": "Exdates are the scheduled starts of cancelled occurrences.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "image": {
+                    "type": "string"
+                },
+                "latitude": {
+                    "type": "number"
+                },
+                "location": {
+                    "type": "string"
+                },
+                "longitude": {
+                    "type": "number"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "occurrences": {
+                    "description": "Occurrences are the events of the series that have not ended.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Event"
+                    }
+                },
+                "officers": {
+                    "description": "Officers are assigned to every occurrence.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.EventOfficer"
+                    }
+                },
+                "prorate_xp": {
+                    "type": "boolean"
+                },
+                "region": {
+                    "type": "string"
+                },
+                "registration_cutoff_minutes": {
+                    "type": "integer"
+                },
+                "rrule": {
+                    "description": "RRule is an iCalendar recurrence rule, e.g. FREQ=WEEKLY;BYDAY=SA.",
+                    "type": "string"
+                },
+                "start": {
+                    "description": "Start is the start of the first occurrence. Every occurrence starts\nat the same wall clock time in Timezone.",
+                    "type": "string"
+                },
+                "timezone": {
+                    "description": "Timezone is an IANA time zone, DefaultTimezone if empty.",
+                    "type": "string"
+                },
+                "total_xp": {
+                    "type": "integer"
+                },
+                "until": {
+                    "description": "Until is set once later occurrences were split off into another\nseries by an edit of all future occurrences.",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.EventStatus": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.FlagReview": {
+            "type": "object",
+            "required": [
+                "status"
+            ],
+            "properties": {
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Friend": {
+            "type": "object",
+            "properties": {
+                "avatar": {
+                    "type": "string"
+                },
+                "first_name": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_name": {
+                    "type": "string"
+                },
+                "region": {
+                    "type": "string"
+                },
+                "xp": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.History": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "end_date": {
+                    "type": "string"
+                },
+                "event_id": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "start_date": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                },
+                "xp_earned": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.LocationCheckIn": {
+            "type": "object",
+            "required": [
+                "latitude",
+                "longitude"
+            ],
+            "properties": {
+                "accuracy": {
+                    "description": "Accuracy is the horizontal accuracy in meters reported by the device.",
+                    "type": "number"
+                },
+                "device_id": {
+                    "type": "string"
+                },
+                "latitude": {
+                    "type": "number"
+                },
+                "longitude": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.Market": {
+            "type": "object",
+            "properties": {
+                "category_name": {
+                    "type": "string"
+                },
+                "count": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "image_url": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "xp": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Message": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.NearbyEvent": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string"
+                },
+                "area": {
+                    "$ref": "#/definitions/geo.Polygon"
+                },
+                "capacity": {
+                    "description": "Capacity is the number of places, nil for unlimited.",
+                    "type": "integer"
+                },
+                "checkin_radius_m": {
+                    "description": "CheckinRadiusM is how close to the coordinates participants must be\nto check themselves in. It defaults to DefaultCheckinRadius.",
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "distance_m": {
+                    "type": "number"
+                },
+                "end_date": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "image": {
+                    "type": "string"
+                },
+                "latitude": {
+                    "type": "number"
+                },
+                "location": {
+                    "type": "string"
+                },
+                "longitude": {
+                    "type": "number"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "officers": {
+                    "description": "Officers are the lead officer, first, and the assistants. An event is\ncreated with a lead.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.EventOfficer"
+                    }
+                },
+                "prorate_xp": {
+                    "description": "ProrateXP credits TotalXP in proportion to the time attended.",
+                    "type": "boolean"
+                },
+                "region": {
+                    "type": "string"
+                },
+                "registration_cutoff_minutes": {
+                    "description": "RegistrationCutoffMinutes closes registration this long before\nStartDate. It defaults to DefaultRegistrationCutoff.",
+                    "type": "integer"
+                },
+                "series_id": {
+                    "description": "SeriesID is set on the occurrences of a recurring event.",
+                    "type": "string"
+                },
+                "start_date": {
+                    "type": "string"
+                },
+                "status": {
+                    "description": "Status is the lifecycle status, one of the Event* constants. New\nevents are published unless created as drafts.",
+                    "type": "string"
+                },
+                "total_xp": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Order": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "handled_by": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "item_id": {
+                    "type": "integer"
+                },
+                "item_name": {
+                    "type": "string"
+                },
+                "order_number": {
+                    "type": "integer"
+                },
+                "pickup_code": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                },
+                "xp_spent": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.OrderStatusUpdate": {
+            "type": "object",
+            "required": [
+                "status"
+            ],
+            "properties": {
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Participant": {
+            "type": "object",
+            "properties": {
+                "checked_in_at": {
+                    "type": "string"
+                },
+                "checked_out_at": {
+                    "type": "string"
+                },
+                "first_name": {
+                    "type": "string"
+                },
+                "last_name": {
+                    "type": "string"
+                },
+                "phone_number": {
+                    "type": "string"
+                },
+                "position": {
+                    "type": "integer"
+                },
+                "registered_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.PickupVerification": {
+            "type": "object",
+            "required": [
+                "pickup_code"
+            ],
+            "properties": {
+                "pickup_code": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.QuestionReview": {
+            "type": "object",
+            "required": [
+                "status"
+            ],
+            "properties": {
+                "note": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.QuizAnswer": {
+            "type": "object",
+            "required": [
+                "answer"
+            ],
+            "properties": {
+                "answer": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.QuizAnswers": {
+            "type": "object",
+            "required": [
+                "answers"
+            ],
+            "properties": {
+                "answers": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.QuizRequest": {
+            "type": "object",
+            "required": [
+                "difficulty"
+            ],
+            "properties": {
+                "difficulty": {
+                    "type": "string"
+                },
+                "topic": {
+                    "description": "Topic is a topic key from GET /quiz/topics, any topic when empty.",
+                    "type": "string"
+                }
+            }
+        },
+        "models.RankingResponse": {
+            "type": "object",
+            "properties": {
+                "avatar": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "location": {
+                    "type": "string"
+                },
+                "rank": {
+                    "type": "integer"
+                },
+                "user_name": {
+                    "type": "string"
+                },
+                "xp": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.RoleUpdate": {
+            "type": "object",
+            "required": [
+                "role"
+            ],
+            "properties": {
+                "role": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.SearchResult": {
+            "type": "object",
+            "properties": {
+                "highlight": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "rank": {
+                    "type": "number"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Season": {
+            "type": "object",
+            "required": [
+                "ends_at",
+                "name",
+                "starts_at"
+            ],
+            "properties": {
+                "closed_at": {
+                    "type": "string"
+                },
+                "ends_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "prizes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.SeasonPrize"
+                    }
+                },
+                "starts_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.SeasonPrize": {
+            "type": "object",
+            "required": [
+                "top_n"
+            ],
+            "properties": {
+                "badge": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "season_id": {
+                    "type": "integer"
+                },
+                "top_n": {
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "xp": {
+                    "type": "integer",
+                    "minimum": 0
+                }
+            }
+        },
+        "models.Ticket": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.TicketScan": {
+            "type": "object",
+            "required": [
+                "token"
+            ],
+            "properties": {
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.UnassignedOfficer": {
+            "type": "object",
+            "properties": {
+                "event_id": {
+                    "type": "string"
+                },
+                "image": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "series_id": {
+                    "type": "string"
+                },
+                "title": {
+                    "description": "Title is the name of the event or series.",
+                    "type": "string"
+                }
+            }
+        },
+        "models.User": {
+            "type": "object"
+        },
+        "models.XPFlagReview": {
+            "type": "object",
+            "required": [
+                "status"
+            ],
+            "properties": {
+                "freeze": {
+                    "type": "boolean"
+                },
+                "reverse": {
+                    "type": "boolean"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "quiz.Answered": {
+            "type": "object",
+            "properties": {
+                "answered_at": {
+                    "type": "string"
+                },
+                "late": {
+                    "description": "Late reports that the answer came after the time limit and counts\nwrong.",
+                    "type": "boolean"
+                },
+                "number": {
+                    "type": "integer"
+                }
+            }
+        },
+        "quiz.AuditEntry": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "after": {
+                    "type": "object"
+                },
+                "before": {
+                    "type": "object"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "note": {
+                    "type": "string"
+                },
+                "question_id": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "quiz.BankQuestion": {
             "type": "object",
             "properties": {
-                "correct_count": {
+                "answer": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
                     "type": "integer"
                 },
                 "difficulty": {
                     "type": "string"
                 },
+                "event_id": {
+                    "description": "EventID is set on the questions of an event quiz.",
+                    "type": "string"
+                },
                 "id": {
+                    "type": "string"
+                },
+                "language": {
+                    "type": "string"
+                },
+                "question": {
+                    "type": "string"
+                },
+                "reviewed_at": {
+                    "type": "string"
+                },
+                "reviewed_by": {
                     "type": "integer"
+                },
+                "source": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "topic": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "variants": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/quiz.Variant"
+                    }
                 }
             }
         },
-        "models.Event": {
+        "quiz.ImportReport": {
             "type": "object",
             "properties": {
-                "created_at": {
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/quiz.RowError"
+                    }
+                },
+                "imported": {
+                    "type": "integer"
+                }
+            }
+        },
+        "quiz.QuestionInput": {
+            "type": "object",
+            "properties": {
+                "answer": {
                     "type": "string"
                 },
-                "description": {
+                "difficulty": {
                     "type": "string"
                 },
-                "end_date": {
+                "language": {
                     "type": "string"
                 },
-                "id": {
+                "question": {
                     "type": "string"
                 },
-                "image": {
+                "topic": {
                     "type": "string"
                 },
-                "location": {
+                "variants": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/quiz.Variant"
+                    }
+                }
+            }
+        },
+        "quiz.QuestionTiming": {
+            "type": "object",
+            "properties": {
+                "correct_rate": {
+                    "description": "CorrectRate is the share of correct answers, in time.",
+                    "type": "number"
+                },
+                "difficulty": {
                     "type": "string"
                 },
-                "name": {
+                "id": {
                     "type": "string"
                 },
-                "resp_officer": {
+                "late_rate": {
+                    "description": "LateRate is the share of answers after the time limit, or missing.",
+                    "type": "number"
+                },
+                "median_seconds": {
+                    "description": "MedianSeconds is the median time taken to answer, nil when the question\nwas never answered.",
+                    "type": "number"
+                },
+                "question": {
                     "type": "string"
                 },
-                "resp_officer_image": {
+                "served": {
+                    "description": "Served is the number of graded sessions the question was served in.",
+                    "type": "integer"
+                },
+                "suggested_difficulty": {
+                    "description": "SuggestedDifficulty is the difficulty the answers point to, once the\nquestion was served often enough: EASY when most answer it correctly\nwith time to spare, HARD when most fail or take nearly all the time.",
                     "type": "string"
                 },
-                "start_date": {
+                "time_used": {
+                    "description": "TimeUsed is the mean share of the time limit taken to answer, up to 1.",
+                    "type": "number"
+                },
+                "topic": {
                     "type": "string"
+                }
+            }
+        },
+        "quiz.Rating": {
+            "type": "object",
+            "properties": {
+                "answered": {
+                    "description": "Answered is the number of questions rated.",
+                    "type": "integer"
                 },
-                "total_xp": {
+                "rating": {
                     "type": "integer"
                 },
+                "topic": {
+                    "type": "string"
+                },
                 "updated_at": {
                     "type": "string"
                 }
             }
         },
-        "models.History": {
+        "quiz.Result": {
             "type": "object",
             "properties": {
-                "created_at": {
+                "answers": {
+                    "description": "Answers is the answer key by question number, sent once graded.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "capped": {
+                    "description": "Capped reports whether the daily quiz XP cap cut the reward.",
+                    "type": "boolean"
+                },
+                "correct_count": {
+                    "type": "integer"
+                },
+                "late": {
+                    "description": "Late is the number of questions of a timed session answered after the\ntime limit, or not at all, counted wrong.",
+                    "type": "integer"
+                },
+                "multipliers": {
+                    "description": "Multipliers are the XP multipliers applied, by name.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "ratings": {
+                    "description": "Ratings are the updated ratings of the user by topic.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "session_id": {
                     "type": "string"
                 },
-                "end_date": {
+                "total": {
+                    "type": "integer"
+                },
+                "xp_earned": {
+                    "type": "integer"
+                }
+            }
+        },
+        "quiz.RowError": {
+            "type": "object",
+            "properties": {
+                "error": {
                     "type": "string"
                 },
-                "event_id": {
+                "row": {
+                    "type": "integer"
+                }
+            }
+        },
+        "quiz.Served": {
+            "type": "object",
+            "properties": {
+                "answered": {
+                    "type": "boolean"
+                },
+                "deadline": {
+                    "description": "Deadline is when the time limit runs out. Later answers count wrong.",
                     "type": "string"
                 },
-                "id": {
+                "number": {
+                    "type": "integer"
+                },
+                "served_at": {
                     "type": "string"
                 },
-                "start_date": {
+                "test": {
+                    "description": "Test is the question and its variants, as in the tests of untimed\nsessions.",
+                    "type": "object"
+                },
+                "time_limit": {
+                    "description": "TimeLimit is the time limit in seconds.",
+                    "type": "number"
+                }
+            }
+        },
+        "quiz.Session": {
+            "type": "object",
+            "properties": {
+                "difficulty": {
                     "type": "string"
                 },
-                "updated_at": {
+                "event_id": {
+                    "description": "EventID is set on the quizzes of events.",
                     "type": "string"
                 },
-                "user_id": {
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "question_count": {
                     "type": "integer"
                 },
-                "xp_earned": {
+                "tests": {
+                    "description": "Questions are the tests of untimed sessions. Timed sessions serve their\ntests one at a time, and leave them out.",
+                    "type": "array",
+                    "items": {
+                        "type": "object"
+                    }
+                },
+                "time_limit": {
+                    "description": "TimeLimit is the time limit of each question of timed sessions, in\nseconds.",
+                    "type": "number"
+                },
+                "timed": {
+                    "type": "boolean"
+                },
+                "user_id": {
                     "type": "integer"
                 }
             }
         },
-        "models.Market": {
+        "quiz.Topic": {
             "type": "object",
             "properties": {
-                "category_name": {
+                "key": {
                     "type": "string"
                 },
-                "count": {
+                "name": {
+                    "description": "Name is shown to users.",
+                    "type": "string"
+                }
+            }
+        },
+        "quiz.Variant": {
+            "type": "object",
+            "properties": {
+                "key": {
+                    "type": "string"
+                },
+                "text": {
+                    "type": "string"
+                }
+            }
+        },
+        "webhandlers.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                }
+            }
+        },
+        "xp.ActiveRules": {
+            "type": "object",
+            "properties": {
+                "origin": {
+                    "type": "string"
+                },
+                "rule_set_id": {
+                    "description": "RuleSetID is the active rule set, nil unless Origin is rule_set.",
                     "type": "integer"
                 },
+                "rules": {
+                    "$ref": "#/definitions/xp.Rules"
+                }
+            }
+        },
+        "xp.Flag": {
+            "type": "object",
+            "properties": {
                 "created_at": {
                     "type": "string"
                 },
-                "description": {
+                "details": {
+                    "type": "string"
+                },
+                "ended_at": {
                     "type": "string"
                 },
+                "frozen": {
+                    "description": "Frozen reports whether the account is frozen now.",
+                    "type": "boolean"
+                },
                 "id": {
                     "type": "integer"
                 },
-                "image_url": {
+                "reason": {
                     "type": "string"
                 },
-                "name": {
+                "ref": {
                     "type": "string"
                 },
-                "updated_at": {
+                "reversed_xp": {
+                    "description": "ReversedXP is the XP taken back on review.",
+                    "type": "integer"
+                },
+                "reviewed_at": {
                     "type": "string"
                 },
-                "xp": {
+                "reviewed_by": {
+                    "type": "integer"
+                },
+                "started_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "user_id": {
                     "type": "integer"
+                },
+                "user_name": {
+                    "type": "string"
                 }
             }
         },
-        "models.Message": {
+        "xp.Limits": {
             "type": "object",
             "properties": {
-                "message": {
+                "quiz_cooldown": {
+                    "description": "QuizCooldown is the least number of seconds between starting quizzes.",
+                    "type": "integer"
+                },
+                "quizzes_per_day": {
+                    "description": "QuizzesPerDay bounds the quizzes a user starts in a day, event quizzes\naside.",
+                    "type": "integer"
+                }
+            }
+        },
+        "xp.Multiplier": {
+            "type": "object",
+            "properties": {
+                "factor": {
+                    "type": "number"
+                },
+                "from": {
+                    "description": "From and Until bound campaigns. Until is exclusive.",
+                    "type": "string"
+                },
+                "min_streak": {
+                    "description": "MinStreak is the number of consecutive days, ending with the day of the\naward, on which the user earned XP.",
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "season": {
+                    "description": "Season is the ID of a season it applies during.",
+                    "type": "integer"
+                },
+                "sources": {
+                    "description": "Sources are the sources it applies to, all of RuledSources when empty.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "until": {
                     "type": "string"
+                },
+                "weekdays": {
+                    "description": "Weekdays are English day names, e.g. saturday.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 }
             }
         },
-        "models.RankingResponse": {
+        "xp.Preview": {
             "type": "object",
             "properties": {
-                "avatar": {
+                "actual": {
+                    "type": "integer"
+                },
+                "from": {
                     "type": "string"
                 },
-                "id": {
+                "preview": {
                     "type": "integer"
                 },
-                "location": {
+                "sources": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/xp.SourcePreview"
+                    }
+                },
+                "until": {
                     "type": "string"
                 },
-                "rank": {
+                "users": {
+                    "description": "Users is the number of users whose XP would differ.",
+                    "type": "integer"
+                }
+            }
+        },
+        "xp.RuleSet": {
+            "type": "object",
+            "properties": {
+                "activated_at": {
+                    "type": "string"
+                },
+                "activated_by": {
                     "type": "integer"
                 },
-                "user_name": {
+                "active": {
+                    "type": "boolean"
+                },
+                "created_at": {
                     "type": "string"
                 },
-                "xp": {
+                "created_by": {
+                    "type": "integer"
+                },
+                "id": {
                     "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "rules": {
+                    "$ref": "#/definitions/xp.Rules"
                 }
             }
         },
-        "models.User": {
-            "type": "object"
+        "xp.Rules": {
+            "type": "object",
+            "properties": {
+                "daily_caps": {
+                    "description": "DailyCaps bound the XP a user earns from a source in a day.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "hourly_caps": {
+                    "description": "HourlyCaps bound the XP a user earns from a source in the last hour.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "limits": {
+                    "$ref": "#/definitions/xp.Limits"
+                },
+                "multipliers": {
+                    "description": "Multipliers apply together, multiplying their factors.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/xp.Multiplier"
+                    }
+                },
+                "rewards": {
+                    "description": "Rewards is the XP of each action.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "number"
+                    }
+                }
+            }
         },
-        "webhandlers.ErrorResponse": {
+        "xp.SourcePreview": {
             "type": "object",
             "properties": {
-                "error": {
+                "actual": {
+                    "type": "integer"
+                },
+                "entries": {
+                    "type": "integer"
+                },
+                "preview": {
+                    "type": "integer"
+                },
+                "source": {
                     "type": "string"
                 }
             }
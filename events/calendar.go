@@ -0,0 +1,86 @@
+package events
+
+import (
+	"context"
+	"strconv"
+	"time"
+	"worker-bot/ical"
+	"worker-bot/models"
+)
+
+// maxCalendarEvents bounds the events of a feed.
+const maxCalendarEvents = 1000
+
+// uidDomain makes event IDs globally unique iCalendar UIDs.
+const uidDomain = "@events.worker-bot"
+
+type calendarRow struct {
+	ID          string    `db:"id"`
+	Name        string    `db:"name"`
+	Description string    `db:"description"`
+	StartDate   time.Time `db:"start_date"`
+	EndDate     time.Time `db:"end_date"`
+	UpdatedAt   time.Time `db:"updated_at"`
+	Place       string    `db:"place"`
+	Latitude    *float64  `db:"latitude"`
+	Longitude   *float64  `db:"longitude"`
+	Timezone    string    `db:"timezone"`
+	InSeries    bool      `db:"in_series"`
+	Status      string    `db:"status"`
+}
+
+// Calendar returns the events matching where, a condition on the events
+// table e, as iCalendar events ordered by start. status is an expression
+// giving their iCalendar status, e.g. to mark waitlisted registrations
+// tentative, and confirmed or cancelled if empty. args are bound to the placeholders of
+// status, then of where. Occurrences of series are stored in UTC and shown
+// in the time zone of their series, single events are wall clock times in
+// DefaultTimezone.
+func (f *Finder) Calendar(ctx context.Context, where, status string, args ...interface{}) ([]ical.Event, error) {
+	if status == "" {
+		status = "CASE WHEN e.status = 'cancelled' THEN '" + ical.StatusCancelled + "' ELSE '" + ical.StatusConfirmed + "' END"
+	}
+
+	defaultZone, err := time.LoadLocation(models.DefaultTimezone)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []calendarRow
+	query := f.db.Rebind(`SELECT e.id, e.name, COALESCE(e.description, '') AS description, e.start_date, e.end_date,
+				COALESCE(e.updated_at, e.created_at, CURRENT_TIMESTAMP) AS updated_at,
+				CONCAT_WS(', ', NULLIF(e.location, ''), NULLIF(e.address, '')) AS place,
+				e.latitude, e.longitude, COALESCE(s.timezone, '` + models.DefaultTimezone + `') AS timezone, e.series_id IS NOT NULL AS in_series,
+				` + status + ` AS status
+			  FROM events e LEFT JOIN event_series s ON s.id = e.series_id
+			  WHERE ` + where + `
+			  ORDER BY e.start_date LIMIT ` + strconv.Itoa(maxCalendarEvents))
+	if err := f.db.SelectContext(ctx, &rows, query, args...); err != nil {
+		return nil, err
+	}
+
+	out := make([]ical.Event, 0, len(rows))
+	for _, row := range rows {
+		loc, err := time.LoadLocation(row.Timezone)
+		if err != nil {
+			return nil, err
+		}
+		start, end := inZone(row.StartDate, loc), inZone(row.EndDate, loc)
+		if row.InSeries {
+			start, end = inZone(row.StartDate, time.UTC).In(loc), inZone(row.EndDate, time.UTC).In(loc)
+		}
+		out = append(out, ical.Event{
+			UID:         row.ID + uidDomain,
+			Summary:     row.Name,
+			Description: row.Description,
+			Location:    row.Place,
+			Lat:         row.Latitude,
+			Lng:         row.Longitude,
+			Start:       start,
+			End:         end,
+			Modified:    inZone(row.UpdatedAt, defaultZone),
+			Status:      row.Status,
+		})
+	}
+	return out, nil
+}
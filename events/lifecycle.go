@@ -0,0 +1,127 @@
+package events
+
+import (
+	"context"
+	"log"
+	"worker-bot/models"
+	"worker-bot/xp"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// checkOutReturning computes the attendance of a registration r of event e
+// that was just checked out. Attendance outside the event's own time window
+// does not count towards pro-rated XP.
+const checkOutReturning = `RETURNING r.event_id, r.user_id, r.checked_in_at, r.checked_out_at,
+				CASE WHEN NOT e.prorate_xp OR e.end_date <= e.start_date THEN e.total_xp
+				ELSE FLOOR(e.total_xp * GREATEST(0, LEAST(1,
+					EXTRACT(EPOCH FROM LEAST(r.checked_out_at, e.end_date) - GREATEST(r.checked_in_at, e.start_date))
+					/ EXTRACT(EPOCH FROM e.end_date - e.start_date))))::bigint END AS xp_earned`
+
+// CheckOut checks a registration out on behalf of an officer, records the
+// attendance in history and credits the event's XP.
+func CheckOut(ctx context.Context, tx *sqlx.Tx, rules *xp.Engine, registrationID, officerID int64) (models.Attendance, error) {
+	var a models.Attendance
+	err := tx.GetContext(ctx, &a, `UPDATE event_registrations r
+			  SET checked_out_at = CURRENT_TIMESTAMP, checked_out_by = $2, updated_at = CURRENT_TIMESTAMP
+			  FROM events e WHERE e.id = r.event_id AND r.id = $1 `+checkOutReturning, registrationID, officerID)
+	if err != nil {
+		return a, err
+	}
+	return a, credit(ctx, tx, rules, &a)
+}
+
+// credit credits the XP of an attendance under the XP rules and records the
+// attendance, with the XP credited, in history.
+func credit(ctx context.Context, tx *sqlx.Tx, rules *xp.Engine, a *models.Attendance) error {
+	if a.XPEarned > 0 {
+		award, err := rules.Award(ctx, tx, a.UserID, float64(a.XPEarned), xp.SourceEvent, a.EventID)
+		if err != nil {
+			return err
+		}
+		a.XPEarned = award.Amount
+	}
+	_, err := tx.ExecContext(ctx, `INSERT INTO history (id, user_id, event_id, start_date, end_date, xp_earned)
+			  VALUES ($1, $2, $3, $4, $5, $6)`,
+		uuid.NewString(), a.UserID, a.EventID, a.CheckedInAt, a.CheckedOutAt, a.XPEarned)
+	return err
+}
+
+// Advance moves published events to ongoing once they start and completes
+// ongoing ones once they end. It returns the users whose XP changed. It is
+// safe to run from several instances at once.
+func (s *Scheduler) Advance(ctx context.Context) ([]int64, error) {
+	_, err := s.db.ExecContext(ctx, `UPDATE events SET status = 'ongoing', updated_at = CURRENT_TIMESTAMP
+				WHERE status = 'published' AND start_date <= CURRENT_TIMESTAMP AND end_date > CURRENT_TIMESTAMP`)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []string
+	err = s.db.SelectContext(ctx, &ids, `SELECT id FROM events
+				WHERE status IN ('published', 'ongoing') AND end_date <= CURRENT_TIMESTAMP ORDER BY end_date`)
+	if err != nil {
+		return nil, err
+	}
+
+	var settled []int64
+	for _, id := range ids {
+		users, err := s.complete(ctx, id)
+		if err != nil {
+			return settled, err
+		}
+		settled = append(settled, users...)
+	}
+	return settled, nil
+}
+
+// complete marks an event completed and settles it: participants who are
+// still checked in are checked out at the end of the event and credited
+// their XP. Those who checked out earlier were credited then. The status
+// change and the settlement commit together, so an event is settled once.
+func (s *Scheduler) complete(ctx context.Context, id string) ([]int64, error) {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var locked []string
+	err = tx.SelectContext(ctx, &locked, `SELECT id FROM events
+				WHERE id = $1 AND status IN ('published', 'ongoing') FOR UPDATE SKIP LOCKED`, id)
+	if err != nil || len(locked) == 0 {
+		return nil, err
+	}
+
+	var attendances []models.Attendance
+	err = tx.SelectContext(ctx, &attendances, `UPDATE event_registrations r
+				SET checked_out_at = GREATEST(e.end_date, r.checked_in_at), updated_at = CURRENT_TIMESTAMP
+				FROM events e
+				WHERE e.id = r.event_id AND r.event_id = $1 AND r.status = 'registered'
+					AND r.checked_in_at IS NOT NULL AND r.checked_out_at IS NULL `+checkOutReturning, id)
+	if err != nil {
+		return nil, err
+	}
+	users := make([]int64, 0, len(attendances))
+	for i := range attendances {
+		a := &attendances[i]
+		if err := credit(ctx, tx, s.rules, a); err != nil {
+			return nil, err
+		}
+		users = append(users, a.UserID)
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE events SET status = 'completed', settled_at = CURRENT_TIMESTAMP,
+				updated_at = CURRENT_TIMESTAMP WHERE id = $1`, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	if len(users) > 0 {
+		log.Printf("Settled XP of %d participants of event %s", len(users), id)
+	}
+	return users, nil
+}
@@ -0,0 +1,97 @@
+// Package events finds events by location for the web app and the bot and
+// expands recurring event series into events.
+package events
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"worker-bot/geo"
+	"worker-bot/models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	StatusUpcoming = "upcoming"
+	StatusOngoing  = "ongoing"
+	StatusPast     = "past"
+)
+
+// statusFilters matches the status filter of the events list. The empty
+// status means events that have not ended.
+var statusFilters = map[string]string{
+	"":             "end_date >= CURRENT_TIMESTAMP",
+	StatusUpcoming: "start_date > CURRENT_TIMESTAMP",
+	StatusOngoing:  "start_date <= CURRENT_TIMESTAMP AND end_date >= CURRENT_TIMESTAMP",
+	StatusPast:     "end_date < CURRENT_TIMESTAMP",
+}
+
+// StatusFilter returns the SQL condition on start_date and end_date that
+// selects events of status.
+func StatusFilter(status string) (string, bool) {
+	filter, ok := statusFilters[status]
+	return filter, ok
+}
+
+type Finder struct {
+	db *sqlx.DB
+
+	postgisOnce sync.Once
+	postgis     bool
+}
+
+func NewFinder(db *sqlx.DB) *Finder {
+	return &Finder{db: db}
+}
+
+// usePostGIS detects PostGIS on first use.
+func (f *Finder) usePostGIS(ctx context.Context) bool {
+	f.postgisOnce.Do(func() {
+		ok, err := geo.HasPostGIS(ctx, f.db)
+		if err != nil {
+			log.Printf("Error detecting PostGIS, using haversine distances: %v", err)
+		}
+		f.postgis = ok
+	})
+	return f.postgis
+}
+
+// Nearby returns up to limit events with coordinates within radius meters of
+// (lat, lng), nearest first.
+func (f *Finder) Nearby(ctx context.Context, lat, lng, radius float64, status string, limit int) ([]models.NearbyEvent, error) {
+	filter, ok := statusFilters[status]
+	if !ok {
+		return nil, fmt.Errorf("unknown status %q", status)
+	}
+
+	postgis := f.usePostGIS(ctx)
+	distance, args := geo.DistanceSQL(postgis, "latitude", "longitude", lat, lng)
+	within, withinArgs := geo.WithinSQL(postgis, "latitude", "longitude", lat, lng, radius)
+	args = append(args, withinArgs...)
+	args = append(args, limit)
+
+	query := f.db.Rebind(`SELECT ` + eventColumns + `, ` + distance + ` AS distance_m
+			  FROM events
+			  WHERE status NOT IN ('draft', 'cancelled') AND latitude IS NOT NULL AND longitude IS NOT NULL AND ` + within + ` AND ` + filter + `
+			  ORDER BY distance_m, start_date LIMIT ?`)
+
+	nearby := []models.NearbyEvent{}
+	if err := f.db.SelectContext(ctx, &nearby, query, args...); err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, len(nearby))
+	for i, e := range nearby {
+		ids[i] = e.ID
+	}
+	officers, err := Officers(ctx, f.db, ids)
+	if err != nil {
+		return nil, err
+	}
+	for i := range nearby {
+		nearby[i].Officers = officers[nearby[i].ID]
+	}
+	return nearby, nil
+}
@@ -0,0 +1,161 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"worker-bot/models"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// NotOfficerError is returned when officers are assigned who are not users
+// with the officer or admin role.
+type NotOfficerError struct {
+	UserID int64
+}
+
+func (e *NotOfficerError) Error() string {
+	return fmt.Sprintf("user %d is not an officer", e.UserID)
+}
+
+const officerColumns = `o.user_id, o.role, u.first_name, u.last_name,
+				COALESCE(u.avatar, '') AS avatar, COALESCE(u.phone_number, '') AS phone_number`
+
+// checkOfficers validates officers and checks that they are all officers or
+// admins.
+func checkOfficers(ctx context.Context, q sqlx.QueryerContext, officers []models.EventOfficer) error {
+	if err := models.ValidateOfficers(officers); err != nil {
+		return err
+	}
+	ids := make([]int64, len(officers))
+	for i, o := range officers {
+		ids[i] = o.UserID
+	}
+	var found []int64
+	err := sqlx.SelectContext(ctx, q, &found, `SELECT id FROM users WHERE id = ANY($1) AND role IN ('officer', 'admin')`,
+		pq.Array(ids))
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		ok := false
+		for _, f := range found {
+			ok = ok || f == id
+		}
+		if !ok {
+			return &NotOfficerError{UserID: id}
+		}
+	}
+	return nil
+}
+
+// SetOfficers replaces the officers of an event, resolving the officer name
+// it had before officers were accounts.
+func SetOfficers(ctx context.Context, tx *sqlx.Tx, eventID string, officers []models.EventOfficer) error {
+	if err := checkOfficers(ctx, tx, officers); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM event_officers WHERE event_id = $1`, eventID); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM unassigned_officers WHERE event_id = $1`, eventID); err != nil {
+		return err
+	}
+	for _, o := range officers {
+		_, err := tx.ExecContext(ctx, `INSERT INTO event_officers (event_id, user_id, role) VALUES ($1, $2, $3)`,
+			eventID, o.UserID, o.Role)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Officers returns the officers of the events with the given IDs by event ID,
+// lead first.
+func Officers(ctx context.Context, q sqlx.QueryerContext, eventIDs []string) (map[string][]models.EventOfficer, error) {
+	var officers []models.EventOfficer
+	err := sqlx.SelectContext(ctx, q, &officers, `SELECT o.event_id, `+officerColumns+`
+				FROM event_officers o JOIN users u ON u.id = o.user_id
+				WHERE o.event_id = ANY($1) ORDER BY o.role = 'lead' DESC, u.first_name, u.last_name`,
+		pq.Array(eventIDs))
+	if err != nil {
+		return nil, err
+	}
+	byEvent := make(map[string][]models.EventOfficer, len(eventIDs))
+	for _, id := range eventIDs {
+		byEvent[id] = []models.EventOfficer{}
+	}
+	for _, o := range officers {
+		byEvent[o.EventID] = append(byEvent[o.EventID], o)
+	}
+	return byEvent, nil
+}
+
+// AttachOfficers fills in the officers of events.
+func AttachOfficers(ctx context.Context, q sqlx.QueryerContext, events []models.Event) error {
+	ids := make([]string, len(events))
+	for i, e := range events {
+		ids[i] = e.ID
+	}
+	officers, err := Officers(ctx, q, ids)
+	if err != nil {
+		return err
+	}
+	for i := range events {
+		events[i].Officers = officers[events[i].ID]
+	}
+	return nil
+}
+
+// setSeriesOfficers replaces the officers of a series. Its occurrences get
+// them through assignSeriesOfficers.
+func setSeriesOfficers(ctx context.Context, tx *sqlx.Tx, seriesID string, officers []models.EventOfficer) error {
+	if err := checkOfficers(ctx, tx, officers); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM series_officers WHERE series_id = $1`, seriesID); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM unassigned_officers WHERE series_id = $1`, seriesID); err != nil {
+		return err
+	}
+	for _, o := range officers {
+		_, err := tx.ExecContext(ctx, `INSERT INTO series_officers (series_id, user_id, role) VALUES ($1, $2, $3)`,
+			seriesID, o.UserID, o.Role)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// assignSeriesOfficers assigns the officers of a series to its occurrences
+// that have none.
+func assignSeriesOfficers(ctx context.Context, ex sqlx.ExecerContext, seriesID string) error {
+	_, err := ex.ExecContext(ctx, `INSERT INTO event_officers (event_id, user_id, role)
+				SELECT e.id, so.user_id, so.role FROM events e JOIN series_officers so ON so.series_id = e.series_id
+				WHERE e.series_id = $1 AND NOT EXISTS (SELECT 1 FROM event_officers eo WHERE eo.event_id = e.id)
+				ON CONFLICT DO NOTHING`, seriesID)
+	if err != nil {
+		return err
+	}
+	_, err = ex.ExecContext(ctx, `DELETE FROM unassigned_officers n USING events e
+				WHERE n.event_id = e.id AND e.series_id = $1
+					AND EXISTS (SELECT 1 FROM event_officers eo WHERE eo.event_id = e.id)`, seriesID)
+	return err
+}
+
+// UnassignedOfficers returns the officer names of events and series that
+// have no officers assigned yet, for an admin to resolve.
+func UnassignedOfficers(ctx context.Context, q sqlx.QueryerContext) ([]models.UnassignedOfficer, error) {
+	officers := []models.UnassignedOfficer{}
+	err := sqlx.SelectContext(ctx, q, &officers, `SELECT n.event_id, n.series_id, COALESCE(e.name, s.name) AS title,
+					n.name, COALESCE(n.image, '') AS image
+				FROM unassigned_officers n
+				LEFT JOIN events e ON e.id = n.event_id
+				LEFT JOIN event_series s ON s.id = n.series_id
+				ORDER BY n.name, title`)
+	return officers, err
+}
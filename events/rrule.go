@@ -0,0 +1,351 @@
+package events
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxPeriods bounds the expansion of rules that rarely or never match, e.g.
+// FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=30.
+const maxPeriods = 10000
+
+// Rule is a parsed iCalendar (RFC 5545) recurrence rule. FREQ, INTERVAL,
+// COUNT, UNTIL, BYDAY, BYMONTHDAY, BYMONTH and WKST are supported.
+type Rule struct {
+	Freq       string
+	Interval   int
+	Count      int
+	Until      time.Time
+	ByDay      []WeekdayNum
+	ByMonthDay []int
+	ByMonth    []time.Month
+	WeekStart  time.Weekday
+}
+
+// WeekdayNum is a BYDAY entry such as SA, 1SA or -1SU. N is 0 for every such
+// weekday of the period.
+type WeekdayNum struct {
+	N       int
+	Weekday time.Weekday
+}
+
+var weekdays = map[string]time.Weekday{
+	"SU": time.Sunday, "MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday,
+	"TH": time.Thursday, "FR": time.Friday, "SA": time.Saturday,
+}
+
+// ParseRule parses an RRULE value, with or without the "RRULE:" prefix. UNTIL
+// given without a zone is read in loc.
+func ParseRule(s string, loc *time.Location) (*Rule, error) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "RRULE:")
+	r := &Rule{Interval: 1, WeekStart: time.Monday}
+	for _, part := range strings.Split(s, ";") {
+		if part == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(part, "=")
+		if !ok || value == "" {
+			return nil, fmt.Errorf("invalid rule part %q", part)
+		}
+		var err error
+		switch strings.ToUpper(name) {
+		case "FREQ":
+			r.Freq = strings.ToUpper(value)
+		case "INTERVAL":
+			r.Interval, err = strconv.Atoi(value)
+			if err == nil && r.Interval < 1 {
+				err = fmt.Errorf("INTERVAL must be positive")
+			}
+		case "COUNT":
+			r.Count, err = strconv.Atoi(value)
+			if err == nil && r.Count < 1 {
+				err = fmt.Errorf("COUNT must be positive")
+			}
+		case "UNTIL":
+			r.Until, err = parseUntil(value, loc)
+		case "BYDAY":
+			for _, day := range strings.Split(value, ",") {
+				var wd WeekdayNum
+				if wd, err = parseWeekdayNum(day); err != nil {
+					break
+				}
+				r.ByDay = append(r.ByDay, wd)
+			}
+		case "BYMONTHDAY":
+			for _, day := range strings.Split(value, ",") {
+				var n int
+				if n, err = strconv.Atoi(day); err != nil || n == 0 || n < -31 || n > 31 {
+					err = fmt.Errorf("invalid BYMONTHDAY %q", day)
+					break
+				}
+				r.ByMonthDay = append(r.ByMonthDay, n)
+			}
+		case "BYMONTH":
+			for _, month := range strings.Split(value, ",") {
+				var n int
+				if n, err = strconv.Atoi(month); err != nil || n < 1 || n > 12 {
+					err = fmt.Errorf("invalid BYMONTH %q", month)
+					break
+				}
+				r.ByMonth = append(r.ByMonth, time.Month(n))
+			}
+		case "WKST":
+			var ok bool
+			if r.WeekStart, ok = weekdays[strings.ToUpper(value)]; !ok {
+				err = fmt.Errorf("invalid WKST %q", value)
+			}
+		default:
+			err = fmt.Errorf("%s is not supported", name)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	switch r.Freq {
+	case "DAILY", "WEEKLY", "MONTHLY", "YEARLY":
+	case "":
+		return nil, fmt.Errorf("FREQ is required")
+	default:
+		return nil, fmt.Errorf("FREQ=%s is not supported", r.Freq)
+	}
+	if r.Count > 0 && !r.Until.IsZero() {
+		return nil, fmt.Errorf("COUNT and UNTIL cannot be used together")
+	}
+	for _, wd := range r.ByDay {
+		if wd.N != 0 && r.Freq != "MONTHLY" && r.Freq != "YEARLY" {
+			return nil, fmt.Errorf("numbered BYDAY is only allowed with FREQ=MONTHLY or YEARLY")
+		}
+	}
+	return r, nil
+}
+
+func parseUntil(value string, loc *time.Location) (time.Time, error) {
+	for _, layout := range []string{"20060102T150405Z", "20060102T150405", "20060102"} {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			if strings.HasSuffix(layout, "Z") {
+				t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, time.UTC)
+			} else if layout == "20060102" {
+				t = t.Add(24*time.Hour - time.Second)
+			}
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid UNTIL %q", value)
+}
+
+func parseWeekdayNum(s string) (WeekdayNum, error) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	if len(s) < 2 {
+		return WeekdayNum{}, fmt.Errorf("invalid BYDAY %q", s)
+	}
+	wd, ok := weekdays[s[len(s)-2:]]
+	if !ok {
+		return WeekdayNum{}, fmt.Errorf("invalid BYDAY %q", s)
+	}
+	n := 0
+	if prefix := s[:len(s)-2]; prefix != "" {
+		var err error
+		if n, err = strconv.Atoi(prefix); err != nil || n == 0 || n < -53 || n > 53 {
+			return WeekdayNum{}, fmt.Errorf("invalid BYDAY %q", s)
+		}
+	}
+	return WeekdayNum{N: n, Weekday: wd}, nil
+}
+
+// Between returns the starts of the occurrences of a series starting at
+// dtstart that fall within [from, to), at most limit of them. dtstart is the
+// first occurrence and its wall clock time, in its location, is kept by
+// every occurrence across daylight saving changes. COUNT counts occurrences
+// from dtstart, including those before from.
+func (r *Rule) Between(dtstart, from, to time.Time, limit int) []time.Time {
+	var out []time.Time
+	count := 0
+	for period := 0; period < maxPeriods; period++ {
+		candidates := r.period(dtstart, period)
+		for _, t := range candidates {
+			if t.Before(dtstart) {
+				continue
+			}
+			if (!r.Until.IsZero() && t.After(r.Until)) || !t.Before(to) {
+				return out
+			}
+			count++
+			if r.Count > 0 && count > r.Count {
+				return out
+			}
+			if !t.Before(from) {
+				out = append(out, t)
+				if len(out) >= limit {
+					return out
+				}
+			}
+		}
+	}
+	return out
+}
+
+// period returns the sorted candidates of the n-th period after the one
+// containing dtstart.
+func (r *Rule) period(dtstart time.Time, n int) []time.Time {
+	loc := dtstart.Location()
+	hour, min, sec := dtstart.Clock()
+	at := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, hour, min, sec, 0, loc)
+	}
+	step := n * r.Interval
+
+	var days []time.Time
+	switch r.Freq {
+	case "DAILY":
+		d := dtstart.AddDate(0, 0, step)
+		if r.matchDay(d) {
+			days = append(days, at(d.Date()))
+		}
+	case "WEEKLY":
+		offset := (int(dtstart.Weekday()) - int(r.WeekStart) + 7) % 7
+		start := dtstart.AddDate(0, 0, step*7-offset)
+		for i := 0; i < 7; i++ {
+			d := start.AddDate(0, 0, i)
+			if r.weeklyDay(d, dtstart) {
+				days = append(days, at(d.Date()))
+			}
+		}
+	case "MONTHLY":
+		first := time.Date(dtstart.Year(), dtstart.Month()+time.Month(step), 1, 0, 0, 0, 0, loc)
+		if r.matchMonth(first.Month()) {
+			days = r.monthDays(first, dtstart, at)
+		}
+	case "YEARLY":
+		year := dtstart.Year() + step
+		switch {
+		case len(r.ByMonth) > 0:
+			for _, m := range r.ByMonth {
+				days = append(days, r.monthDays(time.Date(year, m, 1, 0, 0, 0, 0, loc), dtstart, at)...)
+			}
+		case len(r.ByDay) > 0 || len(r.ByMonthDay) > 0:
+			if len(r.ByMonthDay) > 0 {
+				for m := time.January; m <= time.December; m++ {
+					days = append(days, r.monthDays(time.Date(year, m, 1, 0, 0, 0, 0, loc), dtstart, at)...)
+				}
+			} else {
+				days = r.nthWeekdays(time.Date(year, 1, 1, 0, 0, 0, 0, loc), time.Date(year+1, 1, 1, 0, 0, 0, 0, loc), at)
+			}
+		default:
+			if d := at(year, dtstart.Month(), dtstart.Day()); d.Day() == dtstart.Day() {
+				days = append(days, d)
+			}
+		}
+	}
+	sort.Slice(days, func(i, j int) bool { return days[i].Before(days[j]) })
+	return days
+}
+
+// monthDays returns the days of the month starting at first that match
+// BYMONTHDAY and BYDAY, or dtstart's day of month without either.
+func (r *Rule) monthDays(first, dtstart time.Time, at func(int, time.Month, int) time.Time) []time.Time {
+	next := first.AddDate(0, 1, 0)
+	last := next.AddDate(0, 0, -1).Day()
+
+	if len(r.ByMonthDay) == 0 && len(r.ByDay) == 0 {
+		if dtstart.Day() > last {
+			return nil
+		}
+		return []time.Time{at(first.Year(), first.Month(), dtstart.Day())}
+	}
+	if len(r.ByMonthDay) == 0 {
+		return r.nthWeekdays(first, next, at)
+	}
+
+	var days []time.Time
+	for _, n := range r.ByMonthDay {
+		day := n
+		if n < 0 {
+			day = last + n + 1
+		}
+		if day < 1 || day > last {
+			continue
+		}
+		d := at(first.Year(), first.Month(), day)
+		if len(r.ByDay) == 0 || r.hasWeekday(d.Weekday()) {
+			days = append(days, d)
+		}
+	}
+	return days
+}
+
+// nthWeekdays returns the days in [start, end) matching BYDAY, where a
+// numbered entry such as -1SU picks one of the weekdays in that range.
+func (r *Rule) nthWeekdays(start, end time.Time, at func(int, time.Month, int) time.Time) []time.Time {
+	var days []time.Time
+	for _, wd := range r.ByDay {
+		var matching []time.Time
+		for d := start; d.Before(end); d = d.AddDate(0, 0, 1) {
+			if d.Weekday() == wd.Weekday {
+				matching = append(matching, at(d.Date()))
+			}
+		}
+		switch {
+		case wd.N == 0:
+			days = append(days, matching...)
+		case wd.N > 0 && wd.N <= len(matching):
+			days = append(days, matching[wd.N-1])
+		case wd.N < 0 && -wd.N <= len(matching):
+			days = append(days, matching[len(matching)+wd.N])
+		}
+	}
+	return days
+}
+
+func (r *Rule) matchDay(d time.Time) bool {
+	if !r.matchMonth(d.Month()) {
+		return false
+	}
+	if len(r.ByDay) > 0 && !r.hasWeekday(d.Weekday()) {
+		return false
+	}
+	if len(r.ByMonthDay) > 0 {
+		last := time.Date(d.Year(), d.Month()+1, 0, 0, 0, 0, 0, d.Location()).Day()
+		for _, n := range r.ByMonthDay {
+			if n == d.Day() || last+n+1 == d.Day() {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+func (r *Rule) weeklyDay(d, dtstart time.Time) bool {
+	if !r.matchMonth(d.Month()) {
+		return false
+	}
+	if len(r.ByDay) == 0 {
+		return d.Weekday() == dtstart.Weekday()
+	}
+	return r.hasWeekday(d.Weekday())
+}
+
+func (r *Rule) matchMonth(m time.Month) bool {
+	if len(r.ByMonth) == 0 {
+		return true
+	}
+	for _, month := range r.ByMonth {
+		if month == m {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Rule) hasWeekday(wd time.Weekday) bool {
+	for _, day := range r.ByDay {
+		if day.Weekday == wd {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,266 @@
+package events
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"worker-bot/models"
+)
+
+const testLayout = "2006-01-02 15:04"
+
+func mustZone(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return loc
+}
+
+func mustTime(t *testing.T, s string, loc *time.Location) time.Time {
+	t.Helper()
+	tm, err := time.ParseInLocation(testLayout, s, loc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tm
+}
+
+func formatAll(times []time.Time) []string {
+	out := make([]string, len(times))
+	for i, t := range times {
+		out[i] = t.Format(time.RFC3339)
+	}
+	return out
+}
+
+func TestBetween(t *testing.T) {
+	tests := []struct {
+		name  string
+		rule  string
+		zone  string
+		start string
+		// from defaults to start.
+		from  string
+		limit int
+		want  []string
+	}{
+		{
+			name:  "last sunday of the month",
+			rule:  "FREQ=MONTHLY;BYDAY=-1SU",
+			start: "2026-01-25 10:00",
+			limit: 4,
+			want: []string{
+				"2026-01-25T10:00:00+05:00", "2026-02-22T10:00:00+05:00",
+				"2026-03-29T10:00:00+05:00", "2026-04-26T10:00:00+05:00",
+			},
+		},
+		{
+			name:  "first and third saturday",
+			rule:  "FREQ=MONTHLY;BYDAY=1SA,3SA",
+			start: "2026-01-03 10:00",
+			limit: 4,
+			want: []string{
+				"2026-01-03T10:00:00+05:00", "2026-01-17T10:00:00+05:00",
+				"2026-02-07T10:00:00+05:00", "2026-02-21T10:00:00+05:00",
+			},
+		},
+		{
+			name:  "31st skips shorter months",
+			rule:  "FREQ=MONTHLY;BYMONTHDAY=31",
+			start: "2026-01-31 10:00",
+			limit: 4,
+			want: []string{
+				"2026-01-31T10:00:00+05:00", "2026-03-31T10:00:00+05:00",
+				"2026-05-31T10:00:00+05:00", "2026-07-31T10:00:00+05:00",
+			},
+		},
+		{
+			name:  "last day of the month",
+			rule:  "FREQ=MONTHLY;BYMONTHDAY=-1",
+			start: "2026-01-31 10:00",
+			limit: 4,
+			want: []string{
+				"2026-01-31T10:00:00+05:00", "2026-02-28T10:00:00+05:00",
+				"2026-03-31T10:00:00+05:00", "2026-04-30T10:00:00+05:00",
+			},
+		},
+		{
+			name:  "last day of february across a leap year",
+			rule:  "FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=-1",
+			start: "2027-02-28 10:00",
+			limit: 3,
+			want: []string{
+				"2027-02-28T10:00:00+05:00", "2028-02-29T10:00:00+05:00", "2029-02-28T10:00:00+05:00",
+			},
+		},
+		{
+			name:  "daily interval",
+			rule:  "FREQ=DAILY;INTERVAL=3",
+			start: "2026-01-30 10:00",
+			limit: 3,
+			want: []string{
+				"2026-01-30T10:00:00+05:00", "2026-02-02T10:00:00+05:00", "2026-02-05T10:00:00+05:00",
+			},
+		},
+		{
+			name:  "every other week on two days",
+			rule:  "FREQ=WEEKLY;INTERVAL=2;BYDAY=TU,TH",
+			start: "2026-01-06 18:00",
+			limit: 6,
+			want: []string{
+				"2026-01-06T18:00:00+05:00", "2026-01-08T18:00:00+05:00",
+				"2026-01-20T18:00:00+05:00", "2026-01-22T18:00:00+05:00",
+				"2026-02-03T18:00:00+05:00", "2026-02-05T18:00:00+05:00",
+			},
+		},
+		{
+			name:  "count",
+			rule:  "FREQ=DAILY;COUNT=3",
+			start: "2026-03-01 10:00",
+			limit: 10,
+			want: []string{
+				"2026-03-01T10:00:00+05:00", "2026-03-02T10:00:00+05:00", "2026-03-03T10:00:00+05:00",
+			},
+		},
+		{
+			name:  "count includes occurrences before from",
+			rule:  "FREQ=DAILY;COUNT=3",
+			start: "2026-03-01 10:00",
+			from:  "2026-03-02 00:00",
+			limit: 10,
+			want:  []string{"2026-03-02T10:00:00+05:00", "2026-03-03T10:00:00+05:00"},
+		},
+		{
+			name:  "until date includes that day",
+			rule:  "FREQ=WEEKLY;UNTIL=20260315",
+			start: "2026-03-01 10:00",
+			limit: 10,
+			want: []string{
+				"2026-03-01T10:00:00+05:00", "2026-03-08T10:00:00+05:00", "2026-03-15T10:00:00+05:00",
+			},
+		},
+		{
+			name:  "utc until is inclusive",
+			rule:  "FREQ=WEEKLY;UNTIL=20260308T050000Z",
+			start: "2026-03-01 10:00",
+			limit: 10,
+			want:  []string{"2026-03-01T10:00:00+05:00", "2026-03-08T10:00:00+05:00"},
+		},
+		{
+			name:  "utc until before the occurrence",
+			rule:  "FREQ=WEEKLY;UNTIL=20260308T045959Z",
+			start: "2026-03-01 10:00",
+			limit: 10,
+			want:  []string{"2026-03-01T10:00:00+05:00"},
+		},
+		{
+			name:  "spring forward keeps the wall clock time",
+			rule:  "FREQ=DAILY",
+			zone:  "America/New_York",
+			start: "2026-03-07 09:00",
+			limit: 3,
+			want: []string{
+				"2026-03-07T09:00:00-05:00", "2026-03-08T09:00:00-04:00", "2026-03-09T09:00:00-04:00",
+			},
+		},
+		{
+			name:  "fall back keeps the wall clock time",
+			rule:  "FREQ=WEEKLY;BYDAY=SU",
+			zone:  "Europe/Berlin",
+			start: "2026-10-18 09:00",
+			limit: 3,
+			want: []string{
+				"2026-10-18T09:00:00+02:00", "2026-10-25T09:00:00+01:00", "2026-11-01T09:00:00+01:00",
+			},
+		},
+		{
+			name:  "last sunday across a daylight saving change",
+			rule:  "FREQ=MONTHLY;BYDAY=-1SU",
+			zone:  "Europe/Berlin",
+			start: "2026-02-22 12:00",
+			limit: 3,
+			want: []string{
+				"2026-02-22T12:00:00+01:00", "2026-03-29T12:00:00+02:00", "2026-04-26T12:00:00+02:00",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			zone := tt.zone
+			if zone == "" {
+				zone = models.DefaultTimezone
+			}
+			loc := mustZone(t, zone)
+			rule, err := ParseRule(tt.rule, loc)
+			if err != nil {
+				t.Fatalf("ParseRule(%q): %v", tt.rule, err)
+			}
+			start := mustTime(t, tt.start, loc)
+			from := start
+			if tt.from != "" {
+				from = mustTime(t, tt.from, loc)
+			}
+			to := start.AddDate(5, 0, 0)
+
+			got := formatAll(rule.Between(start, from, to, tt.limit))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Between = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRuleErrors(t *testing.T) {
+	for _, rule := range []string{
+		"",
+		"FREQ=HOURLY",
+		"FREQ=DAILY;INTERVAL=0",
+		"FREQ=DAILY;COUNT=3;UNTIL=20260301",
+		"FREQ=WEEKLY;BYDAY=-1SU",
+		"FREQ=MONTHLY;BYMONTHDAY=32",
+		"FREQ=MONTHLY;BYDAY=XX",
+		"FREQ=DAILY;BYSETPOS=1",
+	} {
+		if _, err := ParseRule(rule, time.UTC); err == nil {
+			t.Errorf("ParseRule(%q) succeeded, want an error", rule)
+		}
+	}
+}
+
+func TestOccurrencesSkipExdates(t *testing.T) {
+	loc := mustZone(t, models.DefaultTimezone)
+	rule, err := ParseRule("FREQ=WEEKLY;BYDAY=SA", loc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := mustTime(t, "2026-01-03 10:00", loc)
+	sc := &schedule{
+		EventSeries: &models.EventSeries{
+			Start: start,
+			// The same instant as the second occurrence, given in UTC.
+			Exdates: []time.Time{mustTime(t, "2026-01-10 05:00", time.UTC)},
+		},
+		rule: rule,
+		loc:  loc,
+	}
+
+	got := formatAll(sc.occurrences(start, mustTime(t, "2026-01-25 00:00", loc)))
+	want := []string{"2026-01-03T10:00:00+05:00", "2026-01-17T10:00:00+05:00", "2026-01-24T10:00:00+05:00"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("occurrences = %v, want %v", got, want)
+	}
+}
+
+func TestEventStoredInUTC(t *testing.T) {
+	loc := mustZone(t, "Europe/Berlin")
+	sc := &schedule{EventSeries: &models.EventSeries{DurationMinutes: 90}, loc: loc}
+
+	event := sc.event("", mustTime(t, "2026-07-04 10:00", loc))
+	if event.StartDate != "2026-07-04 08:00:00" || event.EndDate != "2026-07-04 09:30:00" {
+		t.Errorf("event runs %s to %s, want 2026-07-04 08:00:00 to 09:30:00 UTC", event.StartDate, event.EndDate)
+	}
+}
@@ -0,0 +1,540 @@
+package events
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+	_ "time/tzdata"
+	"worker-bot/models"
+	"worker-bot/xp"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+const (
+	// Horizon is how far ahead series are expanded into events.
+	Horizon = 90 * 24 * time.Hour
+	// maxOccurrences bounds the events a series expands into at once.
+	maxOccurrences = 500
+	// wallClock formats times for TIMESTAMP columns, which hold times
+	// without a zone. The start and end of occurrences are stored in UTC,
+	// the zone of database sessions, so they compare with CURRENT_TIMESTAMP.
+	// The times defining a series, and occurrence_start, are wall clock times
+	// in the series' time zone.
+	wallClock = "2006-01-02 15:04:05"
+)
+
+var (
+	ErrSeriesNotFound    = errors.New("series not found")
+	ErrNotOccurrence     = errors.New("event is not an occurrence of the series")
+	ErrOccurrenceStarted = errors.New("occurrence has already started")
+)
+
+// eventColumns selects an event into models.Event.
+const eventColumns = `id, COALESCE(image, '') AS image, name, COALESCE(description, '') AS description,
+				total_xp, start_date, end_date, created_at, updated_at, COALESCE(location, '') AS location,
+				capacity, registration_cutoff_minutes, prorate_xp,
+				latitude, longitude, checkin_radius_m, COALESCE(address, '') AS address, area, region, series_id, status`
+
+const seriesColumns = `id, name, COALESCE(image, '') AS image, COALESCE(description, '') AS description, total_xp,
+				dtstart, duration_minutes, timezone, rrule, exdates, until,
+				capacity, registration_cutoff_minutes, prorate_xp, latitude, longitude, checkin_radius_m,
+				COALESCE(location, '') AS location, COALESCE(address, '') AS address, area, region,
+				created_at, updated_at`
+
+const insertSeries = `INSERT INTO event_series (id, name, image, description, total_xp,
+				dtstart, duration_minutes, timezone, rrule, exdates, until,
+				capacity, registration_cutoff_minutes, prorate_xp, latitude, longitude, checkin_radius_m,
+				location, address, area, region)
+			  VALUES (:id, :name, :image, :description, :total_xp,
+				:dtstart, :duration_minutes, :timezone, :rrule, :exdates, :until,
+				:capacity, :registration_cutoff_minutes, :prorate_xp, :latitude, :longitude, :checkin_radius_m,
+				:location, :address, :area, :region)`
+
+const updateSeries = `UPDATE event_series SET name = :name, image = :image, description = :description,
+				total_xp = :total_xp, dtstart = :dtstart, duration_minutes = :duration_minutes, timezone = :timezone, rrule = :rrule,
+				exdates = :exdates, capacity = :capacity, registration_cutoff_minutes = :registration_cutoff_minutes,
+				prorate_xp = :prorate_xp, latitude = :latitude, longitude = :longitude,
+				checkin_radius_m = :checkin_radius_m, location = :location, address = :address, area = :area,
+				region = :region, updated_at = CURRENT_TIMESTAMP
+			  WHERE id = :id`
+
+const insertOccurrence = `INSERT INTO events (id, image, name, description, total_xp, start_date, end_date,
+				capacity, registration_cutoff_minutes, prorate_xp,
+				latitude, longitude, checkin_radius_m, location, address, area, region, series_id, occurrence_start)
+			  VALUES (:id, :image, :name, :description, :total_xp, :start_date, :end_date,
+				:capacity, :registration_cutoff_minutes, :prorate_xp,
+				:latitude, :longitude, :checkin_radius_m, :location, :address, :area, :region, :series_id, :occurrence_start)
+			  ON CONFLICT (series_id, occurrence_start) DO NOTHING`
+
+const updateOccurrence = `UPDATE events SET image = :image, name = :name, description = :description,
+				total_xp = :total_xp, start_date = :start_date, end_date = :end_date, capacity = :capacity,
+				registration_cutoff_minutes = :registration_cutoff_minutes, prorate_xp = :prorate_xp,
+				latitude = :latitude, longitude = :longitude, checkin_radius_m = :checkin_radius_m,
+				location = :location, address = :address, area = :area, region = :region,
+				series_id = :series_id, occurrence_start = :occurrence_start, updated_at = CURRENT_TIMESTAMP
+			  WHERE id = :id`
+
+// Scheduler expands recurring event series into events and moves events
+// through their lifecycle as time passes.
+type Scheduler struct {
+	db    *sqlx.DB
+	rules *xp.Engine
+}
+
+func NewScheduler(db *sqlx.DB, rules *xp.Engine) *Scheduler {
+	return &Scheduler{db: db, rules: rules}
+}
+
+// seriesRow reads a series with its exdates array.
+type seriesRow struct {
+	models.EventSeries
+	RawExdates pq.StringArray `db:"exdates"`
+}
+
+// occurrenceRow writes an occurrence of a series.
+type occurrenceRow struct {
+	models.Event
+	OccurrenceStart string `db:"occurrence_start"`
+}
+
+// schedule is a validated series ready to expand.
+type schedule struct {
+	*models.EventSeries
+	rule *Rule
+	loc  *time.Location
+}
+
+// Prepare validates a series, fills in its defaults and moves its times to
+// its time zone.
+func Prepare(s *models.EventSeries) error {
+	if err := models.ValidateOfficers(s.Officers); err != nil {
+		return err
+	}
+	_, err := compile(s)
+	return err
+}
+
+func compile(s *models.EventSeries) (*schedule, error) {
+	if s.Timezone == "" {
+		s.Timezone = models.DefaultTimezone
+	}
+	loc, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("unknown timezone %q", s.Timezone)
+	}
+	rule, err := ParseRule(s.RRule, loc)
+	if err != nil {
+		return nil, fmt.Errorf("rrule: %w", err)
+	}
+	if s.DurationMinutes < 1 {
+		return nil, errors.New("duration_minutes must be positive")
+	}
+
+	s.Start = s.Start.In(loc).Truncate(time.Second)
+	if s.Exdates == nil {
+		s.Exdates = []time.Time{}
+	}
+	for i := range s.Exdates {
+		s.Exdates[i] = s.Exdates[i].In(loc).Truncate(time.Second)
+	}
+	if s.Until != nil {
+		until := s.Until.In(loc)
+		s.Until = &until
+	}
+	if s.RegistrationCutoffMinutes == nil {
+		cutoff := models.DefaultRegistrationCutoff
+		s.RegistrationCutoffMinutes = &cutoff
+	}
+	if s.CheckinRadiusM == nil {
+		radius := models.DefaultCheckinRadius
+		s.CheckinRadiusM = &radius
+	}
+
+	sc := &schedule{EventSeries: s, rule: rule, loc: loc}
+	event := sc.event("", s.Start)
+	if err := event.ValidatePlace(); err != nil {
+		return nil, err
+	}
+	return sc, nil
+}
+
+// load turns a series read from the database into a schedule. TIMESTAMP
+// columns come back as UTC and are moved to the series' time zone.
+func load(row *seriesRow) (*schedule, error) {
+	loc, err := time.LoadLocation(row.Timezone)
+	if err != nil {
+		return nil, err
+	}
+	row.Start = inZone(row.Start, loc)
+	if row.Until != nil {
+		until := inZone(*row.Until, loc)
+		row.Until = &until
+	}
+	row.Exdates = make([]time.Time, len(row.RawExdates))
+	for i, raw := range row.RawExdates {
+		if row.Exdates[i], err = time.ParseInLocation(wallClock, raw, loc); err != nil {
+			return nil, err
+		}
+	}
+	return compile(&row.EventSeries)
+}
+
+func inZone(t time.Time, loc *time.Location) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), loc)
+}
+
+// occurrences returns the starts of the occurrences in [from, to) that were
+// not cancelled.
+func (sc *schedule) occurrences(from, to time.Time) []time.Time {
+	if sc.Until != nil && sc.Until.Before(to) {
+		to = *sc.Until
+	}
+	var starts []time.Time
+	for _, t := range sc.rule.Between(sc.Start, from, to, maxOccurrences+len(sc.Exdates)) {
+		if !sc.cancelled(t) && len(starts) < maxOccurrences {
+			starts = append(starts, t)
+		}
+	}
+	return starts
+}
+
+func (sc *schedule) cancelled(t time.Time) bool {
+	for _, ex := range sc.Exdates {
+		if ex.Equal(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// event returns the occurrence of the series starting at start.
+func (sc *schedule) event(id string, start time.Time) models.Event {
+	seriesID := sc.ID
+	return models.Event{
+		ID:                        id,
+		Name:                      sc.Name,
+		Image:                     sc.Image,
+		Description:               sc.Description,
+		TotalXP:                   sc.TotalXP,
+		StartDate:                 start.UTC().Format(wallClock),
+		EndDate:                   start.Add(time.Duration(sc.DurationMinutes) * time.Minute).UTC().Format(wallClock),
+		Location:                  sc.Location,
+		Capacity:                  sc.Capacity,
+		RegistrationCutoffMinutes: sc.RegistrationCutoffMinutes,
+		ProrateXP:                 sc.ProrateXP,
+		Latitude:                  sc.Latitude,
+		Longitude:                 sc.Longitude,
+		CheckinRadiusM:            sc.CheckinRadiusM,
+		Address:                   sc.Address,
+		Area:                      sc.Area,
+		Region:                    sc.Region,
+		SeriesID:                  &seriesID,
+	}
+}
+
+func (sc *schedule) params() map[string]interface{} {
+	exdates := make(pq.StringArray, len(sc.Exdates))
+	for i, t := range sc.Exdates {
+		exdates[i] = t.Format(wallClock)
+	}
+	var until *string
+	if sc.Until != nil {
+		u := sc.Until.Format(wallClock)
+		until = &u
+	}
+	return map[string]interface{}{
+		"id":                          sc.ID,
+		"name":                        sc.Name,
+		"image":                       sc.Image,
+		"description":                 sc.Description,
+		"total_xp":                    sc.TotalXP,
+		"dtstart":                     sc.Start.Format(wallClock),
+		"duration_minutes":            sc.DurationMinutes,
+		"timezone":                    sc.Timezone,
+		"rrule":                       sc.RRule,
+		"exdates":                     exdates,
+		"until":                       until,
+		"capacity":                    sc.Capacity,
+		"registration_cutoff_minutes": sc.RegistrationCutoffMinutes,
+		"prorate_xp":                  sc.ProrateXP,
+		"latitude":                    sc.Latitude,
+		"longitude":                   sc.Longitude,
+		"checkin_radius_m":            sc.CheckinRadiusM,
+		"location":                    sc.Location,
+		"address":                     sc.Address,
+		"area":                        sc.Area,
+		"region":                      sc.Region,
+	}
+}
+
+// insert adds the occurrences starting at starts that do not exist yet.
+func (sc *schedule) insert(ctx context.Context, ex sqlx.ExtContext, starts []time.Time) error {
+	for _, start := range starts {
+		row := occurrenceRow{Event: sc.event(uuid.NewString(), start), OccurrenceStart: start.Format(wallClock)}
+		if _, err := sqlx.NamedExecContext(ctx, ex, insertOccurrence, row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Create stores a series and expands it into events up to Horizon ahead.
+func (s *Scheduler) Create(ctx context.Context, series *models.EventSeries) error {
+	sc, err := compile(series)
+	if err != nil {
+		return err
+	}
+	series.ID = uuid.NewString()
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.NamedExecContext(ctx, insertSeries, sc.params()); err != nil {
+		return err
+	}
+	if err := setSeriesOfficers(ctx, tx, series.ID, series.Officers); err != nil {
+		return err
+	}
+	now := time.Now().In(sc.loc)
+	if err := sc.insert(ctx, tx, sc.occurrences(now, now.Add(Horizon))); err != nil {
+		return err
+	}
+	if err := assignSeriesOfficers(ctx, tx, series.ID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Series returns a series with its officers and its occurrences that have
+// not ended.
+func (s *Scheduler) Series(ctx context.Context, id string) (models.EventSeries, error) {
+	var row seriesRow
+	err := s.db.GetContext(ctx, &row, `SELECT `+seriesColumns+` FROM event_series WHERE id = $1`, id)
+	if err == sql.ErrNoRows {
+		return row.EventSeries, ErrSeriesNotFound
+	}
+	if err != nil {
+		return row.EventSeries, err
+	}
+	if _, err := load(&row); err != nil {
+		return row.EventSeries, err
+	}
+
+	row.Officers = []models.EventOfficer{}
+	err = s.db.SelectContext(ctx, &row.Officers, `SELECT `+officerColumns+`
+				FROM series_officers o JOIN users u ON u.id = o.user_id
+				WHERE o.series_id = $1 ORDER BY o.role = 'lead' DESC, u.first_name, u.last_name`, id)
+	if err != nil {
+		return row.EventSeries, err
+	}
+
+	row.Occurrences = []models.Event{}
+	err = s.db.SelectContext(ctx, &row.Occurrences, `SELECT `+eventColumns+` FROM events
+				WHERE series_id = $1 AND end_date >= CURRENT_TIMESTAMP ORDER BY start_date`, id)
+	if err != nil {
+		return row.EventSeries, err
+	}
+	return row.EventSeries, AttachOfficers(ctx, s.db, row.Occurrences)
+}
+
+// Extend expands every open-ended series up to Horizon ahead. Series ended by
+// an edit of all future occurrences were fully expanded when they were split.
+// It is safe to run from several instances at once.
+func (s *Scheduler) Extend(ctx context.Context) error {
+	var rows []seriesRow
+	err := s.db.SelectContext(ctx, &rows, `SELECT `+seriesColumns+` FROM event_series WHERE until IS NULL`)
+	if err != nil {
+		return err
+	}
+
+	for i := range rows {
+		sc, err := load(&rows[i])
+		if err != nil {
+			return fmt.Errorf("series %s: %w", rows[i].ID, err)
+		}
+		now := time.Now().In(sc.loc)
+		if err := sc.insert(ctx, s.db, sc.occurrences(now, now.Add(Horizon))); err != nil {
+			return fmt.Errorf("series %s: %w", rows[i].ID, err)
+		}
+		if err := assignSeriesOfficers(ctx, s.db, rows[i].ID); err != nil {
+			return fmt.Errorf("series %s: %w", rows[i].ID, err)
+		}
+	}
+	return nil
+}
+
+// UpdateFuture replaces the definition of a series from the occurrence
+// fromEventID onwards, or from now if it is empty. Earlier occurrences keep
+// the old definition: the series is split in two and the returned ID is the
+// one of the series holding the future occurrences.
+//
+// Upcoming occurrences are matched in order with the new schedule, so the
+// n-th upcoming occurrence keeps its ID, registrations and history when it
+// moves. Occurrences edited on their own are left alone. Occurrences the new
+// schedule drops are deleted, or kept as standalone events if anyone has
+// registered for them. It returns the IDs of the events it updated.
+func (s *Scheduler) UpdateFuture(ctx context.Context, id, fromEventID string, series *models.EventSeries) (string, []string, error) {
+	sc, err := compile(series)
+	if err != nil {
+		return "", nil, err
+	}
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return "", nil, err
+	}
+	defer tx.Rollback()
+
+	var current seriesRow
+	err = tx.GetContext(ctx, &current, `SELECT `+seriesColumns+` FROM event_series WHERE id = $1 FOR UPDATE`, id)
+	if err == sql.ErrNoRows {
+		return "", nil, ErrSeriesNotFound
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	old, err := load(&current)
+	if err != nil {
+		return "", nil, err
+	}
+
+	now := time.Now().In(sc.loc)
+	cut := now.In(old.loc)
+	if fromEventID != "" {
+		var from struct {
+			OccurrenceStart time.Time `db:"occurrence_start"`
+			Started         bool      `db:"started"`
+		}
+		err = tx.GetContext(ctx, &from, `SELECT occurrence_start, start_date <= CURRENT_TIMESTAMP AS started
+					FROM events WHERE id = $1 AND series_id = $2`, fromEventID, id)
+		if err == sql.ErrNoRows {
+			return "", nil, ErrNotOccurrence
+		}
+		if err != nil {
+			return "", nil, err
+		}
+		if from.Started {
+			return "", nil, ErrOccurrenceStarted
+		}
+		cut = inZone(from.OccurrenceStart, old.loc)
+	}
+	// Cancelled occurrences stay cancelled.
+	for _, ex := range old.Exdates {
+		if !ex.Before(cut) {
+			series.Exdates = append(series.Exdates, ex.In(sc.loc))
+		}
+	}
+
+	var earlier bool
+	err = tx.GetContext(ctx, &earlier, `SELECT EXISTS (SELECT 1 FROM events WHERE series_id = $1 AND occurrence_start < $2)`,
+		id, cut.Format(wallClock))
+	if err != nil {
+		return "", nil, err
+	}
+	if earlier {
+		_, err = tx.ExecContext(ctx, `UPDATE event_series SET until = $2, updated_at = CURRENT_TIMESTAMP WHERE id = $1`,
+			id, cut.Format(wallClock))
+		if err != nil {
+			return "", nil, err
+		}
+		series.ID = uuid.NewString()
+		series.Until = nil
+		_, err = tx.NamedExecContext(ctx, insertSeries, sc.params())
+	} else {
+		series.ID = id
+		series.Until = nil
+		_, err = tx.NamedExecContext(ctx, updateSeries, sc.params())
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	if err := setSeriesOfficers(ctx, tx, series.ID, series.Officers); err != nil {
+		return "", nil, err
+	}
+
+	var upcoming []struct {
+		ID              string    `db:"id"`
+		OccurrenceStart time.Time `db:"occurrence_start"`
+	}
+	err = tx.SelectContext(ctx, &upcoming, `SELECT id, occurrence_start FROM events
+				WHERE series_id = $1 AND occurrence_start >= $2 AND NOT detached AND start_date > CURRENT_TIMESTAMP
+				ORDER BY occurrence_start FOR UPDATE`, id, cut.Format(wallClock))
+	if err != nil {
+		return "", nil, err
+	}
+	ids := make([]string, len(upcoming))
+	for i, e := range upcoming {
+		ids[i] = e.ID
+	}
+	// Clear the scheduled starts first so moving occurrences past each
+	// other does not trip the unique index.
+	_, err = tx.ExecContext(ctx, `UPDATE events SET occurrence_start = NULL WHERE id = ANY($1)`, pq.Array(ids))
+	if err != nil {
+		return "", nil, err
+	}
+
+	// Occurrences that stay in the series are not rescheduled.
+	var taken []time.Time
+	err = tx.SelectContext(ctx, &taken, `SELECT occurrence_start FROM events
+				WHERE series_id = $1 AND occurrence_start IS NOT NULL`, series.ID)
+	if err != nil {
+		return "", nil, err
+	}
+	var starts []time.Time
+	for _, t := range sc.occurrences(now, now.Add(Horizon)) {
+		free := true
+		for _, ts := range taken {
+			if inZone(ts, sc.loc).Equal(t) {
+				free = false
+			}
+		}
+		if free {
+			starts = append(starts, t)
+		}
+	}
+
+	var updated []string
+	for i, e := range upcoming {
+		if i < len(starts) {
+			row := occurrenceRow{Event: sc.event(e.ID, starts[i]), OccurrenceStart: starts[i].Format(wallClock)}
+			if _, err := tx.NamedExecContext(ctx, updateOccurrence, row); err != nil {
+				return "", nil, err
+			}
+			updated = append(updated, e.ID)
+			continue
+		}
+		_, err = tx.ExecContext(ctx, `DELETE FROM events e WHERE id = $1
+					AND NOT EXISTS (SELECT 1 FROM event_registrations r WHERE r.event_id = e.id)
+					AND NOT EXISTS (SELECT 1 FROM history h WHERE h.event_id = e.id)`, e.ID)
+		if err != nil {
+			return "", nil, err
+		}
+		_, err = tx.ExecContext(ctx, `UPDATE events SET series_id = NULL, updated_at = CURRENT_TIMESTAMP WHERE id = $1`, e.ID)
+		if err != nil {
+			return "", nil, err
+		}
+	}
+	if len(starts) > len(upcoming) {
+		if err := sc.insert(ctx, tx, starts[len(upcoming):]); err != nil {
+			return "", nil, err
+		}
+	}
+	// Rescheduled occurrences take the new officers.
+	_, err = tx.ExecContext(ctx, `DELETE FROM event_officers WHERE event_id = ANY($1)`, pq.Array(updated))
+	if err != nil {
+		return "", nil, err
+	}
+	if err := assignSeriesOfficers(ctx, tx, series.ID); err != nil {
+		return "", nil, err
+	}
+
+	return series.ID, updated, tx.Commit()
+}
@@ -0,0 +1,23 @@
+// Package geo holds the geographic helpers shared by check-in and event
+// search.
+package geo
+
+import "math"
+
+const earthRadius = 6371000 // meters
+
+// Distance returns the great-circle distance in meters between two points
+// given in degrees, using the haversine formula.
+func Distance(lat1, lng1, lat2, lng2 float64) float64 {
+	rad := math.Pi / 180
+	dLat := (lat2 - lat1) * rad
+	dLng := (lng2 - lng1) * rad
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1*rad)*math.Cos(lat2*rad)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadius * math.Asin(math.Sqrt(a))
+}
+
+// ValidPoint reports whether lat and lng are valid coordinates.
+func ValidPoint(lat, lng float64) bool {
+	return lat >= -90 && lat <= 90 && lng >= -180 && lng <= 180
+}
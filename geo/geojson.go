@@ -0,0 +1,77 @@
+package geo
+
+import (
+	_ "embed"
+	"encoding/json"
+	"strings"
+	"sync"
+)
+
+// Geometry is any GeoJSON geometry.
+type Geometry struct {
+	Type        string      `json:"type"`
+	Coordinates interface{} `json:"coordinates"`
+}
+
+// PointGeometry returns a GeoJSON Point.
+func PointGeometry(lat, lng float64) *Geometry {
+	return &Geometry{Type: "Point", Coordinates: []float64{lng, lat}}
+}
+
+type Feature struct {
+	Type       string                 `json:"type"`
+	ID         string                 `json:"id,omitempty"`
+	Geometry   *Geometry              `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+type FeatureCollection struct {
+	Type     string    `json:"type"`
+	Features []Feature `json:"features"`
+}
+
+func NewFeatureCollection(features []Feature) FeatureCollection {
+	if features == nil {
+		features = []Feature{}
+	}
+	return FeatureCollection{Type: "FeatureCollection", Features: features}
+}
+
+// regionsJSON holds the regions of Uzbekistan. Each feature has a "name" and
+// an Uzbek "name_uz" property, and a simplified Polygon boundary; neighbouring
+// regions share their border positions, and Tashkent Region has the city cut
+// out as a hole. A finer file in the same shape can replace it as is.
+//
+//go:embed data/uzbekistan_regions.geojson
+var regionsJSON []byte
+
+var (
+	regionsOnce sync.Once
+	regions     FeatureCollection
+)
+
+// Regions returns a copy of the embedded regions of Uzbekistan.
+func Regions() FeatureCollection {
+	regionsOnce.Do(func() {
+		if err := json.Unmarshal(regionsJSON, &regions); err != nil {
+			panic("geo: invalid embedded regions: " + err.Error())
+		}
+	})
+
+	features := make([]Feature, len(regions.Features))
+	for i, f := range regions.Features {
+		props := make(map[string]interface{}, len(f.Properties))
+		for k, v := range f.Properties {
+			props[k] = v
+		}
+		f.Properties = props
+		features[i] = f
+	}
+	return NewFeatureCollection(features)
+}
+
+// RegionKey normalises a region name so names typed by users match the
+// embedded ones.
+func RegionKey(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
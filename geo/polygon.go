@@ -0,0 +1,56 @@
+package geo
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// Polygon is a GeoJSON Polygon. Coordinates are rings of [lng, lat]
+// positions; the first ring is the outline and any others are holes.
+type Polygon struct {
+	Type        string        `json:"type" example:"Polygon"`
+	Coordinates [][][]float64 `json:"coordinates"`
+}
+
+// Validate checks that p is a well-formed GeoJSON Polygon.
+func (p *Polygon) Validate() error {
+	if p.Type != "Polygon" {
+		return errors.New(`area type must be "Polygon"`)
+	}
+	if len(p.Coordinates) == 0 {
+		return errors.New("area needs at least one ring")
+	}
+	for _, ring := range p.Coordinates {
+		if len(ring) < 4 {
+			return errors.New("area rings need at least 4 positions")
+		}
+		for _, pos := range ring {
+			if len(pos) < 2 || !ValidPoint(pos[1], pos[0]) {
+				return fmt.Errorf("invalid area position %v", pos)
+			}
+		}
+		first, last := ring[0], ring[len(ring)-1]
+		if first[0] != last[0] || first[1] != last[1] {
+			return errors.New("area rings must be closed")
+		}
+	}
+	return nil
+}
+
+// Value stores the polygon as JSON.
+func (p Polygon) Value() (driver.Value, error) {
+	return json.Marshal(p)
+}
+
+// Scan reads a polygon stored as JSON.
+func (p *Polygon) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case []byte:
+		return json.Unmarshal(v, p)
+	case string:
+		return json.Unmarshal([]byte(v), p)
+	}
+	return fmt.Errorf("cannot scan %T into Polygon", src)
+}
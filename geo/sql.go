@@ -0,0 +1,53 @@
+package geo
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// metersPerDegree is the length of one degree of latitude.
+const metersPerDegree = 111320
+
+// HasPostGIS reports whether the PostGIS extension is installed.
+func HasPostGIS(ctx context.Context, db *sqlx.DB) (bool, error) {
+	var ok bool
+	err := db.GetContext(ctx, &ok, `SELECT EXISTS (SELECT 1 FROM pg_extension WHERE extname = 'postgis')`)
+	return ok, err
+}
+
+// DistanceSQL returns an SQL expression, with ? placeholders, for the
+// distance in meters between the point stored in columns latCol and lngCol
+// and (lat, lng). It uses PostGIS when available and the haversine formula
+// otherwise.
+func DistanceSQL(postgis bool, latCol, lngCol string, lat, lng float64) (string, []interface{}) {
+	if postgis {
+		return fmt.Sprintf(`ST_Distance(ST_SetSRID(ST_MakePoint(%s, %s), 4326)::geography,
+				ST_SetSRID(ST_MakePoint(?, ?), 4326)::geography)`, lngCol, latCol),
+			[]interface{}{lng, lat}
+	}
+	return fmt.Sprintf(`(2 * %d * ASIN(SQRT(POWER(SIN(RADIANS(%[2]s - ?) / 2), 2)
+				+ COS(RADIANS(?)) * COS(RADIANS(%[2]s)) * POWER(SIN(RADIANS(%[3]s - ?) / 2), 2))))`,
+			earthRadius, latCol, lngCol),
+		[]interface{}{lat, lat, lng}
+}
+
+// WithinSQL returns an SQL condition, with ? placeholders, that holds when
+// the point in latCol and lngCol is at most radius meters from (lat, lng).
+// Without PostGIS a bounding box lets the (lat, lng) index narrow the rows
+// before distances are computed.
+func WithinSQL(postgis bool, latCol, lngCol string, lat, lng, radius float64) (string, []interface{}) {
+	if postgis {
+		return fmt.Sprintf(`ST_DWithin(ST_SetSRID(ST_MakePoint(%s, %s), 4326)::geography,
+				ST_SetSRID(ST_MakePoint(?, ?), 4326)::geography, ?)`, lngCol, latCol),
+			[]interface{}{lng, lat, radius}
+	}
+
+	dLat := radius / metersPerDegree
+	dLng := radius / (metersPerDegree * math.Max(math.Cos(lat*math.Pi/180), 0.01))
+	distance, args := DistanceSQL(false, latCol, lngCol, lat, lng)
+	cond := fmt.Sprintf(`%[1]s BETWEEN ? AND ? AND %[2]s BETWEEN ? AND ? AND %[3]s <= ?`, latCol, lngCol, distance)
+	return cond, append([]interface{}{lat - dLat, lat + dLat, lng - dLng, lng + dLng}, append(args, radius)...)
+}
@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"worker-bot/ical"
+
+	"gopkg.in/telebot.v3"
+)
+
+// CalendarButton is the "Add to calendar" button. Its data is the event ID.
+var CalendarButton = &telebot.InlineButton{Unique: "ics"}
+
+func calendarButton(text, eventID string) telebot.InlineButton {
+	return telebot.InlineButton{Unique: CalendarButton.Unique, Text: text, Data: eventID}
+}
+
+// HandleCalendar sends the event of a pressed "Add to calendar" button as an
+// .ics file the phone opens in its calendar app.
+func HandleCalendar(c telebot.Context, b *telebot.Bot) error {
+	defer c.Respond()
+	if finder == nil {
+		return nil
+	}
+
+	events, err := finder.Calendar(context.Background(), "e.id = ? AND e.status <> 'draft'", "", c.Callback().Data)
+	if err != nil {
+		log.Println("Error fetching calendar event:", err)
+		_, err = b.Send(c.Sender(), "Xatolik yuz berdi. Iltimos, qayta urinib ko'ring.")
+		return err
+	}
+	if len(events) == 0 {
+		_, err = b.Send(c.Sender(), "Tadbir topilmadi.")
+		return err
+	}
+
+	var buf bytes.Buffer
+	if err := (ical.Calendar{Events: events}).Write(&buf); err != nil {
+		log.Println("Error writing calendar:", err)
+		return err
+	}
+	_, err = b.Send(c.Sender(), &telebot.Document{
+		File:     telebot.FromReader(&buf),
+		FileName: "tadbir.ics",
+		MIME:     "text/calendar",
+		Caption:  events[0].Summary + "\nFaylni oching va kalendaringizga qo'shing.",
+	})
+	return err
+}
@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+	"worker-bot/checkin"
+	"worker-bot/config"
+	"worker-bot/events"
+
+	"gopkg.in/telebot.v3"
+)
+
+// nearbyRadius is how far the bot looks for events around a shared location.
+const nearbyRadius = 50000
+
+var (
+	checkins *checkin.Service
+	finder   *events.Finder
+
+	// pendingLocation holds the step waiting for a user's location, e.g.
+	// during sign-up. Other shared locations are check-in attempts.
+	pendingLocation = make(map[int64]func(telebot.Context) error)
+)
+
+// UseCheckin sets the service that checks users in by their location.
+func UseCheckin(s *checkin.Service) {
+	checkins = s
+}
+
+// UseEvents sets the finder used to suggest events near a shared location.
+func UseEvents(f *events.Finder) {
+	finder = f
+}
+
+// awaitLocation makes next handle the next location userID shares.
+func awaitLocation(userID int64, next func(telebot.Context) error) {
+	mu.Lock()
+	pendingLocation[userID] = next
+	mu.Unlock()
+}
+
+// HandleLocation passes a shared location to the step waiting for it or
+// tries to check the user in to a running event.
+func HandleLocation(c telebot.Context, b *telebot.Bot) error {
+	userID := c.Sender().ID
+
+	mu.Lock()
+	next, ok := pendingLocation[userID]
+	delete(pendingLocation, userID)
+	mu.Unlock()
+	if ok {
+		return next(c)
+	}
+
+	return selfCheckIn(c, b, false)
+}
+
+// HandleLiveLocation handles updates of a live location. They only answer
+// once the user gets close enough to check in.
+func HandleLiveLocation(c telebot.Context, b *telebot.Bot) error {
+	if c.Message() == nil || c.Message().Location == nil {
+		return nil
+	}
+	return selfCheckIn(c, b, true)
+}
+
+func selfCheckIn(c telebot.Context, b *telebot.Bot, live bool) error {
+	if checkins == nil {
+		return nil
+	}
+
+	location := c.Message().Location
+	attempt := checkin.Attempt{
+		UserID: c.Sender().ID,
+		Lat:    float64(location.Lat),
+		Lng:    float64(location.Lng),
+	}
+	if location.HorizontalAccuracy != nil {
+		attempt.Accuracy = float64(*location.HorizontalAccuracy)
+	}
+
+	result, err := checkins.SelfCheckIn(context.Background(), attempt)
+	var tooFar *checkin.TooFarError
+	switch {
+	case err == nil:
+		_, err = b.Send(c.Sender(), fmt.Sprintf("✅ \"%s\" tadbiriga muvaffaqiyatli qayd etildingiz!", result.EventName))
+		return err
+	case live:
+		// Live updates keep coming while the user walks to the event.
+		return nil
+	case errors.As(err, &tooFar):
+		_, err = b.Send(c.Sender(), fmt.Sprintf("Siz \"%s\" tadbiri joyidan %.0f m uzoqdasiz. Qayd etilish uchun %d m ichida bo'lishingiz kerak. Jonli joylashuvni yuborsangiz, yetib kelganingizda avtomatik qayd etilasiz.",
+			tooFar.EventName, tooFar.Distance, tooFar.Radius))
+		return err
+	case err == checkin.ErrNoEvent:
+		return sendNearbyEvents(c, b, attempt.Lat, attempt.Lng)
+	case err == checkin.ErrAlreadyCheckedIn:
+		_, err = b.Send(c.Sender(), "Siz allaqachon qayd etilgansiz.")
+		return err
+	default:
+		log.Println("Error checking in by location:", err)
+		_, err = b.Send(c.Sender(), "Xatolik yuz berdi. Iltimos, qayta urinib ko'ring.")
+		return err
+	}
+}
+
+// sendNearbyEvents answers a location with the nearest events that have not
+// started yet.
+func sendNearbyEvents(c telebot.Context, b *telebot.Bot, lat, lng float64) error {
+	if finder == nil {
+		return nil
+	}
+
+	nearby, err := finder.Nearby(context.Background(), lat, lng, nearbyRadius, events.StatusUpcoming, 5)
+	if err != nil {
+		log.Println("Error fetching nearby events:", err)
+		_, err = b.Send(c.Sender(), "Xatolik yuz berdi. Iltimos, qayta urinib ko'ring.")
+		return err
+	}
+	if len(nearby) == 0 {
+		_, err = b.Send(c.Sender(), "Yaqin atrofda rejalashtirilgan tadbirlar yo'q.")
+		return err
+	}
+
+	var text strings.Builder
+	var buttons [][]telebot.InlineButton
+	text.WriteString("Sizga eng yaqin tadbirlar:\n")
+	for i, e := range nearby {
+		fmt.Fprintf(&text, "\n%d. %s — %.1f km", i+1, e.Name, e.DistanceM/1000)
+		buttons = append(buttons, []telebot.InlineButton{
+			calendarButton(fmt.Sprintf("📅 %d. Kalendarga", i+1), e.ID),
+			officerButton(fmt.Sprintf("📞 %d. Mas'ul", i+1), e.ID),
+		})
+		if start, err := time.Parse(time.RFC3339, e.StartDate); err == nil {
+			if e.SeriesID != nil {
+				// Occurrences of series are stored in UTC.
+				start = start.In(config.Tashkent)
+			}
+			fmt.Fprintf(&text, ", %s", start.Format("02.01.2006 15:04"))
+		}
+		if e.Address != "" {
+			fmt.Fprintf(&text, "\n   %s", e.Address)
+		}
+	}
+	_, err = b.Send(c.Sender(), text.String(), &telebot.ReplyMarkup{InlineKeyboard: buttons})
+	return err
+}
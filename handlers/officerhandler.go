@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"fmt"
+	"html"
+	"log"
+	"strings"
+
+	"gopkg.in/telebot.v3"
+)
+
+// OfficerButton is the "Contact the officer" button. Its data is the event ID.
+var OfficerButton = &telebot.InlineButton{Unique: "officer"}
+
+func officerButton(text, eventID string) telebot.InlineButton {
+	return telebot.InlineButton{Unique: OfficerButton.Unique, Text: text, Data: eventID}
+}
+
+// HandleOfficer sends the contacts of the officers of the event of a pressed
+// "Contact the officer" button, lead first. Officers without a phone number
+// are sent as a link to their Telegram account.
+func HandleOfficer(c telebot.Context, b *telebot.Bot) error {
+	defer c.Respond()
+
+	rows, err := db.Query(`SELECT u.id, u.first_name, u.last_name, COALESCE(u.phone_number, ''), o.role
+		FROM event_officers o JOIN users u ON u.id = o.user_id JOIN events e ON e.id = o.event_id
+		WHERE o.event_id = $1 AND e.status <> 'draft'
+		ORDER BY o.role = 'lead' DESC, u.first_name, u.last_name`, c.Callback().Data)
+	if err != nil {
+		log.Println("Error fetching event officers:", err)
+		_, err = b.Send(c.Sender(), "Xatolik yuz berdi. Iltimos, qayta urinib ko'ring.")
+		return err
+	}
+	defer rows.Close()
+
+	sent := 0
+	for rows.Next() {
+		var id int64
+		var firstName, lastName, phone, role string
+		if err := rows.Scan(&id, &firstName, &lastName, &phone, &role); err != nil {
+			log.Println("Error reading officer:", err)
+			continue
+		}
+
+		title := "Yordamchi"
+		if role == "lead" {
+			title = "Mas'ul shaxs"
+		}
+		name := strings.TrimSpace(firstName + " " + lastName)
+		switch {
+		case phone != "":
+			_, err = b.Send(c.Sender(), &telebot.Contact{PhoneNumber: phone, FirstName: firstName, LastName: lastName, UserID: id})
+		case id > 0:
+			// Telegram IDs are positive; officers without one cannot be linked.
+			_, err = b.Send(c.Sender(), fmt.Sprintf(`%s: <a href="tg://user?id=%d">%s</a>`, title, id, html.EscapeString(name)),
+				telebot.ModeHTML)
+		default:
+			_, err = b.Send(c.Sender(), title+": "+name)
+		}
+		if err != nil {
+			log.Println("Error sending officer contact:", err)
+			continue
+		}
+		sent++
+	}
+
+	if sent == 0 {
+		_, err = b.Send(c.Sender(), "Bu tadbir uchun mas'ul shaxs topilmadi.")
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"bytes"
+	"log"
+	"time"
+	"worker-bot/config"
+	"worker-bot/ticket"
+
+	"github.com/skip2/go-qrcode"
+	"gopkg.in/telebot.v3"
+)
+
+var tickets *ticket.Signer
+
+// UseTickets sets the signer of the check-in QR codes sent by /ticket.
+func UseTickets(s *ticket.Signer) {
+	tickets = s
+}
+
+// HandleTicket sends the user a check-in QR code for every event they are
+// registered for that has not ended yet.
+func HandleTicket(c telebot.Context, b *telebot.Bot) {
+	userID := c.Sender().ID
+	if tickets == nil {
+		return
+	}
+
+	rows, err := db.Query(`SELECT e.id, e.name FROM event_registrations r JOIN events e ON e.id = r.event_id
+		WHERE r.user_id = $1 AND r.status = 'registered' AND r.checked_out_at IS NULL
+		AND e.status IN ('published', 'ongoing') AND e.end_date > CURRENT_TIMESTAMP ORDER BY e.start_date`, userID)
+	if err != nil {
+		log.Println("Error fetching registrations:", err)
+		b.Send(c.Sender(), "Xatolik yuz berdi. Iltimos, qayta urinib ko'ring.")
+		return
+	}
+	defer rows.Close()
+
+	sent := 0
+	for rows.Next() {
+		var eventID, name string
+		if err := rows.Scan(&eventID, &name); err != nil {
+			log.Println("Error reading registration:", err)
+			continue
+		}
+
+		token, claims := tickets.Issue(eventID, userID, time.Now())
+		png, err := qrcode.Encode(token, qrcode.Medium, 512)
+		if err != nil {
+			log.Println("Error encoding QR code:", err)
+			continue
+		}
+
+		photo := &telebot.Photo{
+			File:    telebot.FromReader(bytes.NewReader(png)),
+			Caption: name + "\nQR kod " + claims.ExpiresAt.In(config.Tashkent).Format("15:04") + " gacha amal qiladi.",
+		}
+		markup := &telebot.ReplyMarkup{InlineKeyboard: [][]telebot.InlineButton{
+			{calendarButton("📅 Kalendarga qo'shish", eventID)},
+			{officerButton("📞 Mas'ul bilan bog'lanish", eventID)},
+		}}
+		if _, err := b.Send(c.Sender(), photo, markup); err != nil {
+			log.Println("Error sending QR code:", err)
+			continue
+		}
+		sent++
+	}
+
+	if sent == 0 {
+		b.Send(c.Sender(), "Siz hech qanday tadbirga ro'yxatdan o'tmagansiz.")
+	}
+}
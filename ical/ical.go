@@ -0,0 +1,120 @@
+// Package ical writes iCalendar (RFC 5545) files so events can be added to
+// and subscribed from phone calendars.
+package ical
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const ContentType = "text/calendar; charset=utf-8"
+
+// maxLine is the longest content line in octets before it is folded.
+const maxLine = 75
+
+const (
+	StatusConfirmed = "CONFIRMED"
+	StatusTentative = "TENTATIVE"
+	StatusCancelled = "CANCELLED"
+)
+
+type Event struct {
+	// UID identifies the event across feeds and updates.
+	UID         string
+	Summary     string
+	Description string
+	Location    string
+	Lat, Lng    *float64
+	Start, End  time.Time
+	// Modified is the last change. Calendars replace their copy of an event
+	// when its sequence grows.
+	Modified time.Time
+	Status   string
+}
+
+type Calendar struct {
+	Name   string
+	Events []Event
+}
+
+// Write writes the calendar to w.
+func (c Calendar) Write(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	line := func(name, value string) {
+		fold(bw, name+":"+value)
+	}
+	now := time.Now()
+
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", "-//Worker Bot//Eco Events//UZ")
+	line("CALSCALE", "GREGORIAN")
+	line("METHOD", "PUBLISH")
+	if c.Name != "" {
+		line("X-WR-CALNAME", escape(c.Name))
+	}
+	line("REFRESH-INTERVAL;VALUE=DURATION", "PT1H")
+	line("X-PUBLISHED-TTL", "PT1H")
+
+	for _, e := range c.Events {
+		line("BEGIN", "VEVENT")
+		line("UID", e.UID)
+		line("DTSTAMP", stamp(now))
+		line("DTSTART", stamp(e.Start))
+		line("DTEND", stamp(e.End))
+		if !e.Modified.IsZero() {
+			line("LAST-MODIFIED", stamp(e.Modified))
+			line("SEQUENCE", fmt.Sprint(e.Modified.Unix()))
+		}
+		line("SUMMARY", escape(e.Summary))
+		if e.Description != "" {
+			line("DESCRIPTION", escape(e.Description))
+		}
+		if e.Location != "" {
+			line("LOCATION", escape(e.Location))
+		}
+		if e.Lat != nil && e.Lng != nil {
+			line("GEO", fmt.Sprintf("%.6f;%.6f", *e.Lat, *e.Lng))
+		}
+		if e.Status != "" {
+			line("STATUS", e.Status)
+		}
+		line("END", "VEVENT")
+	}
+
+	line("END", "VCALENDAR")
+	return bw.Flush()
+}
+
+func stamp(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+var escaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`)
+
+func escape(s string) string {
+	return escaper.Replace(s)
+}
+
+// fold writes a content line, breaking it into lines of at most maxLine
+// octets without splitting UTF-8 characters.
+func fold(w *bufio.Writer, s string) {
+	limit := maxLine
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		w.WriteString(s[:cut])
+		w.WriteString("\r\n ")
+		s = s[cut:]
+		// Continuation lines start with a space.
+		limit = maxLine - 1
+	}
+	w.WriteString(s)
+	w.WriteString("\r\n")
+}
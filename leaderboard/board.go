@@ -0,0 +1,171 @@
+package leaderboard
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+	"worker-bot/config"
+	"worker-bot/xp"
+
+	"github.com/lib/pq"
+)
+
+const (
+	PeriodAll    = "all"
+	PeriodToday  = "today"
+	PeriodWeek   = "week"
+	PeriodMonth  = "month"
+	PeriodSeason = "season"
+)
+
+// PeriodStart returns when period started as of now, in the configured time
+// zone. PeriodAll returns the zero time. PeriodSeason depends on the seasons
+// table and is resolved by CurrentSeason instead.
+func PeriodStart(period string, now time.Time) (time.Time, error) {
+	now = now.In(config.Tashkent)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, config.Tashkent)
+
+	switch period {
+	case PeriodAll:
+		return time.Time{}, nil
+	case PeriodToday:
+		return today, nil
+	case PeriodWeek:
+		return today.AddDate(0, 0, -(int(today.Weekday())+6)%7), nil
+	case PeriodMonth:
+		return today.AddDate(0, 0, 1-today.Day()), nil
+	}
+	return time.Time{}, fmt.Errorf("period must be one of all, today, week, month, season")
+}
+
+// Board selects who is ranked and by which XP. The zero Board is the
+// all-time global ranking with competition ranks (1, 2, 2, 4).
+type Board struct {
+	// Since ranks users by XP earned from this moment on instead of their
+	// total XP. Only users who earned XP in the window are ranked.
+	Since time.Time
+	// Until, when set with Since, ends the window before this moment.
+	Until time.Time
+	// Season ranks users by the archived final standings of a closed
+	// season. Since and Until are ignored and Region matches the region
+	// users had when the season closed.
+	Season int
+	// Region limits the board to users of one region.
+	Region string
+	// UserIDs limits the board to these users, e.g. a user and their friends.
+	UserIDs []int64
+	// Dense gives tied users the same rank without gaps (1, 2, 2, 3).
+	Dense bool
+}
+
+func (b Board) global() bool {
+	return b.Since.IsZero() && b.Season == 0 && b.Region == "" && b.UserIDs == nil && !b.Dense
+}
+
+// ranked renders the board as SQL selecting id, xp, rank and pos, where pos
+// is the position in listing order. It uses ? placeholders.
+func (b Board) ranked() (string, []interface{}) {
+	var (
+		conds  []string
+		args   []interface{}
+		from   = "users u"
+		score  = "u.xp"
+		region = "u.region"
+	)
+
+	switch {
+	case b.Season != 0:
+		from = "users u JOIN season_standings s ON s.user_id = u.id"
+		score = "s.xp"
+		region = "s.region"
+		conds = append(conds, "s.season_id = ?")
+		args = append(args, b.Season)
+	case !b.Since.IsZero():
+		window := "created_at >= ?"
+		args = append(args, pq.Array(xp.EarnedSources), b.Since)
+		if !b.Until.IsZero() {
+			window += " AND created_at < ?"
+			args = append(args, b.Until)
+		}
+		from = `users u JOIN (SELECT user_id, SUM(amount) AS earned FROM xp_ledger
+				WHERE source = ANY(?) AND ` + window + ` GROUP BY user_id) e ON e.user_id = u.id`
+		score = "e.earned"
+		conds = append(conds, "e.earned > 0")
+	}
+	if b.Region != "" {
+		conds = append(conds, region+" = ?")
+		args = append(args, b.Region)
+	}
+	if b.UserIDs != nil {
+		conds = append(conds, "u.id = ANY(?)")
+		args = append(args, pq.Array(b.UserIDs))
+	}
+
+	where := ""
+	if len(conds) > 0 {
+		where = " WHERE " + strings.Join(conds, " AND ")
+	}
+
+	rank := "RANK()"
+	if b.Dense {
+		rank = "DENSE_RANK()"
+	}
+
+	query := fmt.Sprintf(`SELECT id, xp, %s OVER (ORDER BY xp DESC) AS rank,
+				ROW_NUMBER() OVER (ORDER BY xp DESC, id DESC) AS pos
+				FROM (SELECT u.id, %s AS xp FROM %s%s) scores`, rank, score, from, where)
+	return query, args
+}
+
+func (l *Leaderboard) sqlTotal(ctx context.Context, b Board) (int64, error) {
+	ranked, args := b.ranked()
+	query := l.db.Rebind(`SELECT COUNT(*) FROM (` + ranked + `) ranked`)
+
+	var n int64
+	err := l.db.GetContext(ctx, &n, query, args...)
+	return n, err
+}
+
+func (l *Leaderboard) sqlPage(ctx context.Context, b Board, afterUserID int64, limit int) ([]Entry, error) {
+	ranked, args := b.ranked()
+	query := l.db.Rebind(`WITH ranked AS (` + ranked + `)
+			  SELECT id, xp, rank FROM ranked
+			  WHERE pos > CASE WHEN CAST(? AS bigint) = 0 THEN 0 ELSE (SELECT pos FROM ranked WHERE id = ?) END
+			  ORDER BY pos LIMIT ?`)
+	args = append(args, afterUserID, afterUserID, limit)
+
+	entries := []Entry{}
+	err := l.db.SelectContext(ctx, &entries, query, args...)
+	return entries, err
+}
+
+func (l *Leaderboard) sqlRank(ctx context.Context, b Board, userID int64) (Entry, error) {
+	ranked, args := b.ranked()
+	query := l.db.Rebind(`SELECT id, xp, rank FROM (` + ranked + `) ranked WHERE id = ?`)
+	args = append(args, userID)
+
+	var entry Entry
+	err := l.db.GetContext(ctx, &entry, query, args...)
+	if err == sql.ErrNoRows {
+		return Entry{}, ErrNotRanked
+	}
+	return entry, err
+}
+
+func (l *Leaderboard) sqlAround(ctx context.Context, b Board, userID int64, radius int) ([]Entry, error) {
+	ranked, args := b.ranked()
+	query := l.db.Rebind(`WITH ranked AS (` + ranked + `), me AS (SELECT pos FROM ranked WHERE id = ?)
+			  SELECT id, xp, rank FROM ranked, me
+			  WHERE ranked.pos BETWEEN me.pos - ? AND me.pos + ?
+			  ORDER BY ranked.pos`)
+	args = append(args, userID, radius, radius)
+
+	entries := []Entry{}
+	err := l.db.SelectContext(ctx, &entries, query, args...)
+	if err == nil && len(entries) == 0 {
+		return nil, ErrNotRanked
+	}
+	return entries, err
+}
@@ -0,0 +1,338 @@
+// Package leaderboard keeps the global XP ranking in a Redis sorted set and
+// falls back to Postgres window functions whenever Redis cannot be trusted.
+package leaderboard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"sync"
+	"sync/atomic"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	key        = "leaderboard:xp"
+	rebuildKey = "leaderboard:xp:rebuild"
+	batchSize  = 1000
+)
+
+// ErrNotRanked is returned for users that are not on the board.
+var ErrNotRanked = errors.New("user is not ranked")
+
+type Entry struct {
+	UserID int64 `db:"id" json:"user_id"`
+	XP     int64 `db:"xp" json:"xp"`
+	Rank   int64 `db:"rank" json:"rank"`
+}
+
+// Leaderboard ranks users by XP. The all-time global board lives in Redis;
+// scoped boards and the fallback are computed in Postgres. Users with equal
+// XP share a rank and are listed by descending user ID on every board.
+type Leaderboard struct {
+	db  *sqlx.DB
+	rdb redis.UniversalClient
+
+	// synced is false until the sorted set has been rebuilt from Postgres
+	// and again after any failed write, so reads go to Postgres meanwhile.
+	synced atomic.Bool
+
+	// rebuilding serialises rebuilds. While one runs, pending holds the
+	// latest change of every user set or removed since it began, nil for a
+	// removal, so they survive the swap of the rebuilt set.
+	rebuilding sync.Mutex
+	mu         sync.Mutex
+	pending    map[int64]*int64
+}
+
+// New returns a leaderboard backed by rdb. rdb may be nil, in which case
+// every call is served by Postgres.
+func New(db *sqlx.DB, rdb redis.UniversalClient) *Leaderboard {
+	return &Leaderboard{db: db, rdb: rdb}
+}
+
+// member zero-pads the user ID so Redis orders equal scores numerically.
+func member(userID int64) string {
+	return fmt.Sprintf("%020d", userID)
+}
+
+func (l *Leaderboard) useRedis() bool {
+	return l.rdb != nil && l.synced.Load()
+}
+
+// Rebuild reloads the sorted set from users.xp and swaps it in atomically.
+// Scores set or removed while it runs are applied again after the swap.
+func (l *Leaderboard) Rebuild(ctx context.Context) error {
+	if l.rdb == nil {
+		return nil
+	}
+	l.rebuilding.Lock()
+	defer l.rebuilding.Unlock()
+
+	l.mu.Lock()
+	l.pending = map[int64]*int64{}
+	l.mu.Unlock()
+	defer func() {
+		l.mu.Lock()
+		l.pending = nil
+		l.mu.Unlock()
+	}()
+
+	rows, err := l.db.QueryxContext(ctx, "SELECT id, xp FROM users")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	if err := l.rdb.Del(ctx, rebuildKey).Err(); err != nil {
+		l.synced.Store(false)
+		return err
+	}
+
+	total := 0
+	batch := make([]redis.Z, 0, batchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		err := l.rdb.ZAdd(ctx, rebuildKey, batch...).Err()
+		total += len(batch)
+		batch = batch[:0]
+		return err
+	}
+
+	for rows.Next() {
+		var e Entry
+		if err := rows.StructScan(&e); err != nil {
+			return err
+		}
+		batch = append(batch, redis.Z{Score: float64(e.XP), Member: member(e.UserID)})
+		if len(batch) == batchSize {
+			if err := flush(); err != nil {
+				l.synced.Store(false)
+				return err
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if err := flush(); err != nil {
+		l.synced.Store(false)
+		return err
+	}
+
+	if err := l.swap(ctx, total); err != nil {
+		l.synced.Store(false)
+		return err
+	}
+
+	l.synced.Store(true)
+	return nil
+}
+
+// swap replaces the sorted set with the rebuilt one and applies the pending
+// changes in the same transaction. Set and Remove wait for it, so none of
+// their changes fall between the two.
+func (l *Leaderboard) swap(ctx context.Context, total int) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	_, err := l.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		if total == 0 {
+			pipe.Del(ctx, key)
+		} else {
+			pipe.Rename(ctx, rebuildKey, key)
+		}
+		for userID, xp := range l.pending {
+			if xp == nil {
+				pipe.ZRem(ctx, key, member(userID))
+			} else {
+				pipe.ZAdd(ctx, key, redis.Z{Score: float64(*xp), Member: member(userID)})
+			}
+		}
+		return nil
+	})
+	l.pending = nil
+	return err
+}
+
+// record notes a change for the rebuild in progress, if any.
+func (l *Leaderboard) record(userID int64, xp *int64) {
+	l.mu.Lock()
+	if l.pending != nil {
+		l.pending[userID] = xp
+	}
+	l.mu.Unlock()
+}
+
+// Resync rebuilds the sorted set if a previous write or rebuild failed.
+func (l *Leaderboard) Resync(ctx context.Context) {
+	if l.rdb == nil || l.synced.Load() {
+		return
+	}
+	if err := l.Rebuild(ctx); err != nil {
+		log.Printf("Error rebuilding leaderboard: %v", err)
+	}
+}
+
+// Set records the current XP of a user. Callers pass the value returned by
+// their UPDATE so concurrent changes cannot leave a stale score behind.
+func (l *Leaderboard) Set(ctx context.Context, userID, xp int64) {
+	if l.rdb == nil {
+		return
+	}
+	l.record(userID, &xp)
+	err := l.rdb.ZAdd(ctx, key, redis.Z{Score: float64(xp), Member: member(userID)}).Err()
+	if err != nil {
+		log.Printf("Error updating leaderboard, falling back to Postgres: %v", err)
+		l.synced.Store(false)
+	}
+}
+
+// Remove drops a deleted user from the ranking.
+func (l *Leaderboard) Remove(ctx context.Context, userID int64) {
+	if l.rdb == nil {
+		return
+	}
+	l.record(userID, nil)
+	if err := l.rdb.ZRem(ctx, key, member(userID)).Err(); err != nil {
+		log.Printf("Error updating leaderboard, falling back to Postgres: %v", err)
+		l.synced.Store(false)
+	}
+}
+
+// Total returns the number of users on board b.
+func (l *Leaderboard) Total(ctx context.Context, b Board) (int64, error) {
+	if b.global() && l.useRedis() {
+		n, err := l.rdb.ZCard(ctx, key).Result()
+		if err == nil {
+			return n, nil
+		}
+		l.redisFailed(err)
+	}
+	return l.sqlTotal(ctx, b)
+}
+
+// Page returns up to limit entries of board b following afterUserID, or the
+// top of the board when afterUserID is 0.
+func (l *Leaderboard) Page(ctx context.Context, b Board, afterUserID int64, limit int) ([]Entry, error) {
+	if b.global() && l.useRedis() {
+		entries, err := l.redisPage(ctx, afterUserID, limit)
+		if err == nil {
+			return entries, nil
+		}
+		l.redisFailed(err)
+	}
+	return l.sqlPage(ctx, b, afterUserID, limit)
+}
+
+// Rank returns the entry of a single user on board b.
+func (l *Leaderboard) Rank(ctx context.Context, b Board, userID int64) (Entry, error) {
+	if b.global() && l.useRedis() {
+		entry, err := l.redisRank(ctx, userID)
+		if err == redis.Nil {
+			return Entry{}, ErrNotRanked
+		}
+		if err == nil {
+			return entry, nil
+		}
+		l.redisFailed(err)
+	}
+	return l.sqlRank(ctx, b, userID)
+}
+
+// Around returns the user's entry on board b with up to radius entries on
+// either side.
+func (l *Leaderboard) Around(ctx context.Context, b Board, userID int64, radius int) ([]Entry, error) {
+	if b.global() && l.useRedis() {
+		entries, err := l.redisAround(ctx, userID, radius)
+		if err == redis.Nil {
+			return nil, ErrNotRanked
+		}
+		if err == nil {
+			return entries, nil
+		}
+		l.redisFailed(err)
+	}
+	return l.sqlAround(ctx, b, userID, radius)
+}
+
+func (l *Leaderboard) redisFailed(err error) {
+	log.Printf("Error reading leaderboard from Redis, falling back to Postgres: %v", err)
+	l.synced.Store(false)
+}
+
+// redisRange reads positions start..stop (0-based, highest XP first) and
+// derives competition ranks: ties share the rank of their first position.
+func (l *Leaderboard) redisRange(ctx context.Context, start, stop int64) ([]Entry, error) {
+	zs, err := l.rdb.ZRevRangeWithScores(ctx, key, start, stop).Result()
+	if err != nil || len(zs) == 0 {
+		return []Entry{}, err
+	}
+
+	above, err := l.rdb.ZCount(ctx, key, "("+strconv.FormatFloat(zs[0].Score, 'f', -1, 64), "+inf").Result()
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]Entry, len(zs))
+	rank := above + 1
+	for i, z := range zs {
+		id, err := strconv.ParseInt(z.Member.(string), 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		if i > 0 && z.Score != zs[i-1].Score {
+			rank = start + int64(i) + 1
+		}
+		entries[i] = Entry{UserID: id, XP: int64(z.Score), Rank: rank}
+	}
+	return entries, nil
+}
+
+func (l *Leaderboard) redisPage(ctx context.Context, afterUserID int64, limit int) ([]Entry, error) {
+	var start int64
+	if afterUserID != 0 {
+		pos, err := l.rdb.ZRevRank(ctx, key, member(afterUserID)).Result()
+		if err == redis.Nil {
+			return []Entry{}, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		start = pos + 1
+	}
+	return l.redisRange(ctx, start, start+int64(limit)-1)
+}
+
+func (l *Leaderboard) redisRank(ctx context.Context, userID int64) (Entry, error) {
+	pos, err := l.rdb.ZRevRank(ctx, key, member(userID)).Result()
+	if err != nil {
+		return Entry{}, err
+	}
+	entries, err := l.redisRange(ctx, pos, pos)
+	if err != nil {
+		return Entry{}, err
+	}
+	if len(entries) == 0 {
+		return Entry{}, redis.Nil
+	}
+	return entries[0], nil
+}
+
+func (l *Leaderboard) redisAround(ctx context.Context, userID int64, radius int) ([]Entry, error) {
+	pos, err := l.rdb.ZRevRank(ctx, key, member(userID)).Result()
+	if err != nil {
+		return nil, err
+	}
+	start := pos - int64(radius)
+	if start < 0 {
+		start = 0
+	}
+	return l.redisRange(ctx, start, pos+int64(radius))
+}
@@ -0,0 +1,277 @@
+package leaderboard
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/jmoiron/sqlx"
+	"github.com/redis/go-redis/v9"
+)
+
+// scores are the users.xp the tests rebuild from. Users 2, 3 and 4 tie.
+var scores = [][2]int64{{1, 100}, {2, 50}, {3, 50}, {4, 50}, {5, 10}}
+
+// newTestBoard returns a leaderboard on an in-process Redis and a mocked
+// Postgres, rebuilt from scores.
+func newTestBoard(t *testing.T) (*Leaderboard, *miniredis.Miniredis, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { rdb.Close() })
+
+	l := New(sqlx.NewDb(db, "postgres"), rdb)
+	expectUsers(mock, scores)
+	if err := l.Rebuild(context.Background()); err != nil {
+		t.Fatalf("Rebuild: %v", err)
+	}
+	return l, mr, mock
+}
+
+func expectUsers(mock sqlmock.Sqlmock, users [][2]int64) {
+	rows := sqlmock.NewRows([]string{"id", "xp"})
+	for _, u := range users {
+		rows.AddRow(u[0], u[1])
+	}
+	mock.ExpectQuery("SELECT id, xp FROM users").WillReturnRows(rows)
+}
+
+func TestRebuild(t *testing.T) {
+	l, mr, mock := newTestBoard(t)
+
+	members, err := mr.ZMembers(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(members) != len(scores) {
+		t.Fatalf("got %d members, want %d", len(members), len(scores))
+	}
+	if score, _ := mr.ZScore(key, member(1)); score != 100 {
+		t.Errorf("user 1 score = %v, want 100", score)
+	}
+	if mr.Exists(rebuildKey) {
+		t.Error("rebuild key left behind")
+	}
+
+	// Users gone from Postgres are dropped by the next rebuild.
+	expectUsers(mock, scores[:2])
+	if err := l.Rebuild(context.Background()); err != nil {
+		t.Fatalf("Rebuild: %v", err)
+	}
+	if members, _ := mr.ZMembers(key); len(members) != 2 {
+		t.Errorf("got %d members after rebuild, want 2", len(members))
+	}
+
+	expectUsers(mock, nil)
+	if err := l.Rebuild(context.Background()); err != nil {
+		t.Fatalf("Rebuild: %v", err)
+	}
+	if mr.Exists(key) {
+		t.Error("board of no users still exists")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestChangesDuringRebuild(t *testing.T) {
+	l, mr, mock := newTestBoard(t)
+	ctx := context.Background()
+
+	// The rebuild reads users before user 5 gains XP and user 1 is deleted,
+	// and swaps its snapshot in after both changes reached Redis.
+	rows := sqlmock.NewRows([]string{"id", "xp"})
+	for _, u := range scores {
+		rows.AddRow(u[0], u[1])
+	}
+	mock.ExpectQuery("SELECT id, xp FROM users").WillDelayFor(200 * time.Millisecond).WillReturnRows(rows)
+	done := make(chan error)
+	go func() { done <- l.Rebuild(ctx) }()
+	for {
+		l.mu.Lock()
+		started := l.pending != nil
+		l.mu.Unlock()
+		if started {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	l.Set(ctx, 5, 500)
+	l.Remove(ctx, 1)
+	if err := <-done; err != nil {
+		t.Fatalf("Rebuild: %v", err)
+	}
+
+	if score, _ := mr.ZScore(key, member(5)); score != 500 {
+		t.Errorf("user 5 score = %v, want 500", score)
+	}
+	if members, _ := mr.ZMembers(key); len(members) != len(scores)-1 {
+		t.Errorf("got %d members, want %d without the deleted user", len(members), len(scores)-1)
+	}
+	if l.pending != nil {
+		t.Error("changes still recorded after the rebuild")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestPage(t *testing.T) {
+	l, _, mock := newTestBoard(t)
+	ctx := context.Background()
+
+	// Tied users share the rank of the first of them and are listed by
+	// descending user ID.
+	entries, err := l.Page(ctx, Board{}, 0, 10)
+	if err != nil {
+		t.Fatalf("Page: %v", err)
+	}
+	want := []Entry{{1, 100, 1}, {4, 50, 2}, {3, 50, 2}, {2, 50, 2}, {5, 10, 5}}
+	if !reflect.DeepEqual(entries, want) {
+		t.Errorf("Page = %v, want %v", entries, want)
+	}
+
+	// A page starting within a tie keeps the shared rank.
+	entries, err = l.Page(ctx, Board{}, 4, 2)
+	if err != nil {
+		t.Fatalf("Page: %v", err)
+	}
+	want = []Entry{{3, 50, 2}, {2, 50, 2}}
+	if !reflect.DeepEqual(entries, want) {
+		t.Errorf("Page after 4 = %v, want %v", entries, want)
+	}
+
+	entries, err = l.Page(ctx, Board{}, 5, 10)
+	if err != nil || len(entries) != 0 {
+		t.Errorf("Page after last = %v, %v, want no entries", entries, err)
+	}
+
+	if n, err := l.Total(ctx, Board{}); err != nil || n != 5 {
+		t.Errorf("Total = %d, %v, want 5", n, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRank(t *testing.T) {
+	l, _, _ := newTestBoard(t)
+	ctx := context.Background()
+
+	for _, want := range []Entry{{1, 100, 1}, {2, 50, 2}, {4, 50, 2}, {5, 10, 5}} {
+		entry, err := l.Rank(ctx, Board{}, want.UserID)
+		if err != nil {
+			t.Fatalf("Rank(%d): %v", want.UserID, err)
+		}
+		if entry != want {
+			t.Errorf("Rank(%d) = %v, want %v", want.UserID, entry, want)
+		}
+	}
+
+	if _, err := l.Rank(ctx, Board{}, 99); err != ErrNotRanked {
+		t.Errorf("Rank of unknown user: got %v, want ErrNotRanked", err)
+	}
+}
+
+func TestAround(t *testing.T) {
+	l, _, _ := newTestBoard(t)
+	ctx := context.Background()
+
+	entries, err := l.Around(ctx, Board{}, 3, 1)
+	if err != nil {
+		t.Fatalf("Around: %v", err)
+	}
+	want := []Entry{{4, 50, 2}, {3, 50, 2}, {2, 50, 2}}
+	if !reflect.DeepEqual(entries, want) {
+		t.Errorf("Around(3) = %v, want %v", entries, want)
+	}
+
+	// The window is cut at the top of the board.
+	entries, err = l.Around(ctx, Board{}, 1, 2)
+	if err != nil {
+		t.Fatalf("Around: %v", err)
+	}
+	want = []Entry{{1, 100, 1}, {4, 50, 2}, {3, 50, 2}}
+	if !reflect.DeepEqual(entries, want) {
+		t.Errorf("Around(1) = %v, want %v", entries, want)
+	}
+
+	if _, err := l.Around(ctx, Board{}, 99, 1); err != ErrNotRanked {
+		t.Errorf("Around unknown user: got %v, want ErrNotRanked", err)
+	}
+}
+
+func TestPostgresFallback(t *testing.T) {
+	l, mr, mock := newTestBoard(t)
+	ctx := context.Background()
+	mr.SetError("LOADING Redis is loading the dataset in memory")
+
+	mock.ExpectQuery("WITH ranked AS").WithArgs(0, 0, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "xp", "rank"}).AddRow(1, 100, 1).AddRow(4, 50, 2))
+	entries, err := l.Page(ctx, Board{}, 0, 2)
+	if err != nil {
+		t.Fatalf("Page: %v", err)
+	}
+	want := []Entry{{1, 100, 1}, {4, 50, 2}}
+	if !reflect.DeepEqual(entries, want) {
+		t.Errorf("Page = %v, want %v", entries, want)
+	}
+
+	// Once Redis has failed, reads go to Postgres until it is resynced,
+	// even if Redis answers again.
+	mr.SetError("")
+	mock.ExpectQuery("SELECT id, xp, rank FROM").WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "xp", "rank"}).AddRow(2, 50, 2))
+	entry, err := l.Rank(ctx, Board{}, 2)
+	if err != nil {
+		t.Fatalf("Rank: %v", err)
+	}
+	if want := (Entry{2, 50, 2}); entry != want {
+		t.Errorf("Rank = %v, want %v", entry, want)
+	}
+
+	expectUsers(mock, scores)
+	l.Resync(ctx)
+	if _, err := l.Rank(ctx, Board{}, 2); err != nil {
+		t.Fatalf("Rank after resync: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSetFailureFallsBack(t *testing.T) {
+	l, mr, mock := newTestBoard(t)
+	ctx := context.Background()
+
+	l.Set(ctx, 5, 200)
+	if score, _ := mr.ZScore(key, member(5)); score != 200 {
+		t.Errorf("user 5 score = %v, want 200", score)
+	}
+
+	// A failed write leaves Redis stale, so reads fall back to Postgres.
+	mr.SetError("READONLY You can't write against a read only replica")
+	l.Set(ctx, 5, 300)
+	mr.SetError("")
+	mock.ExpectQuery("SELECT id, xp, rank FROM").WithArgs(5).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "xp", "rank"}).AddRow(5, 300, 1))
+	entry, err := l.Rank(ctx, Board{}, 5)
+	if err != nil {
+		t.Fatalf("Rank: %v", err)
+	}
+	if entry.XP != 300 {
+		t.Errorf("Rank = %v, want the Postgres XP of 300", entry)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
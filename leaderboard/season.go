@@ -0,0 +1,186 @@
+package leaderboard
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"strconv"
+	"time"
+	"worker-bot/models"
+	"worker-bot/xp"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+var (
+	ErrNoSeason       = errors.New("no season is running")
+	ErrSeasonNotFound = errors.New("season not found")
+)
+
+const seasonColumns = `id, name, starts_at, ends_at, closed_at`
+
+// CurrentSeason returns the season running at now.
+func (l *Leaderboard) CurrentSeason(ctx context.Context, now time.Time) (models.Season, error) {
+	var s models.Season
+	err := l.db.GetContext(ctx, &s, `SELECT `+seasonColumns+` FROM seasons
+				WHERE starts_at <= $1 AND ends_at > $1
+				ORDER BY starts_at DESC LIMIT 1`, now)
+	if err == sql.ErrNoRows {
+		return s, ErrNoSeason
+	}
+	return s, err
+}
+
+// Season returns a season with its prizes.
+func (l *Leaderboard) Season(ctx context.Context, id int) (models.Season, error) {
+	var s models.Season
+	err := l.db.GetContext(ctx, &s, `SELECT `+seasonColumns+` FROM seasons WHERE id = $1`, id)
+	if err == sql.ErrNoRows {
+		return s, ErrSeasonNotFound
+	}
+	if err != nil {
+		return s, err
+	}
+
+	s.Prizes = []models.SeasonPrize{}
+	err = l.db.SelectContext(ctx, &s.Prizes, `SELECT id, season_id, top_n, xp, badge FROM season_prizes
+				WHERE season_id = $1 ORDER BY top_n, id`, id)
+	return s, err
+}
+
+// SeasonBoard returns the board of season s: the archived standings once it
+// is closed, the XP earned between its start and end until then.
+func SeasonBoard(s models.Season) Board {
+	if s.ClosedAt != nil {
+		return Board{Season: s.ID}
+	}
+	return Board{Since: s.StartsAt, Until: s.EndsAt}
+}
+
+// CloseSeasons closes every season that has ended: it archives the final
+// standings, awards the prizes and marks the season closed. It is safe to
+// run from several instances at once.
+func (l *Leaderboard) CloseSeasons(ctx context.Context) error {
+	var ids []int
+	err := l.db.SelectContext(ctx, &ids, `SELECT id FROM seasons
+				WHERE closed_at IS NULL AND ends_at <= now() ORDER BY ends_at`)
+	if err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		winners, err := l.closeSeason(ctx, id)
+		if err != nil {
+			return err
+		}
+		if len(winners) > 0 {
+			l.SyncUsers(ctx, winners)
+		}
+		log.Printf("Closed season %d, %d users awarded XP", id, len(winners))
+	}
+	return nil
+}
+
+// closeSeason archives and awards one season in a transaction. It returns
+// the users whose XP changed.
+func (l *Leaderboard) closeSeason(ctx context.Context, id int) ([]int64, error) {
+	tx, err := l.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var s models.Season
+	err = tx.GetContext(ctx, &s, `SELECT `+seasonColumns+` FROM seasons
+				WHERE id = $1 AND closed_at IS NULL FOR UPDATE SKIP LOCKED`, id)
+	if err == sql.ErrNoRows {
+		// Closed or being closed by another instance.
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.ExecContext(ctx, `INSERT INTO season_standings (season_id, user_id, region, xp, rank, region_rank)
+				SELECT $1, u.id, u.region, e.earned,
+					RANK() OVER (ORDER BY e.earned DESC),
+					CASE WHEN u.region IS NULL THEN NULL
+						ELSE RANK() OVER (PARTITION BY u.region ORDER BY e.earned DESC) END
+				FROM users u JOIN (SELECT user_id, SUM(amount) AS earned FROM xp_ledger
+					WHERE source = ANY($2) AND created_at >= $3 AND created_at < $4
+					GROUP BY user_id) e ON e.user_id = u.id
+				WHERE e.earned > 0`,
+		s.ID, pq.Array(xp.EarnedSources), s.StartsAt, s.EndsAt)
+	if err != nil {
+		return nil, err
+	}
+
+	winners, err := awardPrizes(ctx, tx, s.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE seasons SET closed_at = now() WHERE id = $1`, s.ID); err != nil {
+		return nil, err
+	}
+	return winners, tx.Commit()
+}
+
+// awardPrizes gives every prize of the season to the users ranked within its
+// top_n in their region. Users tied on the last place all win.
+func awardPrizes(ctx context.Context, tx *sqlx.Tx, seasonID int) ([]int64, error) {
+	var prizes []models.SeasonPrize
+	err := tx.SelectContext(ctx, &prizes, `SELECT id, season_id, top_n, xp, badge FROM season_prizes
+				WHERE season_id = $1`, seasonID)
+	if err != nil {
+		return nil, err
+	}
+
+	var awarded []int64
+	for _, prize := range prizes {
+		if prize.Badge != "" {
+			_, err := tx.ExecContext(ctx, `INSERT INTO user_badges (user_id, season_id, badge, region, rank)
+						SELECT user_id, season_id, $2, region, region_rank FROM season_standings
+						WHERE season_id = $1 AND region_rank <= $3
+						ON CONFLICT (user_id, season_id, badge) DO NOTHING`,
+				seasonID, prize.Badge, prize.TopN)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if prize.XP == 0 {
+			continue
+		}
+		var winners []int64
+		err := tx.SelectContext(ctx, &winners, `SELECT user_id FROM season_standings
+					WHERE season_id = $1 AND region_rank <= $2`, seasonID, prize.TopN)
+		if err != nil {
+			return nil, err
+		}
+		for _, userID := range winners {
+			err := xp.Apply(ctx, tx, userID, prize.XP, xp.SourceSeasonPrize, strconv.Itoa(seasonID))
+			if err != nil {
+				return nil, err
+			}
+		}
+		awarded = append(awarded, winners...)
+	}
+	return awarded, nil
+}
+
+// SyncUsers copies the current XP of users into the sorted set.
+func (l *Leaderboard) SyncUsers(ctx context.Context, userIDs []int64) {
+	var entries []Entry
+	err := l.db.SelectContext(ctx, &entries, `SELECT id, xp FROM users WHERE id = ANY($1)`, pq.Array(userIDs))
+	if err != nil {
+		log.Printf("Error fetching XP for leaderboard: %v", err)
+		l.synced.Store(false)
+		return
+	}
+	for _, e := range entries {
+		l.Set(ctx, e.UserID, e.XP)
+	}
+}
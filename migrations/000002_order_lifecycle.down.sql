@@ -0,0 +1,12 @@
+DROP INDEX IF EXISTS orders_status_idx;
+
+DROP INDEX IF EXISTS orders_user_id_idx;
+
+ALTER TABLE orders
+    DROP COLUMN IF EXISTS updated_at,
+    DROP COLUMN IF EXISTS handled_by,
+    DROP COLUMN IF EXISTS pickup_code,
+    DROP COLUMN IF EXISTS xp_spent,
+    DROP COLUMN IF EXISTS status;
+
+ALTER TABLE users DROP COLUMN IF EXISTS role;
@@ -0,0 +1,21 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS role VARCHAR(20) NOT NULL DEFAULT 'user'
+    CHECK (role IN ('user', 'officer', 'admin'));
+
+CREATE TABLE IF NOT EXISTS orders (
+    id SERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id),
+    item_id INT NOT NULL REFERENCES market(id),
+    order_number INT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE orders
+    ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'ready_for_pickup', 'fulfilled', 'cancelled', 'refunded')),
+    ADD COLUMN IF NOT EXISTS xp_spent BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS pickup_code VARCHAR(6) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS handled_by BIGINT REFERENCES users(id),
+    ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS orders_user_id_idx ON orders (user_id);
+CREATE INDEX IF NOT EXISTS orders_status_idx ON orders (status);
@@ -0,0 +1,12 @@
+DROP INDEX IF EXISTS market_xp_id_idx;
+DROP INDEX IF EXISTS market_category_name_idx;
+
+DROP INDEX IF EXISTS history_start_date_id_idx;
+DROP INDEX IF EXISTS history_event_id_idx;
+DROP INDEX IF EXISTS history_user_id_idx;
+
+DROP INDEX IF EXISTS events_end_date_id_idx;
+DROP INDEX IF EXISTS events_start_date_id_idx;
+
+DROP INDEX IF EXISTS users_region_idx;
+DROP INDEX IF EXISTS users_xp_id_idx;
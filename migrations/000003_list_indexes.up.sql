@@ -0,0 +1,16 @@
+ALTER TABLE events ADD COLUMN IF NOT EXISTS location TEXT;
+
+ALTER TABLE market ADD COLUMN IF NOT EXISTS image_url TEXT;
+
+CREATE INDEX IF NOT EXISTS users_xp_id_idx ON users (xp DESC, id);
+CREATE INDEX IF NOT EXISTS users_region_idx ON users (region);
+
+CREATE INDEX IF NOT EXISTS events_start_date_id_idx ON events (start_date, id);
+CREATE INDEX IF NOT EXISTS events_end_date_id_idx ON events (end_date, id);
+
+CREATE INDEX IF NOT EXISTS history_user_id_idx ON history (user_id);
+CREATE INDEX IF NOT EXISTS history_event_id_idx ON history (event_id);
+CREATE INDEX IF NOT EXISTS history_start_date_id_idx ON history (start_date, id);
+
+CREATE INDEX IF NOT EXISTS market_category_name_idx ON market (category_name);
+CREATE INDEX IF NOT EXISTS market_xp_id_idx ON market (xp, id);
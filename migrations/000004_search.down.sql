@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS users_name_trgm_idx;
+DROP INDEX IF EXISTS market_name_trgm_idx;
+DROP INDEX IF EXISTS events_name_trgm_idx;
+
+DROP INDEX IF EXISTS users_search_idx;
+DROP INDEX IF EXISTS market_search_idx;
+DROP INDEX IF EXISTS events_search_idx;
+
+ALTER TABLE users DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE market DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE events DROP COLUMN IF EXISTS search_vector;
@@ -0,0 +1,29 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- 'simple' keeps Uzbek words as they are, 'russian' adds stemmed Russian forms.
+ALTER TABLE events ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('simple', coalesce(name, '')), 'A') ||
+    setweight(to_tsvector('russian', coalesce(name, '')), 'A') ||
+    setweight(to_tsvector('simple', coalesce(description, '')), 'B') ||
+    setweight(to_tsvector('russian', coalesce(description, '')), 'B')
+) STORED;
+
+ALTER TABLE market ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('simple', coalesce(name, '')), 'A') ||
+    setweight(to_tsvector('russian', coalesce(name, '')), 'A') ||
+    setweight(to_tsvector('simple', coalesce(category_name, '')), 'B') ||
+    setweight(to_tsvector('simple', coalesce(description, '')), 'C') ||
+    setweight(to_tsvector('russian', coalesce(description, '')), 'C')
+) STORED;
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+    to_tsvector('simple', coalesce(first_name, '') || ' ' || coalesce(last_name, ''))
+) STORED;
+
+CREATE INDEX IF NOT EXISTS events_search_idx ON events USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS market_search_idx ON market USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS users_search_idx ON users USING GIN (search_vector);
+
+CREATE INDEX IF NOT EXISTS events_name_trgm_idx ON events USING GIN (name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS market_name_trgm_idx ON market USING GIN (name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS users_name_trgm_idx ON users USING GIN ((first_name || ' ' || last_name) gin_trgm_ops);
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS user_badges;
+DROP TABLE IF EXISTS season_standings;
+DROP TABLE IF EXISTS season_prizes;
+DROP TABLE IF EXISTS seasons;
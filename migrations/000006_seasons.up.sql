@@ -0,0 +1,45 @@
+CREATE TABLE IF NOT EXISTS seasons (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    starts_at TIMESTAMPTZ NOT NULL,
+    ends_at TIMESTAMPTZ NOT NULL,
+    closed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CHECK (ends_at > starts_at)
+);
+
+CREATE INDEX IF NOT EXISTS seasons_ends_at_idx ON seasons (ends_at) WHERE closed_at IS NULL;
+
+-- Every prize goes to the top_n users of each region once the season closes.
+CREATE TABLE IF NOT EXISTS season_prizes (
+    id SERIAL PRIMARY KEY,
+    season_id INT NOT NULL REFERENCES seasons(id) ON DELETE CASCADE,
+    top_n INT NOT NULL CHECK (top_n > 0),
+    xp BIGINT NOT NULL DEFAULT 0 CHECK (xp >= 0),
+    badge VARCHAR(100) NOT NULL DEFAULT ''
+);
+
+-- Final standings of closed seasons. region is the user's region when the
+-- season closed.
+CREATE TABLE IF NOT EXISTS season_standings (
+    season_id INT NOT NULL REFERENCES seasons(id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    region TEXT,
+    xp BIGINT NOT NULL,
+    rank INT NOT NULL,
+    region_rank INT,
+    PRIMARY KEY (season_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS season_standings_rank_idx ON season_standings (season_id, xp DESC, user_id DESC);
+
+CREATE TABLE IF NOT EXISTS user_badges (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    season_id INT REFERENCES seasons(id) ON DELETE SET NULL,
+    badge VARCHAR(100) NOT NULL,
+    region TEXT,
+    rank INT,
+    awarded_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (user_id, season_id, badge)
+);
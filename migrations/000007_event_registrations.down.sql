@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS event_registrations;
+
+ALTER TABLE events
+    DROP COLUMN IF EXISTS registration_cutoff_minutes,
+    DROP COLUMN IF EXISTS capacity;
@@ -0,0 +1,19 @@
+-- capacity NULL means unlimited places. Registration closes
+-- registration_cutoff_minutes before start_date.
+ALTER TABLE events
+    ADD COLUMN IF NOT EXISTS capacity INT CHECK (capacity > 0),
+    ADD COLUMN IF NOT EXISTS registration_cutoff_minutes INT NOT NULL DEFAULT 60
+        CHECK (registration_cutoff_minutes >= 0);
+
+CREATE TABLE IF NOT EXISTS event_registrations (
+    id BIGSERIAL PRIMARY KEY,
+    event_id UUID NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('registered', 'waitlisted')),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (event_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS event_registrations_queue_idx ON event_registrations (event_id, status, id);
+CREATE INDEX IF NOT EXISTS event_registrations_user_id_idx ON event_registrations (user_id);
@@ -0,0 +1,7 @@
+ALTER TABLE events DROP COLUMN IF EXISTS prorate_xp;
+
+ALTER TABLE event_registrations
+    DROP COLUMN IF EXISTS checked_out_by,
+    DROP COLUMN IF EXISTS checked_out_at,
+    DROP COLUMN IF EXISTS checked_in_by,
+    DROP COLUMN IF EXISTS checked_in_at;
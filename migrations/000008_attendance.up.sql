@@ -0,0 +1,8 @@
+ALTER TABLE event_registrations
+    ADD COLUMN IF NOT EXISTS checked_in_at TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS checked_in_by BIGINT REFERENCES users(id),
+    ADD COLUMN IF NOT EXISTS checked_out_at TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS checked_out_by BIGINT REFERENCES users(id);
+
+-- prorate_xp credits total_xp in proportion to the time attended.
+ALTER TABLE events ADD COLUMN IF NOT EXISTS prorate_xp BOOLEAN NOT NULL DEFAULT false;
@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS checkin_flags;
+DROP TABLE IF EXISTS checkin_locations;
+
+ALTER TABLE event_registrations DROP COLUMN IF EXISTS checkin_method;
+
+ALTER TABLE events
+    DROP COLUMN IF EXISTS checkin_radius_m,
+    DROP COLUMN IF EXISTS longitude,
+    DROP COLUMN IF EXISTS latitude;
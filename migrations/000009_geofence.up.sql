@@ -0,0 +1,37 @@
+ALTER TABLE events
+    ADD COLUMN IF NOT EXISTS latitude DOUBLE PRECISION CHECK (latitude BETWEEN -90 AND 90),
+    ADD COLUMN IF NOT EXISTS longitude DOUBLE PRECISION CHECK (longitude BETWEEN -180 AND 180),
+    ADD COLUMN IF NOT EXISTS checkin_radius_m INT NOT NULL DEFAULT 200 CHECK (checkin_radius_m > 0);
+
+ALTER TABLE event_registrations
+    ADD COLUMN IF NOT EXISTS checkin_method VARCHAR(10) CHECK (checkin_method IN ('qr', 'geo'));
+
+-- Every self check-in attempt, kept to spot suspicious patterns.
+CREATE TABLE IF NOT EXISTS checkin_locations (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    event_id UUID REFERENCES events(id) ON DELETE CASCADE,
+    latitude DOUBLE PRECISION NOT NULL,
+    longitude DOUBLE PRECISION NOT NULL,
+    accuracy_m DOUBLE PRECISION,
+    device_id TEXT NOT NULL DEFAULT '',
+    accepted BOOLEAN NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS checkin_locations_user_id_idx ON checkin_locations (user_id, created_at);
+CREATE INDEX IF NOT EXISTS checkin_locations_device_id_idx ON checkin_locations (device_id, created_at) WHERE device_id <> '';
+
+CREATE TABLE IF NOT EXISTS checkin_flags (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    event_id UUID NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+    reason VARCHAR(30) NOT NULL CHECK (reason IN ('shared_device', 'impossible_travel')),
+    details TEXT NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'dismissed', 'confirmed')),
+    reviewed_by BIGINT REFERENCES users(id),
+    reviewed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS checkin_flags_status_idx ON checkin_flags (status, id);
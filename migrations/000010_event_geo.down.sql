@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS events_geog_idx;
+DROP INDEX IF EXISTS events_lat_lng_idx;
+
+ALTER TABLE events
+    DROP COLUMN IF EXISTS area,
+    DROP COLUMN IF EXISTS address;
@@ -0,0 +1,16 @@
+ALTER TABLE events
+    ADD COLUMN IF NOT EXISTS address TEXT,
+    -- GeoJSON Polygon of the area covered by the event, e.g. a park.
+    ADD COLUMN IF NOT EXISTS area JSONB;
+
+CREATE INDEX IF NOT EXISTS events_lat_lng_idx ON events (latitude, longitude);
+
+-- Nearby search uses PostGIS when the extension is installed.
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM pg_extension WHERE extname = 'postgis') THEN
+        EXECUTE 'CREATE INDEX IF NOT EXISTS events_geog_idx ON events
+                 USING GIST ((ST_SetSRID(ST_MakePoint(longitude, latitude), 4326)::geography))';
+    END IF;
+END
+$$;
@@ -0,0 +1,4 @@
+-- region is one of the regions of Uzbekistan, named as in the map data.
+ALTER TABLE events ADD COLUMN IF NOT EXISTS region TEXT;
+
+CREATE INDEX IF NOT EXISTS events_region_idx ON events (LOWER(TRIM(region)));
@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS events_series_occurrence_idx;
+
+ALTER TABLE events
+    DROP COLUMN IF EXISTS detached,
+    DROP COLUMN IF EXISTS occurrence_start,
+    DROP COLUMN IF EXISTS series_id;
+
+DROP TABLE IF EXISTS event_series;
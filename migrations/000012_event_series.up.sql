@@ -0,0 +1,44 @@
+-- A series is a recurring event. Its occurrences are expanded into events
+-- ahead of time so registration, check-in and history work on them as on
+-- any other event.
+CREATE TABLE IF NOT EXISTS event_series (
+    id UUID PRIMARY KEY,
+    name TEXT NOT NULL,
+    image TEXT,
+    description TEXT,
+    total_xp BIGINT NOT NULL DEFAULT 0,
+    resp_officer VARCHAR(255) NOT NULL,
+    resp_officer_image TEXT,
+    -- dtstart, exdates and until are wall clock times in timezone.
+    dtstart TIMESTAMP NOT NULL,
+    duration_minutes INT NOT NULL CHECK (duration_minutes > 0),
+    timezone TEXT NOT NULL DEFAULT 'Asia/Tashkent',
+    rrule TEXT NOT NULL,
+    exdates TIMESTAMP[] NOT NULL DEFAULT '{}',
+    -- until ends a series whose later occurrences were split off into
+    -- another series by an "all future" edit.
+    until TIMESTAMP,
+    capacity INT CHECK (capacity > 0),
+    registration_cutoff_minutes INT NOT NULL DEFAULT 60,
+    prorate_xp BOOLEAN NOT NULL DEFAULT FALSE,
+    latitude DOUBLE PRECISION,
+    longitude DOUBLE PRECISION,
+    checkin_radius_m INT NOT NULL DEFAULT 200,
+    location TEXT,
+    address TEXT,
+    area JSONB,
+    region TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE events
+    ADD COLUMN IF NOT EXISTS series_id UUID REFERENCES event_series(id) ON DELETE SET NULL,
+    -- occurrence_start is the start the series scheduled, kept when the
+    -- occurrence is moved.
+    ADD COLUMN IF NOT EXISTS occurrence_start TIMESTAMP,
+    -- detached occurrences were edited on their own and are left alone by
+    -- edits of the series.
+    ADD COLUMN IF NOT EXISTS detached BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE UNIQUE INDEX IF NOT EXISTS events_series_occurrence_idx ON events (series_id, occurrence_start);
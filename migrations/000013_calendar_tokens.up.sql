@@ -0,0 +1,3 @@
+-- calendar_token is the secret in the URL of a user's personal calendar
+-- feed. Resetting it revokes the old URL.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS calendar_token TEXT UNIQUE;
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS events_status_idx;
+
+ALTER TABLE events
+    DROP COLUMN IF EXISTS cancel_reason,
+    DROP COLUMN IF EXISTS cancelled_at,
+    DROP COLUMN IF EXISTS settled_at,
+    DROP COLUMN IF EXISTS status;
@@ -0,0 +1,16 @@
+-- Events move draft -> published -> ongoing -> completed, or to cancelled.
+-- Published events become ongoing and completed as their start and end pass.
+ALTER TABLE events
+    ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'published'
+        CHECK (status IN ('draft', 'published', 'ongoing', 'completed', 'cancelled')),
+    -- settled_at is when XP was credited to the participants still checked
+    -- in at completion.
+    ADD COLUMN IF NOT EXISTS settled_at TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS cancelled_at TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS cancel_reason TEXT;
+
+-- Events that have started are left ongoing so the scheduler completes them
+-- and settles any participant who never checked out.
+UPDATE events SET status = 'ongoing' WHERE start_date <= CURRENT_TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS events_status_idx ON events (status);
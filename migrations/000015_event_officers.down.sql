@@ -0,0 +1,28 @@
+ALTER TABLE events
+    ADD COLUMN IF NOT EXISTS resp_officer VARCHAR(255) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS resp_officer_image TEXT;
+
+ALTER TABLE event_series
+    ADD COLUMN IF NOT EXISTS resp_officer VARCHAR(255) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS resp_officer_image TEXT;
+
+UPDATE events e SET resp_officer = TRIM(u.first_name || ' ' || u.last_name), resp_officer_image = u.avatar
+FROM event_officers o JOIN users u ON u.id = o.user_id
+WHERE o.event_id = e.id AND o.role = 'lead';
+
+UPDATE event_series s SET resp_officer = TRIM(u.first_name || ' ' || u.last_name), resp_officer_image = u.avatar
+FROM series_officers o JOIN users u ON u.id = o.user_id
+WHERE o.series_id = s.id AND o.role = 'lead';
+
+UPDATE events e SET resp_officer = n.name, resp_officer_image = n.image
+FROM unassigned_officers n WHERE n.event_id = e.id;
+
+UPDATE event_series s SET resp_officer = n.name, resp_officer_image = n.image
+FROM unassigned_officers n WHERE n.series_id = s.id;
+
+ALTER TABLE events ALTER COLUMN resp_officer DROP DEFAULT;
+ALTER TABLE event_series ALTER COLUMN resp_officer DROP DEFAULT;
+
+DROP TABLE IF EXISTS unassigned_officers;
+DROP TABLE IF EXISTS series_officers;
+DROP TABLE IF EXISTS event_officers;
@@ -0,0 +1,78 @@
+-- Events are run by a lead officer with any number of assistants, all of
+-- them user accounts.
+CREATE TABLE IF NOT EXISTS event_officers (
+    event_id UUID NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role VARCHAR(20) NOT NULL CHECK (role IN ('lead', 'assistant')),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (event_id, user_id)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS event_officers_lead_idx ON event_officers (event_id) WHERE role = 'lead';
+CREATE INDEX IF NOT EXISTS event_officers_user_idx ON event_officers (user_id);
+
+-- The officers of a series are assigned to each of its occurrences.
+CREATE TABLE IF NOT EXISTS series_officers (
+    series_id UUID NOT NULL REFERENCES event_series(id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role VARCHAR(20) NOT NULL CHECK (role IN ('lead', 'assistant')),
+    PRIMARY KEY (series_id, user_id)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS series_officers_lead_idx ON series_officers (series_id) WHERE role = 'lead';
+
+-- resp_officer named the lead officer in free text. Anyone can choose their
+-- name, so names are only matched to existing officers and admins, when
+-- exactly one has that full name, and no one is given a role by it. Names
+-- matching no one are kept in unassigned_officers until an admin assigns
+-- the officers of the event or series.
+CREATE TABLE IF NOT EXISTS unassigned_officers (
+    event_id UUID REFERENCES events(id) ON DELETE CASCADE,
+    series_id UUID REFERENCES event_series(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    image TEXT,
+    CHECK ((event_id IS NULL) <> (series_id IS NULL))
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS unassigned_officers_event_idx ON unassigned_officers (event_id) WHERE event_id IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS unassigned_officers_series_idx ON unassigned_officers (series_id) WHERE series_id IS NOT NULL;
+
+CREATE TEMPORARY TABLE officer_names AS
+SELECT n.key, MIN(u.id) AS user_id
+FROM (SELECT LOWER(TRIM(resp_officer)) AS key FROM events
+      UNION
+      SELECT LOWER(TRIM(resp_officer)) FROM event_series) n
+JOIN users u ON LOWER(TRIM(u.first_name || ' ' || u.last_name)) = n.key AND u.role IN ('officer', 'admin')
+WHERE n.key <> ''
+GROUP BY n.key
+HAVING COUNT(*) = 1;
+
+INSERT INTO event_officers (event_id, user_id, role)
+SELECT e.id, n.user_id, 'lead'
+FROM events e JOIN officer_names n ON n.key = LOWER(TRIM(e.resp_officer))
+ON CONFLICT DO NOTHING;
+
+INSERT INTO series_officers (series_id, user_id, role)
+SELECT s.id, n.user_id, 'lead'
+FROM event_series s JOIN officer_names n ON n.key = LOWER(TRIM(s.resp_officer))
+ON CONFLICT DO NOTHING;
+
+INSERT INTO unassigned_officers (event_id, name, image)
+SELECT e.id, TRIM(e.resp_officer), e.resp_officer_image
+FROM events e
+WHERE TRIM(e.resp_officer) <> '' AND NOT EXISTS (SELECT 1 FROM event_officers o WHERE o.event_id = e.id);
+
+INSERT INTO unassigned_officers (series_id, name, image)
+SELECT s.id, TRIM(s.resp_officer), s.resp_officer_image
+FROM event_series s
+WHERE TRIM(s.resp_officer) <> '' AND NOT EXISTS (SELECT 1 FROM series_officers o WHERE o.series_id = s.id);
+
+ALTER TABLE events
+    DROP COLUMN IF EXISTS resp_officer,
+    DROP COLUMN IF EXISTS resp_officer_image;
+
+ALTER TABLE event_series
+    DROP COLUMN IF EXISTS resp_officer,
+    DROP COLUMN IF EXISTS resp_officer_image;
+
+DROP TABLE officer_names;
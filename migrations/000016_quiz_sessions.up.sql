@@ -0,0 +1,16 @@
+-- A quiz session holds a generated quiz with its answer key, which never
+-- leaves the server. It is graded once, when the user submits answers.
+CREATE TABLE IF NOT EXISTS quiz_sessions (
+    id UUID PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    difficulty VARCHAR(10) NOT NULL CHECK (difficulty IN ('EASY', 'MEDIUM', 'HARD')),
+    questions JSONB NOT NULL,
+    answer_key JSONB NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    submitted_at TIMESTAMP,
+    correct_count INT,
+    xp_earned BIGINT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS quiz_sessions_user_idx ON quiz_sessions (user_id, created_at);
@@ -0,0 +1,62 @@
+-- The question bank holds validated quiz questions, generated in the
+-- background or seeded here, so quizzes are assembled without calling a
+-- language model and keep working offline.
+CREATE TABLE IF NOT EXISTS questions (
+    id UUID PRIMARY KEY,
+    difficulty VARCHAR(10) NOT NULL CHECK (difficulty IN ('EASY', 'MEDIUM', 'HARD')),
+    topic VARCHAR(50) NOT NULL DEFAULT 'general',
+    language VARCHAR(10) NOT NULL DEFAULT 'uz',
+    -- source is the provider that generated the question, or seed.
+    source VARCHAR(50) NOT NULL,
+    question TEXT NOT NULL,
+    variants JSONB NOT NULL,
+    answer CHAR(1) NOT NULL CHECK (answer IN ('A', 'B', 'C', 'D')),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS questions_text_idx ON questions (language, lower(question));
+CREATE INDEX IF NOT EXISTS questions_difficulty_idx ON questions (difficulty, language, topic);
+
+-- question_views records when a user was last served a question, so quizzes
+-- prefer questions the user has not seen.
+CREATE TABLE IF NOT EXISTS question_views (
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    question_id UUID NOT NULL REFERENCES questions(id) ON DELETE CASCADE,
+    seen_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, question_id)
+);
+
+INSERT INTO questions (id, difficulty, question, variants, answer, source)
+SELECT md5(q)::uuid, d, q, v::jsonb, a, 'seed' FROM (VALUES
+    ('EASY', 'Qaysi gaz o''simliklar tomonidan fotosintez jarayonida yutiladi?', '[{"key": "A", "text": "Kislorod"}, {"key": "B", "text": "Karbonat angidrid"}, {"key": "C", "text": "Azot"}, {"key": "D", "text": "Vodorod"}]', 'B'),
+    ('EASY', 'Plastik shishani qayerga tashlash to''g''ri?', '[{"key": "A", "text": "Oddiy chiqindi qutisiga"}, {"key": "B", "text": "Daryoga"}, {"key": "C", "text": "Plastik uchun qayta ishlash qutisiga"}, {"key": "D", "text": "Yerga ko''mib yuborish kerak"}]', 'C'),
+    ('EASY', 'Quyidagilardan qaysi biri qayta tiklanadigan energiya manbai?', '[{"key": "A", "text": "Ko''mir"}, {"key": "B", "text": "Neft"}, {"key": "C", "text": "Tabiiy gaz"}, {"key": "D", "text": "Quyosh energiyasi"}]', 'D'),
+    ('EASY', 'Yerdagi chuchuk suvning eng katta qismi qayerda joylashgan?', '[{"key": "A", "text": "Muzliklar va qor qoplamlarida"}, {"key": "B", "text": "Daryolarda"}, {"key": "C", "text": "Ko''llarda"}, {"key": "D", "text": "Atmosferada"}]', 'A'),
+    ('EASY', 'Ekotizimda o''z ozuqasini o''zi hosil qiladigan organizmlar qanday ataladi?', '[{"key": "A", "text": "Iste''molchilar"}, {"key": "B", "text": "Produtsentlar"}, {"key": "C", "text": "Redutsentlar"}, {"key": "D", "text": "Yirtqichlar"}]', 'B'),
+    ('EASY', 'Qaysi harakat suvni tejashga yordam beradi?', '[{"key": "A", "text": "Tish yuvayotganda jo''mrakni yopish"}, {"key": "B", "text": "Mashinani har kuni yuvish"}, {"key": "C", "text": "Uzoq vaqt dush qabul qilish"}, {"key": "D", "text": "Oqayotgan jo''mrakni ta''mirlamaslik"}]', 'A'),
+    ('EASY', 'Orol dengizining qurishiga asosiy sabab nima?', '[{"key": "A", "text": "Zilzilalar"}, {"key": "B", "text": "Amudaryo va Sirdaryo suvlarining sug''orishga olinishi"}, {"key": "C", "text": "Vulqon otilishi"}, {"key": "D", "text": "Dengiz sathining ko''tarilishi"}]', 'B'),
+    ('EASY', 'Qaysi chiqindi kompost qilish uchun yaroqli?', '[{"key": "A", "text": "Shisha"}, {"key": "B", "text": "Batareya"}, {"key": "C", "text": "Meva va sabzavot qoldiqlari"}, {"key": "D", "text": "Plastik paket"}]', 'C'),
+    ('EASY', 'Ozon qatlami Yerni nimadan himoya qiladi?', '[{"key": "A", "text": "Meteoritlardan"}, {"key": "B", "text": "Shamoldan"}, {"key": "C", "text": "Ultrabinafsha nurlardan"}, {"key": "D", "text": "Yomg''irdan"}]', 'C'),
+    ('EASY', 'Daraxt ekish havo sifatiga qanday ta''sir qiladi?', '[{"key": "A", "text": "Havoni ifloslantiradi"}, {"key": "B", "text": "Hech qanday ta''sir qilmaydi"}, {"key": "C", "text": "Havodagi kislorodni kamaytiradi"}, {"key": "D", "text": "Havoni tozalaydi va kislorod ishlab chiqaradi"}]', 'D'),
+    ('MEDIUM', 'Inson faoliyati natijasida chiqariladigan qaysi gaz issiqxona effektiga eng katta hissa qo''shadi?', '[{"key": "A", "text": "Karbonat angidrid"}, {"key": "B", "text": "Geliy"}, {"key": "C", "text": "Argon"}, {"key": "D", "text": "Neon"}]', 'A'),
+    ('MEDIUM', 'Biologik xilma-xillik nima?', '[{"key": "A", "text": "Faqat o''simliklar soni"}, {"key": "B", "text": "Muayyan hududdagi tirik organizmlar turlarining xilma-xilligi"}, {"key": "C", "text": "Faqat hayvonlar soni"}, {"key": "D", "text": "Tuproq turlari soni"}]', 'B'),
+    ('MEDIUM', 'Kislotali yomg''irlarning asosiy sababi nima?', '[{"key": "A", "text": "Oltingugurt va azot oksidlarining havoga chiqishi"}, {"key": "B", "text": "Okean to''lqinlari"}, {"key": "C", "text": "O''rmonlarning o''sishi"}, {"key": "D", "text": "Quyosh faolligi"}]', 'A'),
+    ('MEDIUM', 'Alyuminiy bankani qayta ishlash yangisini ishlab chiqarishga nisbatan qancha energiya tejaydi?', '[{"key": "A", "text": "Taxminan 10%"}, {"key": "B", "text": "Taxminan 30%"}, {"key": "C", "text": "Taxminan 50%"}, {"key": "D", "text": "Taxminan 95%"}]', 'D'),
+    ('MEDIUM', 'Evtrofikatsiya nima?', '[{"key": "A", "text": "Suv havzalarining ozuqa moddalar bilan ortiqcha boyishi va suv o''tlarining ko''payishi"}, {"key": "B", "text": "Tuproq eroziyasi"}, {"key": "C", "text": "Muzliklarning erishi"}, {"key": "D", "text": "Cho''llanish"}]', 'A'),
+    ('MEDIUM', 'Qaysi organizmlar o''lik organik moddalarni parchalaydi?', '[{"key": "A", "text": "Produtsentlar"}, {"key": "B", "text": "Yirtqichlar"}, {"key": "C", "text": "Redutsentlar (zamburug''lar va bakteriyalar)"}, {"key": "D", "text": "O''txo''rlar"}]', 'C'),
+    ('MEDIUM', 'Metan gazining asosiy manbalaridan biri qaysi?', '[{"key": "A", "text": "Shamol elektr stansiyalari"}, {"key": "B", "text": "Chorvachilik va chiqindi poligonlari"}, {"key": "C", "text": "Quyosh panellari"}, {"key": "D", "text": "Gidroelektr stansiyalari"}]', 'B'),
+    ('MEDIUM', 'PM2.5 nimani bildiradi?', '[{"key": "A", "text": "Radioaktiv izotopni"}, {"key": "B", "text": "Suvdagi tuz miqdorini"}, {"key": "C", "text": "Diametri 2,5 mikrometrdan kichik havodagi zarrachalarni"}, {"key": "D", "text": "Ozon konsentratsiyasini"}]', 'C'),
+    ('MEDIUM', 'Cho''llanishga eng ko''p olib keladigan omil qaysi?', '[{"key": "A", "text": "Ortiqcha o''tlatish va o''rmonlarni kesish"}, {"key": "B", "text": "Yog''ingarchilikning ko''payishi"}, {"key": "C", "text": "Daraxt ekish"}, {"key": "D", "text": "Tuproqni mulchalash"}]', 'A'),
+    ('MEDIUM', 'Oziq zanjirida energiyaning qancha qismi odatda keyingi pog''onaga o''tadi?', '[{"key": "A", "text": "Taxminan 90%"}, {"key": "B", "text": "Taxminan 50%"}, {"key": "C", "text": "Taxminan 10%"}, {"key": "D", "text": "100%"}]', 'C'),
+    ('HARD', 'Parij kelishuvi global isishni sanoatlashuvdan oldingi darajaga nisbatan qanday chegarada ushlab turishni maqsad qiladi?', '[{"key": "A", "text": "2°C dan ancha past, imkon qadar 1,5°C"}, {"key": "B", "text": "3°C"}, {"key": "C", "text": "4°C"}, {"key": "D", "text": "5°C"}]', 'A'),
+    ('HARD', 'Azot aylanishida atmosfera azotini ammiakka aylantiruvchi jarayon qanday ataladi?', '[{"key": "A", "text": "Denitrifikatsiya"}, {"key": "B", "text": "Azot fiksatsiyasi"}, {"key": "C", "text": "Nitrifikatsiya"}, {"key": "D", "text": "Ammonifikatsiya"}]', 'B'),
+    ('HARD', 'Biomagnifikatsiya nima?', '[{"key": "A", "text": "Turlar sonining ortishi"}, {"key": "B", "text": "Hujayralar hajmining kattalashishi"}, {"key": "C", "text": "Zaharli moddalar konsentratsiyasining oziq zanjiri bo''ylab ortib borishi"}, {"key": "D", "text": "Populyatsiyaning tez o''sishi"}]', 'C'),
+    ('HARD', 'Suvning organik ifloslanish darajasini baholash uchun qaysi ko''rsatkich ishlatiladi?', '[{"key": "A", "text": "Biokimyoviy kislorod iste''moli (BKI)"}, {"key": "B", "text": "Suvning rangi"}, {"key": "C", "text": "Suvning zichligi"}, {"key": "D", "text": "Suv oqimining tezligi"}]', 'A'),
+    ('HARD', 'Monreal protokoli qaysi moddalarni bosqichma-bosqich taqiqlashga qaratilgan?', '[{"key": "A", "text": "Karbonat angidridni"}, {"key": "B", "text": "Metanni"}, {"key": "C", "text": "Og''ir metallarni"}, {"key": "D", "text": "Ozon qatlamini yemiruvchi xlorftoruglerodlarni"}]', 'D'),
+    ('HARD', 'Ekologiyada tayanch tur (keystone species) deganda nima tushuniladi?', '[{"key": "A", "text": "Eng ko''p sonli tur"}, {"key": "B", "text": "Ekotizimga o''z sonidan ancha katta ta''sir ko''rsatadigan tur"}, {"key": "C", "text": "Faqat o''simlik turi"}, {"key": "D", "text": "Yo''qolib ketgan tur"}]', 'B'),
+    ('HARD', 'Okeanlarning kislotalanishiga asosiy sabab nima?', '[{"key": "A", "text": "Okean suvining sovishi"}, {"key": "B", "text": "Neft to''kilishi"}, {"key": "C", "text": "Atmosferadagi karbonat angidridning suvda erishi"}, {"key": "D", "text": "Tuz miqdorining ortishi"}]', 'C'),
+    ('HARD', 'Orol bo''yidagi tuz-chang bo''ronlarining asosiy manbai qayer?', '[{"key": "A", "text": "Orolqum cho''li, ya''ni dengizning qurigan tubi"}, {"key": "B", "text": "Pomir tog''lari"}, {"key": "C", "text": "Farg''ona vodiysi"}, {"key": "D", "text": "Kaspiy dengizi"}]', 'A'),
+    ('HARD', 'Qaysi gazning 100 yillik global isish salohiyati karbonat angidridnikidan taxminan 25-30 baravar yuqori?', '[{"key": "A", "text": "Azot"}, {"key": "B", "text": "Kislorod"}, {"key": "C", "text": "Metan"}, {"key": "D", "text": "Argon"}]', 'C'),
+    ('HARD', 'Hayot sikli tahlili (LCA) nimani baholaydi?', '[{"key": "A", "text": "Faqat mahsulot narxini"}, {"key": "B", "text": "Mahsulotning xomashyodan chiqindigacha bo''lgan butun hayoti davomida atrof-muhitga ta''sirini"}, {"key": "C", "text": "Faqat ishlab chiqarish tezligini"}, {"key": "D", "text": "Korxona xodimlari sonini"}]', 'B')
+) AS seed (d, q, v, a)
+ON CONFLICT DO NOTHING;
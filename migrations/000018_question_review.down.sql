@@ -0,0 +1,15 @@
+DROP TABLE IF EXISTS question_audit;
+
+DROP INDEX IF EXISTS questions_status_idx;
+
+DELETE FROM questions WHERE status <> 'approved';
+ALTER TABLE questions
+    DROP COLUMN IF EXISTS updated_at,
+    DROP COLUMN IF EXISTS reviewed_at,
+    DROP COLUMN IF EXISTS reviewed_by,
+    DROP COLUMN IF EXISTS created_by,
+    DROP COLUMN IF EXISTS status;
+
+UPDATE users SET role = 'user' WHERE role = 'teacher';
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_role_check;
+ALTER TABLE users ADD CONSTRAINT users_role_check CHECK (role IN ('user', 'officer', 'admin'));
@@ -0,0 +1,31 @@
+-- Teachers write and review quiz questions.
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_role_check;
+ALTER TABLE users ADD CONSTRAINT users_role_check CHECK (role IN ('user', 'teacher', 'officer', 'admin'));
+
+-- Generated and imported questions wait for review before they are served.
+-- Questions already in the bank stay live.
+ALTER TABLE questions
+    ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'approved'
+        CHECK (status IN ('pending', 'approved', 'rejected')),
+    ADD COLUMN IF NOT EXISTS created_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS reviewed_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS reviewed_at TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP;
+ALTER TABLE questions ALTER COLUMN status SET DEFAULT 'pending';
+
+CREATE INDEX IF NOT EXISTS questions_status_idx ON questions (status, created_at);
+
+-- question_audit records every import, edit and review of a question with
+-- its state before and after.
+CREATE TABLE IF NOT EXISTS question_audit (
+    id BIGSERIAL PRIMARY KEY,
+    question_id UUID NOT NULL REFERENCES questions(id) ON DELETE CASCADE,
+    user_id BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    action VARCHAR(20) NOT NULL CHECK (action IN ('import', 'edit', 'approve', 'reject')),
+    before JSONB,
+    after JSONB NOT NULL,
+    note TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS question_audit_question_idx ON question_audit (question_id, created_at);
@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS quiz_sessions_event_submitted_idx;
+ALTER TABLE quiz_sessions DROP COLUMN IF EXISTS event_id;
+
+DROP INDEX IF EXISTS questions_event_idx;
+DELETE FROM questions WHERE event_id IS NOT NULL;
+ALTER TABLE questions DROP COLUMN IF EXISTS event_id;
+
+ALTER TABLE questions DROP CONSTRAINT IF EXISTS questions_topic_check;
+UPDATE questions SET topic = 'general' WHERE source = 'seed';
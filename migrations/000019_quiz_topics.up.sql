@@ -0,0 +1,50 @@
+-- Questions are filed under a topic of the taxonomy, or general.
+UPDATE questions SET topic = 'general'
+    WHERE topic NOT IN ('general', 'waste', 'water', 'air', 'climate', 'biodiversity', 'energy');
+ALTER TABLE questions ADD CONSTRAINT questions_topic_check
+    CHECK (topic IN ('general', 'waste', 'water', 'air', 'climate', 'biodiversity', 'energy'));
+
+UPDATE questions q SET topic = seed.topic FROM (VALUES
+    ('Qaysi gaz o''simliklar tomonidan fotosintez jarayonida yutiladi?', 'climate'),
+    ('Plastik shishani qayerga tashlash to''g''ri?', 'waste'),
+    ('Quyidagilardan qaysi biri qayta tiklanadigan energiya manbai?', 'energy'),
+    ('Yerdagi chuchuk suvning eng katta qismi qayerda joylashgan?', 'water'),
+    ('Ekotizimda o''z ozuqasini o''zi hosil qiladigan organizmlar qanday ataladi?', 'biodiversity'),
+    ('Qaysi harakat suvni tejashga yordam beradi?', 'water'),
+    ('Orol dengizining qurishiga asosiy sabab nima?', 'water'),
+    ('Qaysi chiqindi kompost qilish uchun yaroqli?', 'waste'),
+    ('Ozon qatlami Yerni nimadan himoya qiladi?', 'air'),
+    ('Daraxt ekish havo sifatiga qanday ta''sir qiladi?', 'air'),
+    ('Inson faoliyati natijasida chiqariladigan qaysi gaz issiqxona effektiga eng katta hissa qo''shadi?', 'climate'),
+    ('Biologik xilma-xillik nima?', 'biodiversity'),
+    ('Kislotali yomg''irlarning asosiy sababi nima?', 'air'),
+    ('Alyuminiy bankani qayta ishlash yangisini ishlab chiqarishga nisbatan qancha energiya tejaydi?', 'waste'),
+    ('Evtrofikatsiya nima?', 'water'),
+    ('Qaysi organizmlar o''lik organik moddalarni parchalaydi?', 'biodiversity'),
+    ('Metan gazining asosiy manbalaridan biri qaysi?', 'climate'),
+    ('PM2.5 nimani bildiradi?', 'air'),
+    ('Cho''llanishga eng ko''p olib keladigan omil qaysi?', 'biodiversity'),
+    ('Oziq zanjirida energiyaning qancha qismi odatda keyingi pog''onaga o''tadi?', 'biodiversity'),
+    ('Parij kelishuvi global isishni sanoatlashuvdan oldingi darajaga nisbatan qanday chegarada ushlab turishni maqsad qiladi?', 'climate'),
+    ('Azot aylanishida atmosfera azotini ammiakka aylantiruvchi jarayon qanday ataladi?', 'biodiversity'),
+    ('Biomagnifikatsiya nima?', 'biodiversity'),
+    ('Suvning organik ifloslanish darajasini baholash uchun qaysi ko''rsatkich ishlatiladi?', 'water'),
+    ('Monreal protokoli qaysi moddalarni bosqichma-bosqich taqiqlashga qaratilgan?', 'air'),
+    ('Ekologiyada tayanch tur (keystone species) deganda nima tushuniladi?', 'biodiversity'),
+    ('Okeanlarning kislotalanishiga asosiy sabab nima?', 'water'),
+    ('Orol bo''yidagi tuz-chang bo''ronlarining asosiy manbai qayer?', 'air'),
+    ('Qaysi gazning 100 yillik global isish salohiyati karbonat angidridnikidan taxminan 25-30 baravar yuqori?', 'climate'),
+    ('Hayot sikli tahlili (LCA) nimani baholaydi?', 'waste')
+) AS seed (question, topic)
+WHERE q.source = 'seed' AND q.question = seed.question;
+
+-- Event questions are generated from the description of an event and make
+-- up its post-event quiz. They are not drawn into other quizzes.
+ALTER TABLE questions ADD COLUMN IF NOT EXISTS event_id UUID REFERENCES events(id) ON DELETE CASCADE;
+CREATE INDEX IF NOT EXISTS questions_event_idx ON questions (event_id) WHERE event_id IS NOT NULL;
+
+-- A session with an event is the post-event quiz of that event, submitted
+-- once per user.
+ALTER TABLE quiz_sessions ADD COLUMN IF NOT EXISTS event_id UUID REFERENCES events(id) ON DELETE CASCADE;
+CREATE UNIQUE INDEX IF NOT EXISTS quiz_sessions_event_submitted_idx ON quiz_sessions (user_id, event_id)
+    WHERE event_id IS NOT NULL AND submitted_at IS NOT NULL;
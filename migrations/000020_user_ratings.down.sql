@@ -0,0 +1,2 @@
+ALTER TABLE quiz_sessions DROP COLUMN IF EXISTS question_ids;
+DROP TABLE IF EXISTS user_ratings;
@@ -0,0 +1,14 @@
+-- Elo ratings of users' skill per topic, and over all topics under
+-- 'general', updated with every answered question.
+CREATE TABLE IF NOT EXISTS user_ratings (
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    topic VARCHAR(50) NOT NULL,
+    rating DOUBLE PRECISION NOT NULL DEFAULT 1200,
+    answered INTEGER NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, topic)
+);
+
+-- The bank questions of a session in the order they are numbered, to rate
+-- the user with their topics and difficulties once graded.
+ALTER TABLE quiz_sessions ADD COLUMN IF NOT EXISTS question_ids UUID[];
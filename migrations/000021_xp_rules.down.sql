@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS xp_ledger_source_idx;
+ALTER TABLE xp_ledger DROP COLUMN IF EXISTS rule_set_id;
+ALTER TABLE xp_ledger DROP COLUMN IF EXISTS base;
+DROP TABLE IF EXISTS xp_rule_sets;
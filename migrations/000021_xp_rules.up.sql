@@ -0,0 +1,21 @@
+-- Rule sets decide the XP earned. At most one is active; without one the
+-- rules file, or the built-in rules, apply.
+CREATE TABLE IF NOT EXISTS xp_rule_sets (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    rules JSONB NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT false,
+    created_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    activated_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    activated_at TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS xp_rule_sets_active_idx ON xp_rule_sets (active) WHERE active;
+
+-- base is the XP earned before multipliers and caps, rule_set_id the rule
+-- set in force. Both are null for XP not subject to rules and for XP earned
+-- before rule sets.
+ALTER TABLE xp_ledger ADD COLUMN IF NOT EXISTS base DOUBLE PRECISION;
+ALTER TABLE xp_ledger ADD COLUMN IF NOT EXISTS rule_set_id INT REFERENCES xp_rule_sets(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS xp_ledger_source_idx ON xp_ledger (user_id, source, created_at);
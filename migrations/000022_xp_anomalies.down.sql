@@ -0,0 +1,4 @@
+ALTER TABLE xp_ledger DROP COLUMN IF EXISTS reversed_at;
+DROP TABLE IF EXISTS xp_flags;
+ALTER TABLE users DROP COLUMN IF EXISTS frozen_by;
+ALTER TABLE users DROP COLUMN IF EXISTS frozen_at;
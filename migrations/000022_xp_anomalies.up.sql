@@ -0,0 +1,27 @@
+-- Frozen accounts earn no XP and cannot order from the market.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS frozen_at TIMESTAMPTZ;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS frozen_by BIGINT REFERENCES users(id) ON DELETE SET NULL;
+
+-- Accounts flagged by the anomaly detector for admin review. A flag covers
+-- the XP earned from started_at to ended_at, from the quiz session ref for
+-- fast quizzes. Each account is flagged once per reason and ref.
+CREATE TABLE IF NOT EXISTS xp_flags (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    reason VARCHAR(30) NOT NULL CHECK (reason IN ('earning_rate', 'fast_quiz')),
+    ref TEXT NOT NULL DEFAULT '',
+    details TEXT NOT NULL DEFAULT '',
+    started_at TIMESTAMPTZ NOT NULL,
+    ended_at TIMESTAMPTZ NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'dismissed', 'confirmed')),
+    reversed_xp BIGINT NOT NULL DEFAULT 0,
+    reviewed_by BIGINT REFERENCES users(id),
+    reviewed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (user_id, reason, ref)
+);
+
+CREATE INDEX IF NOT EXISTS xp_flags_status_idx ON xp_flags (status, id);
+
+-- Earned XP taken back by a reversal, so it is reversed once.
+ALTER TABLE xp_ledger ADD COLUMN IF NOT EXISTS reversed_at TIMESTAMPTZ;
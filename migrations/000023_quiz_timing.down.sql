@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS quiz_answers;
+ALTER TABLE quiz_sessions DROP COLUMN IF EXISTS timed;
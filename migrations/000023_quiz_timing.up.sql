@@ -0,0 +1,20 @@
+-- Timed sessions serve their questions one at a time. A question answered
+-- later than its time limit after it was served counts wrong.
+ALTER TABLE quiz_sessions ADD COLUMN IF NOT EXISTS timed BOOLEAN NOT NULL DEFAULT false;
+
+-- The questions of timed sessions with when they were served and answered.
+-- correct is set when the session is graded.
+CREATE TABLE IF NOT EXISTS quiz_answers (
+    session_id UUID NOT NULL REFERENCES quiz_sessions(id) ON DELETE CASCADE,
+    number INT NOT NULL,
+    question_id UUID REFERENCES questions(id) ON DELETE SET NULL,
+    time_limit INTERVAL NOT NULL,
+    served_at TIMESTAMP,
+    answer CHAR(1),
+    answered_at TIMESTAMP,
+    correct BOOLEAN,
+    PRIMARY KEY (session_id, number)
+);
+
+CREATE INDEX IF NOT EXISTS quiz_answers_question_idx ON quiz_answers (question_id)
+    WHERE served_at IS NOT NULL AND correct IS NOT NULL;
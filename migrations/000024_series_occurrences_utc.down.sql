@@ -0,0 +1,5 @@
+UPDATE events e SET
+    start_date = (e.start_date AT TIME ZONE 'UTC') AT TIME ZONE s.timezone,
+    end_date = (e.end_date AT TIME ZONE 'UTC') AT TIME ZONE s.timezone
+FROM event_series s
+WHERE s.id = e.series_id;
@@ -0,0 +1,8 @@
+-- Occurrences of series were stored as wall clock times in the series' time
+-- zone. They are stored in UTC, the zone of database sessions, so they
+-- compare with CURRENT_TIMESTAMP.
+UPDATE events e SET
+    start_date = (e.start_date AT TIME ZONE s.timezone) AT TIME ZONE 'UTC',
+    end_date = (e.end_date AT TIME ZONE s.timezone) AT TIME ZONE 'UTC'
+FROM event_series s
+WHERE s.id = e.series_id;
@@ -1,16 +1,222 @@
 package models
 
+import (
+	"errors"
+	"fmt"
+	"time"
+	"worker-bot/geo"
+)
+
 type Event struct {
-	ID               string `db:"id" json:"id"`
-	Name             string `db:"name" json:"name"`
-	Image            string `db:"image" json:"image"`
-	Description      string `db:"description" json:"description"`
-	TotalXP          int    `db:"total_xp" json:"total_xp"`
-	StartDate        string `db:"start_date" json:"start_date"`
-	EndDate          string `db:"end_date" json:"end_date"`
-	RespOfficer      string `db:"resp_officer" json:"resp_officer"`
-	RespOfficerImage string `db:"resp_officer_image" json:"resp_officer_image"`
-	CreatedAt        string `db:"created_at" json:"created_at"`
-	UpdatedAt        string `db:"updated_at" json:"updated_at"`
-	Location         string `json:"location"`
+	ID          string `db:"id" json:"id"`
+	Name        string `db:"name" json:"name"`
+	Image       string `db:"image" json:"image"`
+	Description string `db:"description" json:"description"`
+	TotalXP     int    `db:"total_xp" json:"total_xp"`
+	StartDate   string `db:"start_date" json:"start_date"`
+	EndDate     string `db:"end_date" json:"end_date"`
+	CreatedAt   string `db:"created_at" json:"created_at"`
+	UpdatedAt   string `db:"updated_at" json:"updated_at"`
+	Location    string `db:"location" json:"location"`
+	// Officers are the lead officer, first, and the assistants. An event is
+	// created with a lead.
+	Officers []EventOfficer `db:"-" json:"officers"`
+	// Capacity is the number of places, nil for unlimited.
+	Capacity *int `db:"capacity" json:"capacity"`
+	// RegistrationCutoffMinutes closes registration this long before
+	// StartDate. It defaults to DefaultRegistrationCutoff.
+	RegistrationCutoffMinutes *int `db:"registration_cutoff_minutes" json:"registration_cutoff_minutes"`
+	// ProrateXP credits TotalXP in proportion to the time attended.
+	ProrateXP bool     `db:"prorate_xp" json:"prorate_xp"`
+	Latitude  *float64 `db:"latitude" json:"latitude"`
+	Longitude *float64 `db:"longitude" json:"longitude"`
+	// CheckinRadiusM is how close to the coordinates participants must be
+	// to check themselves in. It defaults to DefaultCheckinRadius.
+	CheckinRadiusM *int         `db:"checkin_radius_m" json:"checkin_radius_m"`
+	Address        string       `db:"address" json:"address"`
+	Area           *geo.Polygon `db:"area" json:"area"`
+	Region         *string      `db:"region" json:"region"`
+	// SeriesID is set on the occurrences of a recurring event.
+	SeriesID *string `db:"series_id" json:"series_id"`
+	// Status is the lifecycle status, one of the Event* constants. New
+	// events are published unless created as drafts.
+	Status string `db:"status" json:"status"`
+}
+
+// ValidatePlace checks the coordinates and area of an event.
+func (e *Event) ValidatePlace() error {
+	if (e.Latitude == nil) != (e.Longitude == nil) {
+		return errors.New("latitude and longitude must be set together")
+	}
+	if e.Latitude != nil && !geo.ValidPoint(*e.Latitude, *e.Longitude) {
+		return errors.New("invalid coordinates")
+	}
+	if e.Area != nil {
+		return e.Area.Validate()
+	}
+	return nil
+}
+
+// NearbyEvent is an event with its distance from a point.
+type NearbyEvent struct {
+	Event
+	DistanceM float64 `db:"distance_m" json:"distance_m"`
+}
+
+const (
+	DefaultRegistrationCutoff = 60
+	DefaultCheckinRadius      = 200
+)
+
+const (
+	EventDraft     = "draft"
+	EventPublished = "published"
+	EventOngoing   = "ongoing"
+	EventCompleted = "completed"
+	EventCancelled = "cancelled"
+)
+
+const (
+	OfficerLead      = "lead"
+	OfficerAssistant = "assistant"
+)
+
+// EventOfficer is an officer running an event. Only UserID and Role are read
+// when officers are assigned, the rest comes from the user.
+type EventOfficer struct {
+	EventID     string `db:"event_id" json:"-"`
+	UserID      int64  `db:"user_id" json:"user_id"`
+	Role        string `db:"role" json:"role"`
+	FirstName   string `db:"first_name" json:"first_name"`
+	LastName    string `db:"last_name" json:"last_name"`
+	Avatar      string `db:"avatar" json:"avatar"`
+	PhoneNumber string `db:"phone_number" json:"phone_number"`
+}
+
+// UnassignedOfficer is the officer named by an event or series before
+// officers were accounts, that no account was assigned for yet.
+type UnassignedOfficer struct {
+	EventID  *string `db:"event_id" json:"event_id"`
+	SeriesID *string `db:"series_id" json:"series_id"`
+	// Title is the name of the event or series.
+	Title string `db:"title" json:"title"`
+	Name  string `db:"name" json:"name"`
+	Image string `db:"image" json:"image"`
+}
+
+// ValidateOfficers checks that officers have one lead, assistants otherwise,
+// and name nobody twice.
+func ValidateOfficers(officers []EventOfficer) error {
+	leads := 0
+	seen := make(map[int64]bool, len(officers))
+	for _, o := range officers {
+		switch o.Role {
+		case OfficerLead:
+			leads++
+		case OfficerAssistant:
+		default:
+			return errors.New("officer role must be one of lead, assistant")
+		}
+		if seen[o.UserID] {
+			return fmt.Errorf("user %d is assigned twice", o.UserID)
+		}
+		seen[o.UserID] = true
+	}
+	if leads != 1 {
+		return errors.New("an event must have exactly one lead officer")
+	}
+	return nil
+}
+
+// EventCancellation is the optional reason sent to registrants.
+type EventCancellation struct {
+	Reason string `json:"reason"`
+}
+
+const (
+	RegistrationRegistered = "registered"
+	RegistrationWaitlisted = "waitlisted"
+)
+
+type EventRegistration struct {
+	EventID   string `db:"event_id" json:"event_id"`
+	EventName string `db:"event_name" json:"event_name,omitempty"`
+	UserID    int64  `db:"user_id" json:"user_id"`
+	Status    string `db:"status" json:"status"`
+	// Position is the place in the waitlist, starting at 1.
+	Position  *int      `db:"position" json:"position,omitempty"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+type Participant struct {
+	UserID       int64      `db:"user_id" json:"user_id"`
+	FirstName    string     `db:"first_name" json:"first_name"`
+	LastName     string     `db:"last_name" json:"last_name"`
+	PhoneNumber  string     `db:"phone_number" json:"phone_number"`
+	Status       string     `db:"status" json:"status"`
+	Position     *int       `db:"position" json:"position,omitempty"`
+	CreatedAt    time.Time  `db:"created_at" json:"registered_at"`
+	CheckedInAt  *time.Time `db:"checked_in_at" json:"checked_in_at"`
+	CheckedOutAt *time.Time `db:"checked_out_at" json:"checked_out_at"`
+}
+
+type TicketScan struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type Ticket struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type LocationCheckIn struct {
+	Latitude  *float64 `json:"latitude" binding:"required"`
+	Longitude *float64 `json:"longitude" binding:"required"`
+	// Accuracy is the horizontal accuracy in meters reported by the device.
+	Accuracy float64 `json:"accuracy"`
+	DeviceID string  `json:"device_id"`
+}
+
+const (
+	FlagOpen      = "open"
+	FlagDismissed = "dismissed"
+	FlagConfirmed = "confirmed"
+)
+
+type CheckinFlag struct {
+	ID         int64      `db:"id" json:"id"`
+	UserID     int64      `db:"user_id" json:"user_id"`
+	UserName   string     `db:"user_name" json:"user_name"`
+	EventID    string     `db:"event_id" json:"event_id"`
+	EventName  string     `db:"event_name" json:"event_name"`
+	Reason     string     `db:"reason" json:"reason"`
+	Details    string     `db:"details" json:"details"`
+	Status     string     `db:"status" json:"status"`
+	ReviewedBy *int64     `db:"reviewed_by" json:"reviewed_by"`
+	ReviewedAt *time.Time `db:"reviewed_at" json:"reviewed_at"`
+	CreatedAt  time.Time  `db:"created_at" json:"created_at"`
+}
+
+type FlagReview struct {
+	Status string `json:"status" binding:"required"`
+}
+
+type Attendance struct {
+	EventID      string     `db:"event_id" json:"event_id"`
+	UserID       int64      `db:"user_id" json:"user_id"`
+	CheckedInAt  *time.Time `db:"checked_in_at" json:"checked_in_at"`
+	CheckedOutAt *time.Time `db:"checked_out_at" json:"checked_out_at"`
+	XPEarned     int64      `db:"xp_earned" json:"xp_earned"`
+}
+
+// CalendarFeed is the URL of a personal iCalendar feed. Calendar apps
+// subscribe to WebcalURL.
+type CalendarFeed struct {
+	URL       string `json:"url"`
+	WebcalURL string `json:"webcal_url"`
+}
+
+type EventStatus struct {
+	ID     string `db:"id" json:"id"`
+	Status string `db:"status" json:"status"`
 }
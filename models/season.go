@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+type Season struct {
+	ID       int           `db:"id" json:"id"`
+	Name     string        `db:"name" json:"name" binding:"required"`
+	StartsAt time.Time     `db:"starts_at" json:"starts_at" binding:"required"`
+	EndsAt   time.Time     `db:"ends_at" json:"ends_at" binding:"required"`
+	ClosedAt *time.Time    `db:"closed_at" json:"closed_at"`
+	Prizes   []SeasonPrize `db:"-" json:"prizes"`
+}
+
+// SeasonPrize is awarded to the TopN users of every region when the season
+// closes. It grants XP, a badge or both.
+type SeasonPrize struct {
+	ID       int    `db:"id" json:"id"`
+	SeasonID int    `db:"season_id" json:"season_id"`
+	TopN     int    `db:"top_n" json:"top_n" binding:"required,min=1"`
+	XP       int64  `db:"xp" json:"xp" binding:"min=0"`
+	Badge    string `db:"badge" json:"badge"`
+}
+
+type Badge struct {
+	ID        int64     `db:"id" json:"id"`
+	UserID    int64     `db:"user_id" json:"user_id"`
+	SeasonID  *int      `db:"season_id" json:"season_id"`
+	Badge     string    `db:"badge" json:"badge"`
+	Region    *string   `db:"region" json:"region"`
+	Rank      *int      `db:"rank" json:"rank"`
+	AwardedAt time.Time `db:"awarded_at" json:"awarded_at"`
+}
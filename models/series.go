@@ -0,0 +1,47 @@
+package models
+
+import (
+	"time"
+	"worker-bot/geo"
+)
+
+const DefaultTimezone = "Asia/Tashkent"
+
+// EventSeries is a recurring event, e.g. a weekly park clean-up. Its
+// occurrences are events with SeriesID set.
+type EventSeries struct {
+	ID          string `db:"id" json:"id"`
+	Name        string `db:"name" json:"name" binding:"required"`
+	Image       string `db:"image" json:"image"`
+	Description string `db:"description" json:"description"`
+	TotalXP     int    `db:"total_xp" json:"total_xp"`
+	// Officers are assigned to every occurrence.
+	Officers []EventOfficer `db:"-" json:"officers"`
+	// Start is the start of the first occurrence. Every occurrence starts
+	// at the same wall clock time in Timezone.
+	Start           time.Time `db:"dtstart" json:"start" binding:"required"`
+	DurationMinutes int       `db:"duration_minutes" json:"duration_minutes" binding:"required,min=1"`
+	// Timezone is an IANA time zone, DefaultTimezone if empty.
+	Timezone string `db:"timezone" json:"timezone"`
+	// RRule is an iCalendar recurrence rule, e.g. FREQ=WEEKLY;BYDAY=SA.
+	RRule string `db:"rrule" json:"rrule" binding:"required"`
+	// Exdates are the scheduled starts of cancelled occurrences.
+	Exdates []time.Time `db:"-" json:"exdates"`
+	// Until is set once later occurrences were split off into another
+	// series by an edit of all future occurrences.
+	Until                     *time.Time   `db:"until" json:"until"`
+	Capacity                  *int         `db:"capacity" json:"capacity"`
+	RegistrationCutoffMinutes *int         `db:"registration_cutoff_minutes" json:"registration_cutoff_minutes"`
+	ProrateXP                 bool         `db:"prorate_xp" json:"prorate_xp"`
+	Latitude                  *float64     `db:"latitude" json:"latitude"`
+	Longitude                 *float64     `db:"longitude" json:"longitude"`
+	CheckinRadiusM            *int         `db:"checkin_radius_m" json:"checkin_radius_m"`
+	Location                  string       `db:"location" json:"location"`
+	Address                   string       `db:"address" json:"address"`
+	Area                      *geo.Polygon `db:"area" json:"area"`
+	Region                    *string      `db:"region" json:"region"`
+	CreatedAt                 time.Time    `db:"created_at" json:"created_at"`
+	UpdatedAt                 time.Time    `db:"updated_at" json:"updated_at"`
+	// Occurrences are the events of the series that have not ended.
+	Occurrences []Event `db:"-" json:"occurrences"`
+}
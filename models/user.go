@@ -14,6 +14,19 @@ type User struct {
 	Location    string         `db:"location" json:"location"`
 	PhoneNumber string         `db:"phone_number" json:"phone_number"`
 	XP          int            `db:"xp" json:"xp"`
+	Role        string         `db:"role" json:"role,omitempty"`
+	Region      *string        `db:"region" json:"region"`
+	// FrozenAt is set on accounts frozen after an XP review.
+	FrozenAt *time.Time `db:"frozen_at" json:"frozen_at,omitempty"`
+}
+
+type Friend struct {
+	ID        int     `db:"id" json:"id"`
+	FirstName string  `db:"first_name" json:"first_name"`
+	LastName  string  `db:"last_name" json:"last_name"`
+	Avatar    string  `db:"avatar" json:"avatar"`
+	XP        int     `db:"xp" json:"xp"`
+	Region    *string `db:"region" json:"region"`
 }
 
 type RankingResponse struct {
@@ -37,20 +50,105 @@ type Market struct {
 	ImageUrl     string    `json:"image_url" db:"image_url"`
 }
 
+const (
+	RoleUser    = "user"
+	RoleTeacher = "teacher"
+	RoleOfficer = "officer"
+	RoleAdmin   = "admin"
+)
+
+// RoleUpdate is the role an admin gives a user.
+type RoleUpdate struct {
+	Role string `json:"role" binding:"required"`
+}
+
+const (
+	OrderPending        = "pending"
+	OrderReadyForPickup = "ready_for_pickup"
+	OrderFulfilled      = "fulfilled"
+	OrderCancelled      = "cancelled"
+	OrderRefunded       = "refunded"
+)
+
+// OrderTransitions lists the states an order may move to from each state.
+var OrderTransitions = map[string][]string{
+	OrderPending:        {OrderReadyForPickup, OrderCancelled},
+	OrderReadyForPickup: {OrderFulfilled, OrderCancelled},
+	OrderFulfilled:      {OrderRefunded},
+}
+
+// CanTransitionOrder reports whether an order in state from may move to state to.
+func CanTransitionOrder(from, to string) bool {
+	for _, next := range OrderTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
 type Order struct {
 	ID          int       `db:"id" json:"id"`
 	UserID      int       `db:"user_id" json:"user_id"`
 	ItemID      int       `db:"item_id" json:"item_id"`
+	ItemName    string    `db:"item_name" json:"item_name,omitempty"`
 	OrderNumber int       `db:"order_number" json:"order_number"`
+	Status      string    `db:"status" json:"status"`
+	XPSpent     int64     `db:"xp_spent" json:"xp_spent"`
+	PickupCode  string    `db:"pickup_code" json:"pickup_code,omitempty"`
+	HandledBy   *int64    `db:"handled_by" json:"handled_by,omitempty"`
 	CreatedAt   time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time `db:"updated_at" json:"updated_at"`
+}
+
+type OrderStatusUpdate struct {
+	Status string `json:"status" binding:"required"`
+}
+
+type PickupVerification struct {
+	PickupCode string `json:"pickup_code" binding:"required"`
 }
 
 type Message struct {
 	Message string `json:"message"`
 }
 
-type EarnXP struct {
-	Id           int    `json:"id"`
-	Difficulty   string `json:"difficulty"`
-	CorrectCount int    `json:"correct_count"`
+type QuizRequest struct {
+	Difficulty string `json:"difficulty" binding:"required"`
+	// Topic is a topic key from GET /quiz/topics, any topic when empty.
+	Topic string `json:"topic"`
+}
+
+// QuizAnswers are the chosen variants by question number, e.g. {"1": "A"}.
+type QuizAnswers struct {
+	Answers map[string]string `json:"answers" binding:"required"`
+}
+
+// QuizAnswer is the chosen variant of a question of a timed quiz.
+type QuizAnswer struct {
+	Answer string `json:"answer" binding:"required"`
+}
+
+// QuestionReview approves or rejects a bank question.
+type QuestionReview struct {
+	Status string `json:"status" binding:"required"`
+	Note   string `json:"note"`
+}
+
+// XPFlagReview dismisses or confirms an XP anomaly flag. Confirming it can
+// also freeze the account and reverse the XP the flag covers.
+type XPFlagReview struct {
+	Status  string `json:"status" binding:"required"`
+	Freeze  bool   `json:"freeze"`
+	Reverse bool   `json:"reverse"`
+}
+
+// SearchResult is a search match. Highlight is escaped HTML with the
+// matches in <b> tags.
+type SearchResult struct {
+	Type      string  `db:"type" json:"type"`
+	ID        string  `db:"id" json:"id"`
+	Title     string  `db:"title" json:"title"`
+	Highlight string  `db:"highlight" json:"highlight"`
+	Rank      float64 `db:"rank" json:"rank"`
 }
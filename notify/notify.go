@@ -0,0 +1,24 @@
+// Package notify sends messages to users outside of a request, e.g. when a
+// waitlisted user gets a place at an event.
+package notify
+
+import "gopkg.in/telebot.v3"
+
+// Notifier delivers a text message to a user.
+type Notifier interface {
+	Notify(userID int64, text string) error
+}
+
+// Telegram sends notifications as bot messages. User IDs are Telegram IDs.
+type Telegram struct {
+	bot *telebot.Bot
+}
+
+func NewTelegram(bot *telebot.Bot) *Telegram {
+	return &Telegram{bot: bot}
+}
+
+func (t *Telegram) Notify(userID int64, text string) error {
+	_, err := t.bot.Send(&telebot.User{ID: userID}, text)
+	return err
+}
@@ -0,0 +1,253 @@
+package quiz
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// Language is the language quizzes are served in.
+const Language = "uz"
+
+// DefaultTopic is the topic of questions not filed under a topic of the
+// taxonomy. Drawing or filling it means any topic.
+const DefaultTopic = "general"
+
+// fillTimeout bounds a background Fill, generation and translation
+// included.
+const fillTimeout = 5 * time.Minute
+
+var difficulties = []string{Easy, Medium, Hard}
+
+// Bank is the question bank quizzes are assembled from. It is topped up in the
+// background with generated questions, so serving a quiz never waits for a
+// language model or the translation service.
+type Bank struct {
+	db        *sqlx.DB
+	generator QuestionGenerator
+	translate Translator
+	// minSize is the number of questions per difficulty TopUp keeps.
+	minSize int
+
+	mu      sync.Mutex
+	filling map[string]bool
+}
+
+func NewBank(db *sqlx.DB, generator QuestionGenerator, translate Translator, minSize int) *Bank {
+	return &Bank{db: db, generator: generator, translate: translate, minSize: minSize, filling: map[string]bool{}}
+}
+
+// drawnQuestion is a question drawn from the bank for a user.
+type drawnQuestion struct {
+	ID       string   `db:"id"`
+	Question string   `db:"question"`
+	Variants Variants `db:"variants"`
+	Answer   string   `db:"answer"`
+	// Seen reports whether the user was served the question before.
+	Seen bool `db:"seen"`
+}
+
+// Draw assembles a quiz of up to count approved questions of a difficulty
+// and topic for a user, preferring questions the user has not seen and then
+// those seen longest ago. DefaultTopic draws from all topics. The quiz is
+// passed to MarkSeen once served. When the user has run out of unseen questions the bank is topped
+// up in the background.
+func (b *Bank) Draw(ctx context.Context, userID int64, difficulty, topic string, count int) (*Quiz, error) {
+	var rows []drawnQuestion
+	err := b.db.SelectContext(ctx, &rows, `SELECT q.id, q.question, q.variants, q.answer, v.seen_at IS NOT NULL AS seen
+				FROM questions q LEFT JOIN question_views v ON v.question_id = q.id AND v.user_id = $1
+				WHERE q.difficulty = $2 AND ($3 = 'general' OR q.topic = $3) AND q.language = $4
+					AND q.status = 'approved' AND q.event_id IS NULL
+				ORDER BY v.seen_at NULLS FIRST, random() LIMIT $5`, userID, difficulty, topic, Language, count)
+	if err != nil {
+		return nil, err
+	}
+
+	q := drawn(rows)
+	exhausted := len(rows) < count
+	for _, row := range rows {
+		exhausted = exhausted || row.Seen
+	}
+	if exhausted {
+		b.FillAsync(difficulty, topic)
+	}
+	return q, nil
+}
+
+// DrawEvent assembles the quiz of an event from up to count of its approved
+// questions.
+func (b *Bank) DrawEvent(ctx context.Context, eventID string, count int) (*Quiz, error) {
+	var rows []drawnQuestion
+	err := b.db.SelectContext(ctx, &rows, `SELECT id, question, variants, answer, false AS seen FROM questions
+				WHERE event_id = $1 AND status = 'approved' ORDER BY random() LIMIT $2`, eventID, count)
+	if err != nil {
+		return nil, err
+	}
+	return drawn(rows), nil
+}
+
+func drawn(rows []drawnQuestion) *Quiz {
+	q := &Quiz{Questions: make([]Question, len(rows))}
+	for i, row := range rows {
+		q.Questions[i] = Question{ID: row.ID, Question: row.Question, Variants: row.Variants, Answer: row.Answer}
+	}
+	return q
+}
+
+// MarkSeen records that a user was served the questions of a quiz.
+func (b *Bank) MarkSeen(ctx context.Context, userID int64, q *Quiz) error {
+	_, err := b.db.ExecContext(ctx, `INSERT INTO question_views (user_id, question_id)
+				SELECT $1, unnest($2::uuid[])
+				ON CONFLICT (user_id, question_id) DO UPDATE SET seen_at = CURRENT_TIMESTAMP`,
+		userID, pq.Array(q.IDs()))
+	return err
+}
+
+// Add stores the questions of a validated quiz generated for a request as
+// pending review, skipping questions already in the bank. eventID links
+// them to an event, nil for general questions. Add returns the number of
+// questions added.
+func (b *Bank) Add(ctx context.Context, q *Quiz, req Request, source string, eventID *string) (int, error) {
+	tx, err := b.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	added := 0
+	for _, question := range q.Questions {
+		res, err := tx.ExecContext(ctx, `INSERT INTO questions (id, difficulty, topic, language, source, status, question, variants, answer, event_id)
+					VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) ON CONFLICT DO NOTHING`,
+			uuid.NewString(), req.Difficulty, req.Topic, Language, source, StatusPending,
+			question.Question, question.Variants, question.Answer, eventID)
+		if err != nil {
+			return 0, err
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return 0, err
+		}
+		added += int(n)
+	}
+	return added, tx.Commit()
+}
+
+// Fill generates a quiz of a difficulty and topic, translates it and adds it
+// to the bank for review. DefaultTopic fills the topic with the fewest
+// questions. Nothing is generated while the difficulty already has the bank's
+// minimum number of questions waiting for review.
+func (b *Bank) Fill(ctx context.Context, difficulty, topic string) error {
+	var pending int
+	err := b.db.GetContext(ctx, &pending, `SELECT COUNT(*) FROM questions
+				WHERE difficulty = $1 AND language = $2 AND status = 'pending' AND event_id IS NULL`,
+		difficulty, Language)
+	if err != nil {
+		return err
+	}
+	if pending >= b.minSize {
+		return nil
+	}
+
+	if topic == DefaultTopic {
+		if topic, err = b.scarcestTopic(ctx, difficulty); err != nil {
+			return err
+		}
+	}
+	return b.generate(ctx, Request{Difficulty: difficulty, Topic: topic, Count: QuestionCount}, nil)
+}
+
+// scarcestTopic returns the topic of the taxonomy with the fewest approved or
+// pending questions of a difficulty.
+func (b *Bank) scarcestTopic(ctx context.Context, difficulty string) (string, error) {
+	keys := make([]string, len(Topics))
+	for i, t := range Topics {
+		keys[i] = t.Key
+	}
+	var topic string
+	err := b.db.GetContext(ctx, &topic, `SELECT t.topic FROM unnest($1::text[]) WITH ORDINALITY AS t (topic, n)
+				LEFT JOIN questions q ON q.topic = t.topic AND q.difficulty = $2 AND q.language = $3
+					AND q.status <> 'rejected' AND q.event_id IS NULL
+				GROUP BY t.topic, t.n ORDER BY COUNT(q.id), t.n LIMIT 1`, pq.Array(keys), difficulty, Language)
+	return topic, err
+}
+
+// generate generates and translates the quiz of a request and adds it to the
+// bank.
+func (b *Bank) generate(ctx context.Context, req Request, eventID *string) error {
+	q, err := b.generator.Generate(ctx, req)
+	if err != nil {
+		return err
+	}
+	source := q.Source
+	if source == "" {
+		source = b.generator.Name()
+	}
+	if err := q.Translate(ctx, b.translate); err != nil {
+		return err
+	}
+	// Translation can merge texts that differed in English.
+	if err := q.Validate(len(q.Questions)); err != nil {
+		return err
+	}
+	added, err := b.Add(ctx, q, req, source, eventID)
+	if err != nil {
+		return err
+	}
+	log.Printf("Added %d %s %s questions from %s to the question bank", added, req.Difficulty, req.Topic, source)
+	return nil
+}
+
+// FillAsync runs Fill in the background, unless it is already running for
+// the difficulty and topic.
+func (b *Bank) FillAsync(difficulty, topic string) {
+	key := difficulty + "/" + topic
+	b.mu.Lock()
+	if b.filling[key] {
+		b.mu.Unlock()
+		return
+	}
+	b.filling[key] = true
+	b.mu.Unlock()
+
+	go func() {
+		defer func() {
+			b.mu.Lock()
+			delete(b.filling, key)
+			b.mu.Unlock()
+		}()
+		ctx, cancel := context.WithTimeout(context.Background(), fillTimeout)
+		defer cancel()
+		if err := b.Fill(ctx, difficulty, topic); err != nil {
+			log.Printf("Error filling %s %s question bank: %v", difficulty, topic, err)
+		}
+	}()
+}
+
+// TopUp fills every difficulty holding fewer than the bank's minimum number
+// of approved or pending questions.
+func (b *Bank) TopUp(ctx context.Context) error {
+	var counts []struct {
+		Difficulty string `db:"difficulty"`
+		Count      int    `db:"count"`
+	}
+	err := b.db.SelectContext(ctx, &counts, `SELECT difficulty, COUNT(*) AS count FROM questions
+				WHERE language = $1 AND status <> 'rejected' AND event_id IS NULL GROUP BY difficulty`, Language)
+	if err != nil {
+		return err
+	}
+	have := map[string]int{}
+	for _, c := range counts {
+		have[c.Difficulty] = c.Count
+	}
+	for _, difficulty := range difficulties {
+		if have[difficulty] < b.minSize {
+			b.FillAsync(difficulty, DefaultTopic)
+		}
+	}
+	return nil
+}
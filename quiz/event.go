@@ -0,0 +1,154 @@
+package quiz
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EventDifficulty is the difficulty of event quizzes.
+const EventDifficulty = Medium
+
+// eventFillBatch bounds the number of events FillEvents generates quizzes
+// for in one run.
+const eventFillBatch = 5
+
+var (
+	ErrNotAttended     = errors.New("user did not attend the event")
+	ErrEventQuizClosed = errors.New("event quiz is not open")
+	ErrEventQuizOpen   = errors.New("event quiz is already in progress")
+	ErrNoQuestions     = errors.New("no questions available")
+)
+
+// EventPolicy configures event quizzes: attendees can take the quiz of an
+// event once, within Window after it ends, for BonusXP on top of the usual
+// reward, in proportion to their score.
+type EventPolicy struct {
+	Window  time.Duration
+	BonusXP int64
+}
+
+// FillEvents generates the quizzes of events that have none yet, from their
+// name and description, for review. Quizzes are generated as soon as events
+// are published, giving reviewers until the events end.
+func (b *Bank) FillEvents(ctx context.Context, window time.Duration) error {
+	var events []struct {
+		ID          string `db:"id"`
+		Name        string `db:"name"`
+		Description string `db:"description"`
+	}
+	err := b.db.SelectContext(ctx, &events, `SELECT id, name, COALESCE(description, '') AS description FROM events e
+				WHERE status IN ('published', 'ongoing', 'completed')
+					AND end_date + $1 * INTERVAL '1 second' > CURRENT_TIMESTAMP
+					AND COALESCE(description, '') <> ''
+					AND NOT EXISTS (SELECT 1 FROM questions q WHERE q.event_id = e.id AND q.status <> 'rejected')
+				ORDER BY end_date LIMIT $2`, window.Seconds(), eventFillBatch)
+	if err != nil {
+		return err
+	}
+
+	for _, e := range events {
+		req := Request{
+			Difficulty: EventDifficulty,
+			Topic:      DefaultTopic,
+			Count:      QuestionCount,
+			Material:   strings.TrimSpace(e.Name + "\n\n" + e.Description),
+		}
+		id := e.ID
+		if err := b.generate(ctx, req, &id); err != nil {
+			log.Printf("Error generating quiz of event %s: %v", e.ID, err)
+		}
+	}
+	return nil
+}
+
+// CreateForEvent stores the quiz of an event for a user who attended it. The
+// quiz is open from the end of the event for policy.Window and can be
+// submitted once, and a user has one session of it open at a time. It is
+// timed as MEDIUM quizzes are.
+func (s *Store) CreateForEvent(ctx context.Context, userID int64, eventID string, policy EventPolicy, q *Quiz) (Session, error) {
+	if err := s.CheckEventQuiz(ctx, userID, eventID, policy); err != nil {
+		return Session{}, err
+	}
+	rawQuestions, rawAnswers, err := marshalQuiz(q)
+	if err != nil {
+		return Session{}, err
+	}
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return Session{}, err
+	}
+	defer tx.Rollback()
+
+	// Starting and submitting the quiz of an event lock the registration, so
+	// no session is started while another is submitted.
+	var state struct {
+		Submitted bool `db:"submitted"`
+		Open      bool `db:"open"`
+	}
+	err = tx.GetContext(ctx, &state, `SELECT
+					EXISTS (SELECT 1 FROM quiz_sessions s WHERE s.user_id = r.user_id AND s.event_id = r.event_id
+						AND s.submitted_at IS NOT NULL) AS submitted,
+					EXISTS (SELECT 1 FROM quiz_sessions s WHERE s.user_id = r.user_id AND s.event_id = r.event_id
+						AND s.submitted_at IS NULL AND s.expires_at > CURRENT_TIMESTAMP) AS open
+				FROM event_registrations r WHERE r.user_id = $1 AND r.event_id = $2 FOR UPDATE OF r`, userID, eventID)
+	switch {
+	case err == sql.ErrNoRows:
+		return Session{}, ErrNotAttended
+	case err != nil:
+		return Session{}, err
+	case state.Submitted:
+		return Session{}, ErrAlreadySubmitted
+	case state.Open:
+		return Session{}, ErrEventQuizOpen
+	}
+
+	var session Session
+	err = tx.GetContext(ctx, &session, `INSERT INTO quiz_sessions (id, user_id, difficulty, event_id, questions, answer_key, expires_at)
+				VALUES ($1, $2, $3, $4, $5, $6, CURRENT_TIMESTAMP + $7 * INTERVAL '1 second')
+				RETURNING id, user_id, difficulty, event_id, questions, expires_at`,
+		uuid.NewString(), userID, EventDifficulty, eventID, rawQuestions, rawAnswers, SessionTTL.Seconds())
+	if err != nil {
+		return Session{}, err
+	}
+	if err := s.startTiming(ctx, tx, &session, q); err != nil {
+		return Session{}, err
+	}
+	return session, tx.Commit()
+}
+
+// CheckEventQuiz checks that a user can take the quiz of an event now: the
+// user checked in to the event, the event is completed and ended less than
+// policy.Window ago, and the user has not submitted its quiz.
+func (s *Store) CheckEventQuiz(ctx context.Context, userID int64, eventID string, policy EventPolicy) error {
+	var state struct {
+		Attended  bool `db:"attended"`
+		Open      bool `db:"open"`
+		Submitted bool `db:"submitted"`
+	}
+	err := s.db.GetContext(ctx, &state, `SELECT r.checked_in_at IS NOT NULL AS attended,
+					e.status = 'completed' AND e.end_date + $3 * INTERVAL '1 second' > CURRENT_TIMESTAMP AS open,
+					EXISTS (SELECT 1 FROM quiz_sessions s WHERE s.user_id = r.user_id AND s.event_id = e.id
+						AND s.submitted_at IS NOT NULL) AS submitted
+				FROM event_registrations r JOIN events e ON e.id = r.event_id
+				WHERE r.user_id = $1 AND r.event_id = $2`, userID, eventID, policy.Window.Seconds())
+	switch {
+	case err == sql.ErrNoRows:
+		return ErrNotAttended
+	case err != nil:
+		return err
+	case !state.Attended:
+		return ErrNotAttended
+	case !state.Open:
+		return ErrEventQuizClosed
+	case state.Submitted:
+		return ErrAlreadySubmitted
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+package quiz
+
+import (
+	"context"
+	"fmt"
+)
+
+// Fake generates the same quiz for a request every time, for tests and for
+// running without a language model.
+type Fake struct{}
+
+func (Fake) Name() string {
+	return "fake"
+}
+
+func (Fake) Generate(ctx context.Context, req Request) (*Quiz, error) {
+	q := &Quiz{Questions: make([]Question, req.Count)}
+	for i := range q.Questions {
+		variants := make([]Variant, len(VariantKeys))
+		for j, key := range VariantKeys {
+			variants[j] = Variant{Key: key, Text: fmt.Sprintf("Variant %s", key)}
+		}
+		q.Questions[i] = Question{
+			Question: fmt.Sprintf("%s %s ecology question %d", req.Difficulty, req.Topic, i+1),
+			Variants: variants,
+			Answer:   VariantKeys[i%len(VariantKeys)],
+		}
+	}
+	return q, nil
+}
@@ -0,0 +1,94 @@
+package quiz
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/generative-ai-go/genai"
+	"google.golang.org/api/option"
+)
+
+// Gemini generates quizzes with Google's Gemini models.
+type Gemini struct {
+	apiKey string
+	model  string
+}
+
+func NewGemini(apiKey, model string) *Gemini {
+	return &Gemini{apiKey: apiKey, model: model}
+}
+
+func (g *Gemini) Name() string {
+	return "gemini"
+}
+
+func (g *Gemini) Generate(ctx context.Context, req Request) (*Quiz, error) {
+	if g.apiKey == "" {
+		return nil, errors.New("no API key configured")
+	}
+	client, err := genai.NewClient(ctx, option.WithAPIKey(g.apiKey))
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+
+	model := client.GenerativeModel(g.model)
+	model.SafetySettings = []*genai.SafetySetting{
+		{
+			Category:  genai.HarmCategoryHarassment,
+			Threshold: genai.HarmBlockOnlyHigh,
+		},
+		{
+			Category:  genai.HarmCategoryDangerousContent,
+			Threshold: genai.HarmBlockOnlyHigh,
+		},
+	}
+	model.GenerationConfig = genai.GenerationConfig{
+		ResponseMIMEType: "application/json",
+		ResponseSchema:   geminiSchema,
+	}
+
+	resp, err := model.GenerateContent(ctx, genai.Text(Prompt(req)))
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Candidates) == 0 || resp.Candidates[0].Content == nil || len(resp.Candidates[0].Content.Parts) == 0 {
+		return nil, errors.New("no content in response")
+	}
+	text, ok := resp.Candidates[0].Content.Parts[0].(genai.Text)
+	if !ok {
+		return nil, fmt.Errorf("%w: unexpected response part %T", ErrInvalidQuiz, resp.Candidates[0].Content.Parts[0])
+	}
+	return Parse([]byte(text), req.Count)
+}
+
+// geminiSchema is the schema of Quiz in Gemini's subset of OpenAPI.
+var geminiSchema = &genai.Schema{
+	Type:     genai.TypeObject,
+	Required: []string{"questions"},
+	Properties: map[string]*genai.Schema{
+		"questions": {
+			Type: genai.TypeArray,
+			Items: &genai.Schema{
+				Type:     genai.TypeObject,
+				Required: []string{"question", "variants", "answer"},
+				Properties: map[string]*genai.Schema{
+					"question": {Type: genai.TypeString},
+					"variants": {
+						Type: genai.TypeArray,
+						Items: &genai.Schema{
+							Type:     genai.TypeObject,
+							Required: []string{"key", "text"},
+							Properties: map[string]*genai.Schema{
+								"key":  {Type: genai.TypeString, Format: "enum", Enum: VariantKeys},
+								"text": {Type: genai.TypeString},
+							},
+						},
+					},
+					"answer": {Type: genai.TypeString, Format: "enum", Enum: VariantKeys},
+				},
+			},
+		},
+	},
+}
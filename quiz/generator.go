@@ -0,0 +1,118 @@
+package quiz
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+// QuestionCount is the number of questions in a quiz.
+const QuestionCount = 10
+
+// maxAttempts bounds how often a generator is asked again after returning
+// an invalid quiz.
+const maxAttempts = 3
+
+// Request describes the quiz to generate.
+type Request struct {
+	Difficulty string
+	// Topic is a topic of the taxonomy, or DefaultTopic for any.
+	Topic string
+	Count int
+	// Material is a text the questions are about, such as the description
+	// of an event. Empty for general questions.
+	Material string
+}
+
+// QuestionGenerator generates a valid quiz for a request. It returns an error
+// wrapping ErrInvalidQuiz when the output did not follow the schema.
+type QuestionGenerator interface {
+	// Name identifies the generator in logs and errors.
+	Name() string
+	Generate(ctx context.Context, req Request) (*Quiz, error)
+}
+
+// Prompt asks a language model for the quiz of a request.
+func Prompt(req Request) string {
+	var about, material string
+	if topic, ok := FindTopic(req.Topic); ok {
+		about = " about " + topic.Subject
+	}
+	if req.Material != "" {
+		about = ""
+		material = fmt.Sprintf("\nThe questions are for people who took part in the following event and must be answerable from its description:\n\"\"\"\n%s\n\"\"\"", req.Material)
+	}
+	return fmt.Sprintf(`Generate %d distinct multiple choice ecology questions%s, of %s difficulty (EASY, MEDIUM or HARD).%s
+Answer with JSON only, in this format:
+{
+    "questions": [
+        {
+            "question": "Which of the following is NOT a category within the broad field of Ecology?",
+            "variants": [
+                {"key": "A", "text": "Biomes"},
+                {"key": "B", "text": "Ecosystems"},
+                {"key": "C", "text": "Biodiversity"},
+                {"key": "D", "text": "Astrophysics"}
+            ],
+            "answer": "D"
+        }
+    ]
+}
+Every question has exactly four different variants keyed A, B, C and D, in this order, and answer is the key of the only correct variant. Vary the position of the correct variant.`, req.Count, about, req.Difficulty, material)
+}
+
+// Fallback tries its generators in order until one returns a valid quiz. A
+// generator returning invalid quizzes is asked again up to maxAttempts times
+// before the next one is tried.
+type Fallback struct {
+	generators []QuestionGenerator
+	// timeout bounds each attempt, 0 for none.
+	timeout time.Duration
+}
+
+func NewFallback(timeout time.Duration, generators ...QuestionGenerator) *Fallback {
+	return &Fallback{generators: generators, timeout: timeout}
+}
+
+func (f *Fallback) Name() string {
+	return "fallback"
+}
+
+func (f *Fallback) Generate(ctx context.Context, req Request) (*Quiz, error) {
+	if len(f.generators) == 0 {
+		return nil, errors.New("no quiz providers configured")
+	}
+
+	var errs []error
+	for _, g := range f.generators {
+		for attempt := 1; attempt <= maxAttempts; attempt++ {
+			quiz, err := f.attempt(ctx, g, req)
+			if err == nil {
+				if quiz.Source == "" {
+					quiz.Source = g.Name()
+				}
+				return quiz, nil
+			}
+			log.Printf("Quiz provider %s failed (attempt %d): %v", g.Name(), attempt, err)
+			if ctx.Err() != nil {
+				return nil, errors.Join(append(errs, fmt.Errorf("%s: %w", g.Name(), err))...)
+			}
+			if !errors.Is(err, ErrInvalidQuiz) || attempt == maxAttempts {
+				errs = append(errs, fmt.Errorf("%s: %w", g.Name(), err))
+				break
+			}
+		}
+	}
+	return nil, errors.Join(errs...)
+}
+
+func (f *Fallback) attempt(ctx context.Context, g QuestionGenerator, req Request) (*Quiz, error) {
+	if f.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, f.timeout)
+		defer cancel()
+	}
+	return g.Generate(ctx, req)
+}
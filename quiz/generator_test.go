@@ -0,0 +1,136 @@
+package quiz
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+var errUnavailable = errors.New("provider unavailable")
+
+// scripted is a generator that returns the results of its script in turn:
+// nil for a valid quiz, ErrInvalidQuiz for output that does not follow the
+// schema, context.DeadlineExceeded to block until the attempt times out, or
+// any other error.
+type scripted struct {
+	name   string
+	script []error
+	calls  int
+}
+
+func (s *scripted) Name() string {
+	return s.name
+}
+
+func (s *scripted) Generate(ctx context.Context, req Request) (*Quiz, error) {
+	step := s.script[s.calls]
+	s.calls++
+	switch {
+	case step == nil:
+		return Fake{}.Generate(ctx, req)
+	case errors.Is(step, ErrInvalidQuiz):
+		return Parse([]byte(`{"questions": []}`), req.Count)
+	case errors.Is(step, context.DeadlineExceeded):
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	return nil, step
+}
+
+func TestFallback(t *testing.T) {
+	tests := []struct {
+		name    string
+		scripts [][]error
+		// source is the generator expected to succeed, "" for none.
+		source string
+		calls  []int
+	}{
+		{
+			name:    "first succeeds",
+			scripts: [][]error{{nil}, {nil}},
+			source:  "first",
+			calls:   []int{1, 0},
+		},
+		{
+			name:    "failure moves on, invalid output is retried",
+			scripts: [][]error{{errUnavailable}, {ErrInvalidQuiz, nil}},
+			source:  "second",
+			calls:   []int{1, 2},
+		},
+		{
+			name:    "fails, then invalid, then succeeds",
+			scripts: [][]error{{errUnavailable}, {ErrInvalidQuiz, ErrInvalidQuiz, nil}, {nil}},
+			source:  "second",
+			calls:   []int{1, 3, 0},
+		},
+		{
+			name:    "invalid until attempts run out",
+			scripts: [][]error{{ErrInvalidQuiz, ErrInvalidQuiz, ErrInvalidQuiz}, {nil}},
+			source:  "second",
+			calls:   []int{maxAttempts, 1},
+		},
+		{
+			name:    "attempt times out",
+			scripts: [][]error{{context.DeadlineExceeded}, {nil}},
+			source:  "second",
+			calls:   []int{1, 1},
+		},
+		{
+			name:    "all fail",
+			scripts: [][]error{{errUnavailable}, {ErrInvalidQuiz, ErrInvalidQuiz, ErrInvalidQuiz}},
+			calls:   []int{1, maxAttempts},
+		},
+	}
+
+	names := []string{"first", "second", "third"}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var generators []QuestionGenerator
+			var scripts []*scripted
+			for i, script := range tt.scripts {
+				s := &scripted{name: names[i], script: script}
+				scripts = append(scripts, s)
+				generators = append(generators, s)
+			}
+
+			req := Request{Difficulty: "EASY", Topic: DefaultTopic, Count: QuestionCount}
+			quiz, err := NewFallback(10*time.Millisecond, generators...).Generate(context.Background(), req)
+			if tt.source == "" {
+				if err == nil {
+					t.Fatal("Generate succeeded, want an error")
+				}
+				if !errors.Is(err, errUnavailable) || !errors.Is(err, ErrInvalidQuiz) {
+					t.Errorf("Generate = %v, want the errors of every generator", err)
+				}
+				for _, s := range scripts {
+					if !strings.Contains(err.Error(), s.name+": ") {
+						t.Errorf("Generate = %v, want an error naming %s", err, s.name)
+					}
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("Generate: %v", err)
+				}
+				if quiz.Source != tt.source {
+					t.Errorf("Source = %q, want %q", quiz.Source, tt.source)
+				}
+				if err := quiz.Validate(QuestionCount); err != nil {
+					t.Errorf("Validate: %v", err)
+				}
+			}
+			for i, s := range scripts {
+				if s.calls != tt.calls[i] {
+					t.Errorf("%s called %d times, want %d", s.name, s.calls, tt.calls[i])
+				}
+			}
+		})
+	}
+}
+
+func TestFallbackWithoutGenerators(t *testing.T) {
+	if _, err := NewFallback(0).Generate(context.Background(), Request{Count: QuestionCount}); err == nil {
+		t.Error("Generate succeeded without generators")
+	}
+}
@@ -0,0 +1,89 @@
+package quiz
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// OpenAI generates quizzes with any server implementing the OpenAI chat
+// completions API, e.g. a local Ollama or llama.cpp server.
+type OpenAI struct {
+	baseURL string
+	apiKey  string
+	model   string
+	client  *http.Client
+}
+
+// NewOpenAI returns a generator for the API at baseURL, e.g.
+// http://localhost:11434/v1 for Ollama. apiKey may be empty for local servers.
+func NewOpenAI(baseURL, apiKey, model string) *OpenAI {
+	return &OpenAI{baseURL: strings.TrimSuffix(baseURL, "/"), apiKey: apiKey, model: model, client: &http.Client{}}
+}
+
+func (o *OpenAI) Name() string {
+	return "openai"
+}
+
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+func (o *OpenAI) Generate(ctx context.Context, req Request) (*Quiz, error) {
+	payload, err := json.Marshal(map[string]interface{}{
+		"model": o.model,
+		"messages": []chatMessage{
+			{Role: "system", Content: "You write ecology quiz questions and answer with JSON only."},
+			{Role: "user", Content: Prompt(req)},
+		},
+		"response_format": map[string]interface{}{
+			"type":        "json_schema",
+			"json_schema": map[string]interface{}{"name": "quiz", "strict": true, "schema": jsonSchema},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, o.baseURL+"/chat/completions", bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if o.apiKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+o.apiKey)
+	}
+
+	resp, err := o.client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: %s", resp.Status, bytes.TrimSpace(body))
+	}
+
+	var completion struct {
+		Choices []struct {
+			Message chatMessage `json:"message"`
+		} `json:"choices"`
+	}
+	if err := json.Unmarshal(body, &completion); err != nil {
+		return nil, err
+	}
+	if len(completion.Choices) == 0 || completion.Choices[0].Message.Content == "" {
+		return nil, errors.New("no content in response")
+	}
+	return Parse([]byte(completion.Choices[0].Message.Content), req.Count)
+}
@@ -0,0 +1,164 @@
+package quiz
+
+import (
+	"context"
+	"database/sql"
+	"math"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// Users are rated with Elo ratings per topic, and over all topics under
+// DefaultTopic. Every answered question is rated as a game between the user
+// and the question, whose rating is set by its difficulty. Event quizzes are
+// not rated.
+const (
+	// InitialRating is the rating of users who have not answered a question
+	// of a topic, that of a MEDIUM question.
+	InitialRating = 1200
+	// ratingK is the most a rating changes with one answer.
+	ratingK = 32
+)
+
+// Adaptive is the difficulty of quizzes whose difficulty is picked from the
+// rating of the user.
+const Adaptive = "ADAPTIVE"
+
+// difficultyRatings are the ratings of questions by difficulty.
+var difficultyRatings = map[string]float64{Easy: 1000, Medium: InitialRating, Hard: 1400}
+
+// Rating is the rating of a user in a topic.
+type Rating struct {
+	Topic  string `db:"topic" json:"topic"`
+	Rating int    `db:"rating" json:"rating"`
+	// Answered is the number of questions rated.
+	Answered  int       `db:"answered" json:"answered"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// Expected returns the chance a user with a rating answers a question of a
+// difficulty correctly.
+func Expected(rating float64, difficulty string) float64 {
+	return 1 / (1 + math.Pow(10, (difficultyRatings[difficulty]-rating)/400))
+}
+
+// DifficultyFor returns the difficulty whose questions are rated nearest to
+// rating.
+func DifficultyFor(rating float64) string {
+	best := Medium
+	for _, difficulty := range difficulties {
+		if math.Abs(difficultyRatings[difficulty]-rating) < math.Abs(difficultyRatings[best]-rating) {
+			best = difficulty
+		}
+	}
+	return best
+}
+
+// Ratings returns the ratings of a user, overall first.
+func (s *Store) Ratings(ctx context.Context, userID int64) ([]Rating, error) {
+	ratings := []Rating{}
+	err := s.db.SelectContext(ctx, &ratings, `SELECT topic, ROUND(rating)::integer AS rating, answered, updated_at
+				FROM user_ratings WHERE user_id = $1 ORDER BY topic <> $2, topic`, userID, DefaultTopic)
+	return ratings, err
+}
+
+// Rating returns the rating of a user in a topic, the overall rating of users
+// not yet rated in the topic.
+func (s *Store) Rating(ctx context.Context, userID int64, topic string) (float64, error) {
+	var rating float64
+	err := s.db.GetContext(ctx, &rating, `SELECT rating FROM user_ratings WHERE user_id = $1 AND topic IN ($2, $3)
+				ORDER BY topic = $3 LIMIT 1`, userID, topic, DefaultTopic)
+	if err == sql.ErrNoRows {
+		return InitialRating, nil
+	}
+	return rating, err
+}
+
+// rate updates the ratings of a user with the answers to a graded session,
+// correct by question, and returns the number of correct answers weighted by
+// the difficulty of the questions relative to the user, and the updated
+// ratings by topic. A correct answer weighs 1 for a question rated as the
+// user is, up to 2 for far harder questions and down to 0 for far easier
+// ones. Questions no longer in the bank weigh 1 and are not rated.
+func rate(ctx context.Context, tx *sqlx.Tx, userID int64, questionIDs []string, correct []bool) (float64, map[string]int, error) {
+	var questions []struct {
+		ID         string `db:"id"`
+		Topic      string `db:"topic"`
+		Difficulty string `db:"difficulty"`
+	}
+	err := tx.SelectContext(ctx, &questions, `SELECT id, topic, difficulty FROM questions WHERE id = ANY($1)`,
+		pq.Array(questionIDs))
+	if err != nil {
+		return 0, nil, err
+	}
+	byID := make(map[string]int, len(questions))
+	for i, q := range questions {
+		byID[q.ID] = i
+	}
+
+	var rows []struct {
+		Topic  string  `db:"topic"`
+		Rating float64 `db:"rating"`
+	}
+	err = tx.SelectContext(ctx, &rows, `SELECT topic, rating FROM user_ratings WHERE user_id = $1 FOR UPDATE`, userID)
+	if err != nil {
+		return 0, nil, err
+	}
+	ratings := make(map[string]float64, len(rows))
+	for _, row := range rows {
+		ratings[row.Topic] = row.Rating
+	}
+	rating := func(topic string) float64 {
+		if r, ok := ratings[topic]; ok {
+			return r
+		}
+		if r, ok := ratings[DefaultTopic]; ok {
+			return r
+		}
+		return InitialRating
+	}
+
+	var weighted float64
+	answered := map[string]int{}
+	for i, id := range questionIDs {
+		n, ok := byID[id]
+		if !ok || i >= len(correct) {
+			if i < len(correct) && correct[i] {
+				weighted++
+			}
+			continue
+		}
+		q := questions[n]
+		expected := Expected(rating(q.Topic), q.Difficulty)
+		score := 0.0
+		if correct[i] {
+			score = 1
+			weighted += 2 * (1 - expected)
+		}
+
+		topics := []string{DefaultTopic}
+		if q.Topic != DefaultTopic {
+			topics = append(topics, q.Topic)
+		}
+		for _, topic := range topics {
+			r := rating(topic)
+			ratings[topic] = r + ratingK*(score-Expected(r, q.Difficulty))
+			answered[topic]++
+		}
+	}
+
+	updated := make(map[string]int, len(answered))
+	for topic, n := range answered {
+		_, err := tx.ExecContext(ctx, `INSERT INTO user_ratings (user_id, topic, rating, answered) VALUES ($1, $2, $3, $4)
+					ON CONFLICT (user_id, topic) DO UPDATE SET rating = EXCLUDED.rating,
+						answered = user_ratings.answered + EXCLUDED.answered, updated_at = CURRENT_TIMESTAMP`,
+			userID, topic, ratings[topic], n)
+		if err != nil {
+			return 0, nil, err
+		}
+		updated[topic] = int(math.Round(ratings[topic]))
+	}
+	return weighted, updated, nil
+}
@@ -0,0 +1,313 @@
+package quiz
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// Review statuses of bank questions. Only approved questions are served.
+const (
+	StatusPending  = "pending"
+	StatusApproved = "approved"
+	StatusRejected = "rejected"
+)
+
+// SourceImport is the source of imported questions.
+const SourceImport = "import"
+
+// Actions recorded in the audit of a question.
+const (
+	ActionImport  = "import"
+	ActionEdit    = "edit"
+	ActionApprove = "approve"
+	ActionReject  = "reject"
+)
+
+var (
+	ErrQuestionNotFound  = errors.New("question not found")
+	ErrDuplicateQuestion = errors.New("the bank already has this question")
+	ErrStatusUnchanged   = errors.New("question already has this status")
+)
+
+// BankQuestion is a question stored in the bank.
+type BankQuestion struct {
+	ID         string   `db:"id" json:"id"`
+	Difficulty string   `db:"difficulty" json:"difficulty"`
+	Topic      string   `db:"topic" json:"topic"`
+	Language   string   `db:"language" json:"language"`
+	Source     string   `db:"source" json:"source"`
+	Status     string   `db:"status" json:"status"`
+	Question   string   `db:"question" json:"question"`
+	Variants   Variants `db:"variants" json:"variants"`
+	Answer     string   `db:"answer" json:"answer"`
+	// EventID is set on the questions of an event quiz.
+	EventID    *string    `db:"event_id" json:"event_id"`
+	CreatedBy  *int64     `db:"created_by" json:"created_by"`
+	ReviewedBy *int64     `db:"reviewed_by" json:"reviewed_by"`
+	ReviewedAt *time.Time `db:"reviewed_at" json:"reviewed_at"`
+	CreatedAt  time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt  time.Time  `db:"updated_at" json:"updated_at"`
+}
+
+// BankColumns selects a BankQuestion from the questions table.
+const BankColumns = `id, difficulty, topic, language, source, status, question, variants, answer, event_id,
+				created_by, reviewed_by, reviewed_at, created_at, updated_at`
+
+// QuestionInput is a question written or edited by a reviewer. Topic
+// defaults to DefaultTopic and language to Language.
+type QuestionInput struct {
+	Difficulty string `json:"difficulty"`
+	Topic      string `json:"topic"`
+	Language   string `json:"language"`
+	Question
+}
+
+// Validate normalizes the input and checks it as Question.Validate does.
+func (in *QuestionInput) Validate() error {
+	in.Difficulty = strings.ToUpper(strings.TrimSpace(in.Difficulty))
+	switch in.Difficulty {
+	case Easy, Medium, Hard:
+	default:
+		return fmt.Errorf("%w: difficulty must be one of EASY, MEDIUM, HARD", ErrInvalidQuiz)
+	}
+	in.Topic = strings.ToLower(strings.TrimSpace(in.Topic))
+	if in.Topic == "" {
+		in.Topic = DefaultTopic
+	}
+	if !ValidTopic(in.Topic) {
+		return fmt.Errorf("%w: unknown topic %q", ErrInvalidQuiz, in.Topic)
+	}
+	in.Language = strings.ToLower(strings.TrimSpace(in.Language))
+	if in.Language == "" {
+		in.Language = Language
+	}
+	if len(in.Language) > 10 {
+		return fmt.Errorf("%w: language is longer than 10 characters", ErrInvalidQuiz)
+	}
+	return in.Question.Validate()
+}
+
+// AuditEntry is an import, edit or review of a question. Before and After
+// are the question as stored before and after it.
+type AuditEntry struct {
+	ID         int64           `db:"id" json:"id"`
+	QuestionID string          `db:"question_id" json:"question_id"`
+	UserID     *int64          `db:"user_id" json:"user_id"`
+	Action     string          `db:"action" json:"action"`
+	Before     json.RawMessage `db:"before" json:"before" swaggertype:"object"`
+	After      json.RawMessage `db:"after" json:"after" swaggertype:"object"`
+	Note       string          `db:"note" json:"note"`
+	CreatedAt  time.Time       `db:"created_at" json:"created_at"`
+}
+
+// ImportRow is a question read from row Row of an import, counting data rows
+// from 1.
+type ImportRow struct {
+	Row   int
+	Input QuestionInput
+}
+
+// RowError reports why a row of an import was not imported.
+type RowError struct {
+	Row   int    `json:"row"`
+	Error string `json:"error"`
+}
+
+// ImportReport is the outcome of an import.
+type ImportReport struct {
+	Imported int        `json:"imported"`
+	Errors   []RowError `json:"errors"`
+}
+
+// Import adds questions imported by a user to the bank as pending, reporting
+// the rows that are invalid or already in the bank.
+func (b *Bank) Import(ctx context.Context, userID int64, rows []ImportRow) (ImportReport, error) {
+	report := ImportReport{Errors: []RowError{}}
+	tx, err := b.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return report, err
+	}
+	defer tx.Rollback()
+
+	for _, row := range rows {
+		in := row.Input
+		if err := in.Validate(); err != nil {
+			report.Errors = append(report.Errors, RowError{Row: row.Row, Error: err.Error()})
+			continue
+		}
+		var q BankQuestion
+		err := tx.GetContext(ctx, &q, `INSERT INTO questions (id, difficulty, topic, language, source, status, question, variants, answer, created_by)
+					VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) ON CONFLICT DO NOTHING
+					RETURNING `+BankColumns,
+			uuid.NewString(), in.Difficulty, in.Topic, in.Language, SourceImport, StatusPending,
+			in.Question.Question, in.Variants, in.Answer, userID)
+		if err == sql.ErrNoRows {
+			report.Errors = append(report.Errors, RowError{Row: row.Row, Error: ErrDuplicateQuestion.Error()})
+			continue
+		}
+		if err != nil {
+			return report, err
+		}
+		if err := audit(ctx, tx, userID, ActionImport, nil, q, ""); err != nil {
+			return report, err
+		}
+		report.Imported++
+	}
+	return report, tx.Commit()
+}
+
+// ExportFilter selects the questions to export. Empty fields match all.
+type ExportFilter struct {
+	Status     string
+	Difficulty string
+	Topic      string
+	Language   string
+}
+
+// Export returns the questions of the bank matching filter, oldest first.
+func (b *Bank) Export(ctx context.Context, filter ExportFilter) ([]BankQuestion, error) {
+	questions := []BankQuestion{}
+	err := b.db.SelectContext(ctx, &questions, `SELECT `+BankColumns+` FROM questions
+				WHERE ($1 = '' OR status = $1) AND ($2 = '' OR difficulty = $2)
+					AND ($3 = '' OR topic = $3) AND ($4 = '' OR language = $4)
+				ORDER BY created_at, id`, filter.Status, filter.Difficulty, filter.Topic, filter.Language)
+	return questions, err
+}
+
+// Get returns a question of the bank.
+func (b *Bank) Get(ctx context.Context, id string) (BankQuestion, error) {
+	var q BankQuestion
+	err := b.db.GetContext(ctx, &q, `SELECT `+BankColumns+` FROM questions WHERE id = $1`, id)
+	if err == sql.ErrNoRows {
+		return q, ErrQuestionNotFound
+	}
+	return q, err
+}
+
+// Edit replaces the content of a question on behalf of a reviewer. Its review
+// status is kept.
+func (b *Bank) Edit(ctx context.Context, id string, userID int64, in QuestionInput) (BankQuestion, error) {
+	if err := in.Validate(); err != nil {
+		return BankQuestion{}, err
+	}
+	tx, err := b.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return BankQuestion{}, err
+	}
+	defer tx.Rollback()
+
+	before, err := lockQuestion(ctx, tx, id)
+	if err != nil {
+		return BankQuestion{}, err
+	}
+	var duplicate bool
+	err = tx.GetContext(ctx, &duplicate, `SELECT EXISTS (SELECT 1 FROM questions
+				WHERE language = $1 AND lower(question) = lower($2) AND id <> $3)`, in.Language, in.Question.Question, id)
+	if err != nil {
+		return BankQuestion{}, err
+	}
+	if duplicate {
+		return BankQuestion{}, ErrDuplicateQuestion
+	}
+
+	var after BankQuestion
+	err = tx.GetContext(ctx, &after, `UPDATE questions SET difficulty = $2, topic = $3, language = $4, question = $5,
+					variants = $6, answer = $7, updated_at = CURRENT_TIMESTAMP
+				WHERE id = $1 RETURNING `+BankColumns,
+		id, in.Difficulty, in.Topic, in.Language, in.Question.Question, in.Variants, in.Answer)
+	if err != nil {
+		return BankQuestion{}, err
+	}
+	if err := audit(ctx, tx, userID, ActionEdit, &before, after, ""); err != nil {
+		return BankQuestion{}, err
+	}
+	return after, tx.Commit()
+}
+
+// Review approves or rejects a question on behalf of a reviewer. Approved
+// questions can be rejected later to take them out of quizzes, and rejected
+// ones approved.
+func (b *Bank) Review(ctx context.Context, id string, userID int64, status, note string) (BankQuestion, error) {
+	action := ActionApprove
+	switch status {
+	case StatusApproved:
+	case StatusRejected:
+		action = ActionReject
+	default:
+		return BankQuestion{}, fmt.Errorf("status must be one of %s, %s", StatusApproved, StatusRejected)
+	}
+
+	tx, err := b.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return BankQuestion{}, err
+	}
+	defer tx.Rollback()
+
+	before, err := lockQuestion(ctx, tx, id)
+	if err != nil {
+		return BankQuestion{}, err
+	}
+	if before.Status == status {
+		return BankQuestion{}, ErrStatusUnchanged
+	}
+
+	var after BankQuestion
+	err = tx.GetContext(ctx, &after, `UPDATE questions SET status = $2, reviewed_by = $3, reviewed_at = CURRENT_TIMESTAMP,
+					updated_at = CURRENT_TIMESTAMP
+				WHERE id = $1 RETURNING `+BankColumns, id, status, userID)
+	if err != nil {
+		return BankQuestion{}, err
+	}
+	if err := audit(ctx, tx, userID, action, &before, after, strings.TrimSpace(note)); err != nil {
+		return BankQuestion{}, err
+	}
+	return after, tx.Commit()
+}
+
+// History returns the audit of a question, oldest first.
+func (b *Bank) History(ctx context.Context, id string) ([]AuditEntry, error) {
+	if _, err := b.Get(ctx, id); err != nil {
+		return nil, err
+	}
+	entries := []AuditEntry{}
+	err := b.db.SelectContext(ctx, &entries, `SELECT id, question_id, user_id, action, before, after, note, created_at
+				FROM question_audit WHERE question_id = $1 ORDER BY created_at, id`, id)
+	return entries, err
+}
+
+func lockQuestion(ctx context.Context, tx *sqlx.Tx, id string) (BankQuestion, error) {
+	var q BankQuestion
+	err := tx.GetContext(ctx, &q, `SELECT `+BankColumns+` FROM questions WHERE id = $1 FOR UPDATE`, id)
+	if err == sql.ErrNoRows {
+		return q, ErrQuestionNotFound
+	}
+	return q, err
+}
+
+// audit records an action of a user on a question. before is nil for new
+// questions.
+func audit(ctx context.Context, tx *sqlx.Tx, userID int64, action string, before *BankQuestion, after BankQuestion, note string) error {
+	var rawBefore interface{}
+	if before != nil {
+		b, err := json.Marshal(before)
+		if err != nil {
+			return err
+		}
+		rawBefore = b
+	}
+	rawAfter, err := json.Marshal(after)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `INSERT INTO question_audit (question_id, user_id, action, before, after, note)
+				VALUES ($1, $2, $3, $4, $5, $6)`, after.ID, userID, action, rawBefore, rawAfter, note)
+	return err
+}
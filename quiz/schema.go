@@ -0,0 +1,217 @@
+package quiz
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// VariantKeys are the keys of the variants of every question, in order.
+var VariantKeys = []string{"A", "B", "C", "D"}
+
+// ErrInvalidQuiz is returned for generated quizzes that do not follow the
+// schema. Generators are retried when they return it.
+var ErrInvalidQuiz = errors.New("invalid quiz")
+
+// Variant is one of the choices of a question.
+type Variant struct {
+	Key  string `json:"key"`
+	Text string `json:"text"`
+}
+
+// Variants are the choices of a question, stored as JSON.
+type Variants []Variant
+
+// Value stores the variants as JSON.
+func (v Variants) Value() (driver.Value, error) {
+	return json.Marshal(v)
+}
+
+// Scan reads variants stored as JSON.
+func (v *Variants) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		return json.Unmarshal(s, v)
+	case string:
+		return json.Unmarshal([]byte(s), v)
+	}
+	return fmt.Errorf("cannot scan %T into Variants", src)
+}
+
+// Question is a multiple choice question. Answer is the key of the correct
+// variant.
+type Question struct {
+	// ID is the ID of questions drawn from the bank.
+	ID       string   `json:"-"`
+	Question string   `json:"question"`
+	Variants Variants `json:"variants"`
+	Answer   string   `json:"answer"`
+}
+
+// Quiz is a generated quiz with its answers.
+type Quiz struct {
+	Questions []Question `json:"questions"`
+	// Source names the provider that generated the quiz.
+	Source string `json:"-"`
+}
+
+// Test is a question as shown to the user, without its answer. Variants are
+// single entry objects such as {"A": "Biomes"}.
+type Test struct {
+	Question string              `json:"question"`
+	Variants []map[string]string `json:"variants"`
+}
+
+// Tests returns the questions as shown to the user, numbered from 1 in order.
+func (q *Quiz) Tests() []Test {
+	tests := make([]Test, len(q.Questions))
+	for i, question := range q.Questions {
+		tests[i] = Test{Question: question.Question, Variants: make([]map[string]string, len(question.Variants))}
+		for j, v := range question.Variants {
+			tests[i].Variants[j] = map[string]string{v.Key: v.Text}
+		}
+	}
+	return tests
+}
+
+// IDs returns the bank IDs of the questions.
+func (q *Quiz) IDs() []string {
+	ids := make([]string, len(q.Questions))
+	for i, question := range q.Questions {
+		ids[i] = question.ID
+	}
+	return ids
+}
+
+// AnswerKey returns the answers by question number.
+func (q *Quiz) AnswerKey() map[string]string {
+	key := make(map[string]string, len(q.Questions))
+	for i, question := range q.Questions {
+		key[strconv.Itoa(i+1)] = question.Answer
+	}
+	return key
+}
+
+// Parse decodes a generated quiz and validates it with Validate.
+func Parse(raw []byte, count int) (*Quiz, error) {
+	var q Quiz
+	if err := json.Unmarshal(raw, &q); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidQuiz, err)
+	}
+	if err := q.Validate(count); err != nil {
+		return nil, err
+	}
+	return &q, nil
+}
+
+// Validate checks that the quiz has exactly count distinct questions, each
+// valid as checked by Question.Validate. Keys and answers are normalized to
+// upper case.
+func (q *Quiz) Validate(count int) error {
+	if len(q.Questions) != count {
+		return fmt.Errorf("%w: %d questions instead of %d", ErrInvalidQuiz, len(q.Questions), count)
+	}
+
+	seen := make(map[string]bool, count)
+	for i := range q.Questions {
+		question := &q.Questions[i]
+		if err := question.check(); err != nil {
+			return fmt.Errorf("%w: question %d %v", ErrInvalidQuiz, i+1, err)
+		}
+		if seen[normalize(question.Question)] {
+			return fmt.Errorf("%w: question %d is a duplicate", ErrInvalidQuiz, i+1)
+		}
+		seen[normalize(question.Question)] = true
+	}
+	return nil
+}
+
+// Validate checks that the question is not empty and has the variants A to D
+// holding distinct texts, one of them the answer. Keys and answers are
+// normalized to upper case.
+func (q *Question) Validate() error {
+	if err := q.check(); err != nil {
+		return fmt.Errorf("%w: question %v", ErrInvalidQuiz, err)
+	}
+	return nil
+}
+
+// check implements Validate, describing the problem as a predicate of the
+// question.
+func (q *Question) check() error {
+	q.Question = strings.TrimSpace(q.Question)
+	if q.Question == "" {
+		return errors.New("is empty")
+	}
+
+	if len(q.Variants) != len(VariantKeys) {
+		return fmt.Errorf("has %d variants instead of %d", len(q.Variants), len(VariantKeys))
+	}
+	texts := make(map[string]bool, len(VariantKeys))
+	for i := range q.Variants {
+		v := &q.Variants[i]
+		v.Key = strings.ToUpper(strings.TrimSpace(v.Key))
+		v.Text = strings.TrimSpace(v.Text)
+		if v.Key != VariantKeys[i] {
+			return fmt.Errorf("has variant %q where %s was expected", v.Key, VariantKeys[i])
+		}
+		if v.Text == "" {
+			return fmt.Errorf("variant %s is empty", v.Key)
+		}
+		if texts[normalize(v.Text)] {
+			return errors.New("has duplicate variants")
+		}
+		texts[normalize(v.Text)] = true
+	}
+
+	q.Answer = strings.ToUpper(strings.TrimSpace(q.Answer))
+	for _, key := range VariantKeys {
+		if q.Answer == key {
+			return nil
+		}
+	}
+	return fmt.Errorf("has answer %q", q.Answer)
+}
+
+// normalize folds case and spacing so near-identical texts compare equal.
+func normalize(s string) string {
+	return strings.ToLower(strings.Join(strings.Fields(s), " "))
+}
+
+// jsonSchema is the JSON schema of Quiz given to OpenAI compatible servers.
+var jsonSchema = map[string]interface{}{
+	"type":                 "object",
+	"additionalProperties": false,
+	"required":             []string{"questions"},
+	"properties": map[string]interface{}{
+		"questions": map[string]interface{}{
+			"type": "array",
+			"items": map[string]interface{}{
+				"type":                 "object",
+				"additionalProperties": false,
+				"required":             []string{"question", "variants", "answer"},
+				"properties": map[string]interface{}{
+					"question": map[string]interface{}{"type": "string"},
+					"variants": map[string]interface{}{
+						"type":     "array",
+						"minItems": len(VariantKeys),
+						"maxItems": len(VariantKeys),
+						"items": map[string]interface{}{
+							"type":                 "object",
+							"additionalProperties": false,
+							"required":             []string{"key", "text"},
+							"properties": map[string]interface{}{
+								"key":  map[string]interface{}{"type": "string", "enum": VariantKeys},
+								"text": map[string]interface{}{"type": "string"},
+							},
+						},
+					},
+					"answer": map[string]interface{}{"type": "string", "enum": VariantKeys},
+				},
+			},
+		},
+	},
+}
@@ -0,0 +1,109 @@
+package quiz
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// validQuiz returns a valid quiz of count questions.
+func validQuiz(t *testing.T, count int) *Quiz {
+	t.Helper()
+	q, err := Fake{}.Generate(context.Background(), Request{Difficulty: "EASY", Count: count})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return q
+}
+
+func TestQuestionValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(q *Question)
+		ok     bool
+	}{
+		{"valid", func(q *Question) {}, true},
+		{"lower case keys and answer", func(q *Question) {
+			for i := range q.Variants {
+				q.Variants[i].Key = " " + string(rune('a'+i)) + " "
+			}
+			q.Answer = " b "
+		}, true},
+		{"empty question", func(q *Question) { q.Question = "  " }, false},
+		{"three variants", func(q *Question) { q.Variants = q.Variants[:3] }, false},
+		{"five variants", func(q *Question) { q.Variants = append(q.Variants, Variant{Key: "E", Text: "Variant E"}) }, false},
+		{"variants out of order", func(q *Question) { q.Variants[0], q.Variants[1] = q.Variants[1], q.Variants[0] }, false},
+		{"empty variant", func(q *Question) { q.Variants[2].Text = " " }, false},
+		{"duplicate variants", func(q *Question) { q.Variants[3].Text = "  variant   a" }, false},
+		{"unknown answer", func(q *Question) { q.Answer = "E" }, false},
+		{"no answer", func(q *Question) { q.Answer = "" }, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := validQuiz(t, 1).Questions[0]
+			tt.change(&q)
+			err := q.Validate()
+			if tt.ok {
+				if err != nil {
+					t.Fatalf("Validate: %v", err)
+				}
+				for i, v := range q.Variants {
+					if v.Key != VariantKeys[i] {
+						t.Errorf("variant %d key = %q, want %q", i, v.Key, VariantKeys[i])
+					}
+				}
+				return
+			}
+			if !errors.Is(err, ErrInvalidQuiz) {
+				t.Errorf("Validate = %v, want ErrInvalidQuiz", err)
+			}
+		})
+	}
+}
+
+func TestQuizValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(q *Quiz)
+		ok     bool
+	}{
+		{"valid", func(q *Quiz) {}, true},
+		{"too few questions", func(q *Quiz) { q.Questions = q.Questions[:2] }, false},
+		{"duplicate questions", func(q *Quiz) { q.Questions[2].Question = " " + q.Questions[0].Question + " " }, false},
+		{"invalid question", func(q *Quiz) { q.Questions[1].Answer = "X" }, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := validQuiz(t, 3)
+			tt.change(q)
+			err := q.Validate(3)
+			if tt.ok && err != nil {
+				t.Errorf("Validate: %v", err)
+			}
+			if !tt.ok && !errors.Is(err, ErrInvalidQuiz) {
+				t.Errorf("Validate = %v, want ErrInvalidQuiz", err)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	raw := `{"questions": [{"question": "Which gas do plants take in?", "variants": [
+		{"key": "a", "text": "Oxygen"}, {"key": "b", "text": "Carbon dioxide"},
+		{"key": "c", "text": "Helium"}, {"key": "d", "text": "Neon"}], "answer": "b"}]}`
+	q, err := Parse([]byte(raw), 1)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if got := q.AnswerKey()["1"]; got != "B" {
+		t.Errorf("answer = %q, want B", got)
+	}
+
+	for _, raw := range []string{`not json`, `{"questions": []}`} {
+		if _, err := Parse([]byte(raw), 1); !errors.Is(err, ErrInvalidQuiz) {
+			t.Errorf("Parse(%s) = %v, want ErrInvalidQuiz", raw, err)
+		}
+	}
+}
@@ -0,0 +1,302 @@
+// Package quiz keeps generated quizzes as sessions on the server and grades
+// the answers submitted for them, so the answer key never reaches the client
+// and XP is awarded once per quiz.
+package quiz
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+	"worker-bot/xp"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// SessionTTL is how long a quiz can be answered after it was generated.
+const SessionTTL = 30 * time.Minute
+
+const (
+	Easy   = "EASY"
+	Medium = "MEDIUM"
+	Hard   = "HARD"
+)
+
+var (
+	ErrUserNotFound     = errors.New("user not found")
+	ErrSessionNotFound  = errors.New("quiz session not found")
+	ErrSessionExpired   = errors.New("quiz session has expired")
+	ErrAlreadySubmitted = errors.New("quiz session was already submitted")
+	ErrQuizLimit        = errors.New("daily quiz limit reached")
+	ErrQuizCooldown     = errors.New("quizzes are started too often")
+)
+
+// Session is a quiz as sent to the user, without its answer key.
+type Session struct {
+	ID         string `db:"id" json:"id"`
+	UserID     int64  `db:"user_id" json:"user_id"`
+	Difficulty string `db:"difficulty" json:"difficulty"`
+	// EventID is set on the quizzes of events.
+	EventID *string `db:"event_id" json:"event_id,omitempty"`
+	// Questions are the tests of untimed sessions. Timed sessions serve their
+	// tests one at a time, and leave them out.
+	Questions     json.RawMessage `db:"questions" json:"tests" swaggertype:"array,object"`
+	QuestionCount int             `db:"-" json:"question_count"`
+	Timed         bool            `db:"-" json:"timed"`
+	// TimeLimit is the time limit of each question of timed sessions, in
+	// seconds.
+	TimeLimit float64   `db:"-" json:"time_limit,omitempty"`
+	ExpiresAt time.Time `db:"expires_at" json:"expires_at"`
+}
+
+// Result is a graded quiz.
+type Result struct {
+	SessionID    string `json:"session_id"`
+	CorrectCount int    `json:"correct_count"`
+	Total        int    `json:"total"`
+	XPEarned     int64  `json:"xp_earned"`
+	// Answers is the answer key by question number, sent once graded.
+	Answers map[string]string `json:"answers"`
+	// Ratings are the updated ratings of the user by topic.
+	Ratings map[string]int `json:"ratings,omitempty"`
+	// Multipliers are the XP multipliers applied, by name.
+	Multipliers []string `json:"multipliers,omitempty"`
+	// Capped reports whether the daily quiz XP cap cut the reward.
+	Capped bool `json:"capped,omitempty"`
+	// Late is the number of questions of a timed session answered after the
+	// time limit, or not at all, counted wrong.
+	Late int `json:"late,omitempty"`
+}
+
+// Grade is the score of a submitted quiz.
+type Grade struct {
+	Difficulty string
+	// EventID is set on the quizzes of events.
+	EventID *string
+	Correct int
+	Total   int
+	// Weighted is the number of correct answers weighted by the difficulty
+	// of the questions relative to the rating of the user, from 0 for far
+	// easier questions through 1 for questions at the user's rating to 2 for
+	// far harder ones. Unrated quizzes weigh every correct answer 1.
+	Weighted float64
+	// Speed is the sum over correct answers of the share of their time limit
+	// left, 0 for untimed quizzes.
+	Speed float64
+}
+
+// Reward returns the base XP earned for a graded quiz, before the
+// multipliers and caps of the XP rules.
+type Reward func(g Grade) float64
+
+type Store struct {
+	db     *sqlx.DB
+	rules  *xp.Engine
+	limits TimeLimits
+}
+
+func NewStore(db *sqlx.DB, rules *xp.Engine, limits TimeLimits) *Store {
+	return &Store{db: db, rules: rules, limits: limits}
+}
+
+// Create stores a quiz drawn for a user. The answer key stays with the
+// session. Quizzes of a difficulty with a time limit are timed; the user is
+// shown the tests of others at once. Frozen accounts cannot start quizzes, and quizzes are
+// started within the limits of the XP rules.
+func (s *Store) Create(ctx context.Context, userID int64, difficulty string, q *Quiz) (Session, error) {
+	rawQuestions, rawAnswers, err := marshalQuiz(q)
+	if err != nil {
+		return Session{}, err
+	}
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return Session{}, err
+	}
+	defer tx.Rollback()
+
+	if err := s.checkLimits(ctx, tx, userID); err != nil {
+		return Session{}, err
+	}
+	var session Session
+	err = tx.GetContext(ctx, &session, `INSERT INTO quiz_sessions (id, user_id, difficulty, questions, answer_key, question_ids, expires_at)
+				VALUES ($1, $2, $3, $4, $5, $6, CURRENT_TIMESTAMP + $7 * INTERVAL '1 second')
+				RETURNING id, user_id, difficulty, questions, expires_at`,
+		uuid.NewString(), userID, difficulty, rawQuestions, rawAnswers, pq.Array(q.IDs()), SessionTTL.Seconds())
+	if err != nil {
+		return Session{}, err
+	}
+	if err := s.startTiming(ctx, tx, &session, q); err != nil {
+		return Session{}, err
+	}
+	return session, tx.Commit()
+}
+
+// checkLimits locks a user and checks that the user can start a quiz. The lock
+// keeps concurrent requests within the limits.
+func (s *Store) checkLimits(ctx context.Context, tx *sqlx.Tx, userID int64) error {
+	limits := s.rules.Active().Rules.Limits
+	var state struct {
+		Frozen  bool `db:"frozen"`
+		Today   int  `db:"today"`
+		Cooling bool `db:"cooling"`
+	}
+	err := tx.GetContext(ctx, &state, `SELECT u.frozen_at IS NOT NULL AS frozen,
+				(SELECT COUNT(*) FROM quiz_sessions s WHERE s.user_id = u.id AND s.event_id IS NULL
+					AND s.created_at >= $2) AS today,
+				COALESCE((SELECT CURRENT_TIMESTAMP - MAX(s.created_at) < $3 * INTERVAL '1 second' FROM quiz_sessions s
+					WHERE s.user_id = u.id AND s.event_id IS NULL), false) AS cooling
+				FROM users u WHERE u.id = $1 FOR UPDATE OF u`, userID, xp.DayStart(time.Now()), limits.QuizCooldown)
+	switch {
+	case err == sql.ErrNoRows:
+		return ErrUserNotFound
+	case err != nil:
+		return err
+	case state.Frozen:
+		return xp.ErrAccountFrozen
+	case limits.QuizzesPerDay > 0 && state.Today >= limits.QuizzesPerDay:
+		return ErrQuizLimit
+	case limits.QuizCooldown > 0 && state.Cooling:
+		return ErrQuizCooldown
+	}
+	return nil
+}
+
+// marshalQuiz returns the tests and the answer key of a quiz as stored in a
+// session.
+func marshalQuiz(q *Quiz) (questions, answers []byte, err error) {
+	if questions, err = json.Marshal(q.Tests()); err != nil {
+		return nil, nil, err
+	}
+	if answers, err = json.Marshal(q.AnswerKey()); err != nil {
+		return nil, nil, err
+	}
+	return questions, answers, nil
+}
+
+// Submit grades a user's answers, by question number, to a session and
+// credits the XP reward gives for them under the XP rules. A session is
+// graded once, and not after it expired. The quiz of an event is graded once
+// per user. Timed sessions are graded on the answers given as their questions
+// were served; answers submitted for served questions not answered yet count
+// as given now, and those to questions never served are ignored.
+func (s *Store) Submit(ctx context.Context, userID int64, sessionID string, answers map[string]string, reward Reward) (Result, error) {
+	if _, err := uuid.Parse(sessionID); err != nil {
+		return Result{}, ErrSessionNotFound
+	}
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return Result{}, err
+	}
+	defer tx.Rollback()
+
+	// The quiz of an event is submitted under the lock of the registration,
+	// so concurrent sessions of it see each other's submission.
+	_, err = tx.ExecContext(ctx, `SELECT 1 FROM event_registrations r JOIN quiz_sessions s
+					ON s.user_id = r.user_id AND s.event_id = r.event_id
+				WHERE s.id = $1 AND s.user_id = $2 FOR UPDATE OF r`, sessionID, userID)
+	if err != nil {
+		return Result{}, err
+	}
+
+	var session struct {
+		Difficulty string          `db:"difficulty"`
+		EventID    *string         `db:"event_id"`
+		AnswerKey  json.RawMessage `db:"answer_key"`
+		// QuestionIDs is empty for event quizzes and sessions started before
+		// ratings, which are not rated.
+		QuestionIDs pq.StringArray `db:"question_ids"`
+		Timed       bool           `db:"timed"`
+		Submitted   bool           `db:"submitted"`
+		Expired     bool           `db:"expired"`
+	}
+	err = tx.GetContext(ctx, &session, `SELECT difficulty, event_id, answer_key, question_ids, timed,
+				submitted_at IS NOT NULL OR EXISTS (SELECT 1 FROM quiz_sessions o WHERE o.user_id = s.user_id
+					AND o.event_id = s.event_id AND o.submitted_at IS NOT NULL) AS submitted,
+				expires_at <= CURRENT_TIMESTAMP AS expired
+				FROM quiz_sessions s WHERE id = $1 AND user_id = $2 FOR UPDATE`, sessionID, userID)
+	switch {
+	case err == sql.ErrNoRows:
+		return Result{}, ErrSessionNotFound
+	case err != nil:
+		return Result{}, err
+	case session.Submitted:
+		return Result{}, ErrAlreadySubmitted
+	case session.Expired:
+		return Result{}, ErrSessionExpired
+	}
+
+	result := Result{SessionID: sessionID}
+	if err := json.Unmarshal(session.AnswerKey, &result.Answers); err != nil {
+		return Result{}, err
+	}
+	result.Total = len(result.Answers)
+	var timings []timing
+	if session.Timed {
+		if timings, err = recordTimings(ctx, tx, sessionID, answers); err != nil {
+			return Result{}, err
+		}
+		answers = make(map[string]string, len(timings))
+		for _, t := range timings {
+			if t.Answer == nil || t.Late {
+				result.Late++
+				continue
+			}
+			answers[strconv.Itoa(t.Number)] = *t.Answer
+		}
+	}
+	correct := make([]bool, result.Total)
+	for i := range correct {
+		number := strconv.Itoa(i + 1)
+		correct[i] = strings.EqualFold(strings.TrimSpace(answers[number]), result.Answers[number])
+		if correct[i] {
+			result.CorrectCount++
+		}
+	}
+	grade := Grade{
+		Difficulty: session.Difficulty,
+		EventID:    session.EventID,
+		Correct:    result.CorrectCount,
+		Total:      result.Total,
+		Weighted:   float64(result.CorrectCount),
+	}
+	for _, t := range timings {
+		if t.Number <= len(correct) && correct[t.Number-1] && t.Seconds != nil && t.TimeLimit > 0 {
+			grade.Speed += max(1-*t.Seconds/t.TimeLimit, 0)
+		}
+	}
+	if session.Timed {
+		if err := markCorrect(ctx, tx, sessionID, correct); err != nil {
+			return Result{}, err
+		}
+	}
+	if len(session.QuestionIDs) > 0 {
+		grade.Weighted, result.Ratings, err = rate(ctx, tx, userID, session.QuestionIDs, correct)
+		if err != nil {
+			return Result{}, err
+		}
+	}
+	if base := reward(grade); base > 0 {
+		award, err := s.rules.Award(ctx, tx, userID, base, xp.SourceQuiz, sessionID)
+		if err != nil {
+			return Result{}, err
+		}
+		result.XPEarned, result.Multipliers, result.Capped = award.Amount, award.Multipliers, award.Capped
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE quiz_sessions SET submitted_at = CURRENT_TIMESTAMP, correct_count = $2, xp_earned = $3
+				WHERE id = $1`, sessionID, result.CorrectCount, result.XPEarned)
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+		// quiz_sessions_event_submitted_idx: the quiz of the event was
+		// submitted by another session.
+		return Result{}, ErrAlreadySubmitted
+	}
+	if err != nil {
+		return Result{}, err
+	}
+	return result, tx.Commit()
+}
@@ -0,0 +1,311 @@
+package quiz
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+var (
+	ErrNotTimed         = errors.New("quiz session is not timed")
+	ErrNoSuchQuestion   = errors.New("quiz session has no such question")
+	ErrNotServed        = errors.New("question was not served yet")
+	ErrAlreadyAnswered  = errors.New("question was already answered")
+	ErrInvalidAnswer    = fmt.Errorf("answer must be one of %s", strings.Join(VariantKeys, ", "))
+	ErrInvalidTimeLimit = errors.New("invalid time limits")
+)
+
+// TimeLimits are the time limits of quiz questions by difficulty. Quizzes of
+// a difficulty without a limit are untimed.
+type TimeLimits map[string]time.Duration
+
+// ParseTimeLimits reads time limits written as EASY=30s,MEDIUM=45s,HARD=1m.
+// The questions of a quiz must fit in SessionTTL.
+func ParseTimeLimits(s string) (TimeLimits, error) {
+	limits := TimeLimits{}
+	for _, field := range strings.Split(s, ",") {
+		if strings.TrimSpace(field) == "" {
+			continue
+		}
+		difficulty, raw, ok := strings.Cut(field, "=")
+		difficulty = strings.ToUpper(strings.TrimSpace(difficulty))
+		if !ok || !contains(difficulties, difficulty) {
+			return nil, fmt.Errorf("%w: %q is not DIFFICULTY=duration", ErrInvalidTimeLimit, field)
+		}
+		limit, err := time.ParseDuration(strings.TrimSpace(raw))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidTimeLimit, err)
+		}
+		if limit <= 0 || limit*QuestionCount > SessionTTL {
+			return nil, fmt.Errorf("%w: %s questions must take between 0 and %s", ErrInvalidTimeLimit, difficulty, SessionTTL/QuestionCount)
+		}
+		limits[difficulty] = limit
+	}
+	return limits, nil
+}
+
+// Served is a question of a timed session as served to the user.
+type Served struct {
+	Number int `db:"number" json:"number"`
+	// Test is the question and its variants, as in the tests of untimed
+	// sessions.
+	Test     json.RawMessage `db:"test" json:"test" swaggertype:"object"`
+	ServedAt time.Time       `db:"served_at" json:"served_at"`
+	// Deadline is when the time limit runs out. Later answers count wrong.
+	Deadline time.Time `db:"deadline" json:"deadline"`
+	// TimeLimit is the time limit in seconds.
+	TimeLimit float64 `db:"time_limit" json:"time_limit"`
+	Answered  bool    `db:"answered" json:"answered"`
+}
+
+// Answered is an answer recorded for a question of a timed session. Whether
+// it is correct is told when the session is submitted.
+type Answered struct {
+	Number     int       `db:"number" json:"number"`
+	AnsweredAt time.Time `db:"answered_at" json:"answered_at"`
+	// Late reports that the answer came after the time limit and counts
+	// wrong.
+	Late bool `db:"late" json:"late"`
+}
+
+// timing is when a question of a timed session was answered.
+type timing struct {
+	Number int     `db:"number"`
+	Answer *string `db:"answer"`
+	Late   bool    `db:"late"`
+	// Seconds is the time taken to answer, nil for questions not answered.
+	Seconds   *float64 `db:"seconds"`
+	TimeLimit float64  `db:"time_limit"`
+}
+
+// startTiming makes a new session timed when its difficulty has a time limit:
+// its questions are recorded to be served one at a time, and the tests are
+// left out of the session returned.
+func (s *Store) startTiming(ctx context.Context, tx *sqlx.Tx, session *Session, q *Quiz) error {
+	session.QuestionCount = len(q.Questions)
+	limit, ok := s.limits[session.Difficulty]
+	if !ok {
+		return nil
+	}
+	_, err := tx.ExecContext(ctx, `UPDATE quiz_sessions SET timed = true WHERE id = $1`, session.ID)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `INSERT INTO quiz_answers (session_id, number, question_id, time_limit)
+				SELECT $1, number, NULLIF(id, '')::uuid, $3 * INTERVAL '1 second'
+				FROM unnest($2::text[]) WITH ORDINALITY AS ids (id, number)`,
+		session.ID, pq.Array(q.IDs()), limit.Seconds())
+	if err != nil {
+		return err
+	}
+	session.Timed = true
+	session.TimeLimit = limit.Seconds()
+	session.Questions = json.RawMessage("[]")
+	return nil
+}
+
+// timedSession checks that a timed session of a user can still be answered.
+// It shares the session's lock, so answers wait for a submission in progress.
+func timedSession(ctx context.Context, tx *sqlx.Tx, userID int64, sessionID string) error {
+	if _, err := uuid.Parse(sessionID); err != nil {
+		return ErrSessionNotFound
+	}
+	var session struct {
+		Timed     bool `db:"timed"`
+		Submitted bool `db:"submitted"`
+		Expired   bool `db:"expired"`
+	}
+	err := tx.GetContext(ctx, &session, `SELECT timed, submitted_at IS NOT NULL AS submitted,
+				expires_at <= CURRENT_TIMESTAMP AS expired
+				FROM quiz_sessions WHERE id = $1 AND user_id = $2 FOR SHARE`, sessionID, userID)
+	switch {
+	case err == sql.ErrNoRows:
+		return ErrSessionNotFound
+	case err != nil:
+		return err
+	case session.Submitted:
+		return ErrAlreadySubmitted
+	case session.Expired:
+		return ErrSessionExpired
+	case !session.Timed:
+		return ErrNotTimed
+	}
+	return nil
+}
+
+// Serve serves a question of a timed session, by number, starting its time
+// limit. Serving a question again returns it with the same deadline.
+func (s *Store) Serve(ctx context.Context, userID int64, sessionID string, number int) (Served, error) {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return Served{}, err
+	}
+	defer tx.Rollback()
+
+	if err := timedSession(ctx, tx, userID, sessionID); err != nil {
+		return Served{}, err
+	}
+	var served Served
+	err = tx.GetContext(ctx, &served, `WITH served AS (
+					UPDATE quiz_answers SET served_at = COALESCE(served_at, CURRENT_TIMESTAMP)
+					WHERE session_id = $1 AND number = $2
+					RETURNING number, served_at, served_at + time_limit AS deadline,
+						EXTRACT(EPOCH FROM time_limit)::double precision AS time_limit, answered_at IS NOT NULL AS answered
+				)
+				SELECT served.*, s.questions -> (served.number - 1) AS test
+				FROM served, quiz_sessions s WHERE s.id = $1`, sessionID, number)
+	if err == sql.ErrNoRows {
+		return served, ErrNoSuchQuestion
+	}
+	if err != nil {
+		return served, err
+	}
+	return served, tx.Commit()
+}
+
+// Answer records the answer to a served question of a timed session. Each
+// question is answered once.
+func (s *Store) Answer(ctx context.Context, userID int64, sessionID string, number int, answer string) (Answered, error) {
+	answer = strings.ToUpper(strings.TrimSpace(answer))
+	if !contains(VariantKeys, answer) {
+		return Answered{}, ErrInvalidAnswer
+	}
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return Answered{}, err
+	}
+	defer tx.Rollback()
+
+	if err := timedSession(ctx, tx, userID, sessionID); err != nil {
+		return Answered{}, err
+	}
+	var state struct {
+		Served   bool `db:"served"`
+		Answered bool `db:"answered"`
+	}
+	err = tx.GetContext(ctx, &state, `SELECT served_at IS NOT NULL AS served, answered_at IS NOT NULL AS answered
+				FROM quiz_answers WHERE session_id = $1 AND number = $2 FOR UPDATE`, sessionID, number)
+	switch {
+	case err == sql.ErrNoRows:
+		return Answered{}, ErrNoSuchQuestion
+	case err != nil:
+		return Answered{}, err
+	case !state.Served:
+		return Answered{}, ErrNotServed
+	case state.Answered:
+		return Answered{}, ErrAlreadyAnswered
+	}
+
+	var answered Answered
+	err = tx.GetContext(ctx, &answered, `UPDATE quiz_answers SET answer = $3, answered_at = CURRENT_TIMESTAMP
+				WHERE session_id = $1 AND number = $2
+				RETURNING number, answered_at, answered_at > served_at + time_limit AS late`, sessionID, number, answer)
+	if err != nil {
+		return answered, err
+	}
+	return answered, tx.Commit()
+}
+
+// recordTimings records the answers submitted with a timed session for the
+// served questions not answered yet, as answered now, and returns when every
+// question of the session was answered.
+func recordTimings(ctx context.Context, tx *sqlx.Tx, sessionID string, answers map[string]string) ([]timing, error) {
+	var numbers []int64
+	var values []string
+	for number, answer := range answers {
+		n, err := strconv.ParseInt(number, 10, 64)
+		if err != nil {
+			continue
+		}
+		answer = strings.ToUpper(strings.TrimSpace(answer))
+		if contains(VariantKeys, answer) {
+			numbers = append(numbers, n)
+			values = append(values, answer)
+		}
+	}
+	_, err := tx.ExecContext(ctx, `UPDATE quiz_answers a SET answer = x.answer, answered_at = CURRENT_TIMESTAMP
+				FROM unnest($2::integer[], $3::text[]) AS x (number, answer)
+				WHERE a.session_id = $1 AND a.number = x.number AND a.served_at IS NOT NULL AND a.answered_at IS NULL`,
+		sessionID, pq.Array(numbers), pq.Array(values))
+	if err != nil {
+		return nil, err
+	}
+
+	var timings []timing
+	err = tx.SelectContext(ctx, &timings, `SELECT number, answer, COALESCE(answered_at > served_at + time_limit, false) AS late,
+				EXTRACT(EPOCH FROM answered_at - served_at)::double precision AS seconds,
+				EXTRACT(EPOCH FROM time_limit)::double precision AS time_limit
+				FROM quiz_answers WHERE session_id = $1 ORDER BY number`, sessionID)
+	return timings, err
+}
+
+// markCorrect records which questions of a graded timed session were answered
+// correctly, by question number from 1.
+func markCorrect(ctx context.Context, tx *sqlx.Tx, sessionID string, correct []bool) error {
+	_, err := tx.ExecContext(ctx, `UPDATE quiz_answers SET correct = COALESCE(($2::boolean[])[number], false)
+				WHERE session_id = $1`, sessionID, pq.BoolArray(correct))
+	return err
+}
+
+// minTimedAnswers is the number of times a question is served before its
+// difficulty is suggested from them.
+const minTimedAnswers = 20
+
+// QuestionTiming is how a bank question fared in timed sessions. Questions
+// served and not answered by the time the session was submitted count as
+// answered late.
+type QuestionTiming struct {
+	ID         string `db:"id" json:"id"`
+	Difficulty string `db:"difficulty" json:"difficulty"`
+	Topic      string `db:"topic" json:"topic"`
+	Question   string `db:"question" json:"question"`
+	// Served is the number of graded sessions the question was served in.
+	Served int64 `db:"served" json:"served"`
+	// CorrectRate is the share of correct answers, in time.
+	CorrectRate float64 `db:"correct_rate" json:"correct_rate"`
+	// LateRate is the share of answers after the time limit, or missing.
+	LateRate float64 `db:"late_rate" json:"late_rate"`
+	// TimeUsed is the mean share of the time limit taken to answer, up to 1.
+	TimeUsed float64 `db:"time_used" json:"time_used"`
+	// MedianSeconds is the median time taken to answer, nil when the question
+	// was never answered.
+	MedianSeconds *float64 `db:"median_seconds" json:"median_seconds"`
+	// SuggestedDifficulty is the difficulty the answers point to, once the
+	// question was served often enough: EASY when most answer it correctly
+	// with time to spare, HARD when most fail or take nearly all the time.
+	SuggestedDifficulty *string `db:"suggested_difficulty" json:"suggested_difficulty"`
+}
+
+// TimingsQuery selects QuestionTimings, to be filtered on their columns.
+var TimingsQuery = fmt.Sprintf(`SELECT * FROM (SELECT *, CASE WHEN served < %d THEN NULL
+					WHEN correct_rate >= 0.75 AND time_used < 0.5 THEN '%s'
+					WHEN correct_rate < 0.4 OR time_used >= 0.8 THEN '%s'
+					ELSE '%s' END AS suggested_difficulty
+				FROM (SELECT q.id, q.difficulty, q.topic, q.question, COUNT(*) AS served,
+						AVG(CASE WHEN a.correct THEN 1 ELSE 0 END)::double precision AS correct_rate,
+						AVG(CASE WHEN a.answered_at IS NULL OR a.answered_at > a.served_at + a.time_limit
+							THEN 1 ELSE 0 END)::double precision AS late_rate,
+						AVG(LEAST(COALESCE(EXTRACT(EPOCH FROM a.answered_at - a.served_at) / EXTRACT(EPOCH FROM a.time_limit), 1),
+							1))::double precision AS time_used,
+						percentile_cont(0.5) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM a.answered_at - a.served_at)) AS median_seconds
+					FROM quiz_answers a JOIN questions q ON q.id = a.question_id
+					WHERE a.served_at IS NOT NULL AND a.correct IS NOT NULL
+					GROUP BY q.id) stats) timings`, minTimedAnswers, Easy, Hard, Medium)
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
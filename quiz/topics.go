@@ -0,0 +1,47 @@
+package quiz
+
+// Topics of the taxonomy questions are filed under. Questions spanning
+// several topics, and those written before the taxonomy, are DefaultTopic.
+const (
+	TopicWaste        = "waste"
+	TopicWater        = "water"
+	TopicAir          = "air"
+	TopicClimate      = "climate"
+	TopicBiodiversity = "biodiversity"
+	TopicEnergy       = "energy"
+)
+
+// Topic is a topic of the taxonomy.
+type Topic struct {
+	Key string `json:"key"`
+	// Name is shown to users.
+	Name string `json:"name"`
+	// Subject describes the topic to language models.
+	Subject string `json:"-"`
+}
+
+// Topics is the taxonomy, in the order it is shown.
+var Topics = []Topic{
+	{Key: TopicWaste, Name: "Chiqindilar va qayta ishlash", Subject: "waste, recycling, composting and pollution by litter"},
+	{Key: TopicWater, Name: "Suv", Subject: "fresh water, oceans, water pollution and saving water"},
+	{Key: TopicAir, Name: "Havo", Subject: "air quality, air pollution and the ozone layer"},
+	{Key: TopicClimate, Name: "Iqlim", Subject: "climate change, greenhouse gases and adaptation"},
+	{Key: TopicBiodiversity, Name: "Biologik xilma-xillik", Subject: "biodiversity, ecosystems, species and habitats"},
+	{Key: TopicEnergy, Name: "Energiya", Subject: "energy sources, renewable energy and saving energy"},
+}
+
+// FindTopic returns the topic of the taxonomy with a key.
+func FindTopic(key string) (Topic, bool) {
+	for _, t := range Topics {
+		if t.Key == key {
+			return t, true
+		}
+	}
+	return Topic{}, false
+}
+
+// ValidTopic reports whether key is DefaultTopic or a topic of the taxonomy.
+func ValidTopic(key string) bool {
+	_, ok := FindTopic(key)
+	return ok || key == DefaultTopic
+}
@@ -0,0 +1,134 @@
+package quiz
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// MaxImportRows bounds the number of questions in one import.
+const MaxImportRows = 1000
+
+// csvColumns are the columns of exported CSV files. Imports need the
+// difficulty, question, variant and answer columns and ignore id, status and
+// source, so exported files can be imported again.
+var csvColumns = []string{"id", "status", "source", "difficulty", "topic", "language", "question", "a", "b", "c", "d", "answer"}
+
+var requiredCSVColumns = []string{"difficulty", "question", "a", "b", "c", "d", "answer"}
+
+// ReadCSV reads the questions of a CSV import with a header row. Rows that
+// cannot be read are reported as row errors; an error is returned when the
+// file itself is unusable.
+func ReadCSV(r io.Reader) ([]ImportRow, []RowError, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil, errors.New("file is empty")
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		// Spreadsheets may start the file with a byte order mark.
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		columns[name] = i
+	}
+	for _, name := range requiredCSVColumns {
+		if _, ok := columns[name]; !ok {
+			return nil, nil, fmt.Errorf("missing column %q", name)
+		}
+	}
+	field := func(record []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return record[i]
+	}
+
+	var rows []ImportRow
+	var rowErrors []RowError
+	for n := 1; ; n++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		if n > MaxImportRows {
+			return nil, nil, fmt.Errorf("more than %d rows", MaxImportRows)
+		}
+		if len(record) != len(header) {
+			rowErrors = append(rowErrors, RowError{Row: n, Error: fmt.Sprintf("has %d fields instead of %d", len(record), len(header))})
+			continue
+		}
+
+		in := QuestionInput{
+			Difficulty: field(record, "difficulty"),
+			Topic:      field(record, "topic"),
+			Language:   field(record, "language"),
+			Question:   Question{Question: field(record, "question"), Answer: field(record, "answer")},
+		}
+		for _, key := range VariantKeys {
+			in.Variants = append(in.Variants, Variant{Key: key, Text: field(record, strings.ToLower(key))})
+		}
+		rows = append(rows, ImportRow{Row: n, Input: in})
+	}
+	return rows, rowErrors, nil
+}
+
+// ReadJSON reads the questions of a JSON import, an array of questions as
+// written by Export.
+func ReadJSON(r io.Reader) ([]ImportRow, []RowError, error) {
+	var records []json.RawMessage
+	if err := json.NewDecoder(r).Decode(&records); err != nil {
+		return nil, nil, fmt.Errorf("file must be a JSON array of questions: %v", err)
+	}
+	if len(records) > MaxImportRows {
+		return nil, nil, fmt.Errorf("more than %d rows", MaxImportRows)
+	}
+
+	var rows []ImportRow
+	var rowErrors []RowError
+	for i, record := range records {
+		var in QuestionInput
+		if err := json.Unmarshal(record, &in); err != nil {
+			rowErrors = append(rowErrors, RowError{Row: i + 1, Error: err.Error()})
+			continue
+		}
+		rows = append(rows, ImportRow{Row: i + 1, Input: in})
+	}
+	return rows, rowErrors, nil
+}
+
+// WriteCSV writes questions as CSV with a header row.
+func WriteCSV(w io.Writer, questions []BankQuestion) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvColumns); err != nil {
+		return err
+	}
+	for _, q := range questions {
+		record := []string{q.ID, q.Status, q.Source, q.Difficulty, q.Topic, q.Language, q.Question}
+		for i := range VariantKeys {
+			text := ""
+			if i < len(q.Variants) {
+				text = q.Variants[i].Text
+			}
+			record = append(record, text)
+		}
+		record = append(record, q.Answer)
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,95 @@
+package quiz
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// Translator translates texts, returning one translation per text in order.
+type Translator func(ctx context.Context, texts []string) ([]string, error)
+
+// TranslateUzbek translates English texts to Uzbek with the Tahrirchi API.
+func TranslateUzbek(ctx context.Context, texts []string) ([]string, error) {
+	url := "https://websocket.tahrirchi.uz/translate"
+	payload := map[string]interface{}{
+		"text": map[string]interface{}{
+			"texts": texts,
+		},
+		"source_lang": "eng_Latn",
+		"target_lang": "uzn_Latn",
+	}
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(payloadBytes))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "389eebc7-4e87-4c59-b0c0-d1a1f1c0aacc")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Sentences []struct {
+			Translated *string `json:"translated"`
+		} `json:"sentences"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, err
+	}
+	translated := make([]string, len(response.Sentences))
+	for i, sentence := range response.Sentences {
+		if sentence.Translated == nil {
+			return nil, fmt.Errorf("unexpected sentence format")
+		}
+		translated[i] = *sentence.Translated
+	}
+	return translated, nil
+}
+
+// Translate translates the questions and variants of a quiz in one request.
+func (q *Quiz) Translate(ctx context.Context, translate Translator) error {
+	var texts []string
+	for _, question := range q.Questions {
+		texts = append(texts, question.Question)
+		for _, variant := range question.Variants {
+			texts = append(texts, variant.Text)
+		}
+	}
+
+	translated, err := translate(ctx, texts)
+	if err != nil {
+		return err
+	}
+	if len(translated) != len(texts) {
+		return fmt.Errorf("got %d translations for %d texts", len(translated), len(texts))
+	}
+
+	i := 0
+	for j := range q.Questions {
+		question := &q.Questions[j]
+		question.Question = translated[i]
+		i++
+		for k := range question.Variants {
+			question.Variants[k].Text = translated[i]
+			i++
+		}
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+// Package webauth identifies the users of the web app by the init data
+// Telegram passes to it. The init data is signed with the bot token, so the
+// user it names cannot be forged by the client.
+package webauth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalid = errors.New("invalid init data")
+	ErrExpired = errors.New("init data has expired")
+)
+
+// Verifier checks init data against the bot token.
+type Verifier struct {
+	key    []byte
+	maxAge time.Duration
+}
+
+// NewVerifier returns a Verifier for the web app of the bot with botToken.
+// Init data older than maxAge is refused.
+func NewVerifier(botToken string, maxAge time.Duration) *Verifier {
+	mac := hmac.New(sha256.New, []byte("WebAppData"))
+	mac.Write([]byte(botToken))
+	return &Verifier{key: mac.Sum(nil), maxAge: maxAge}
+}
+
+// Verify checks the hash and age of init data, as sent in
+// Telegram.WebApp.initData, and returns the ID of the user it names.
+func (v *Verifier) Verify(initData string, now time.Time) (int64, error) {
+	values, err := url.ParseQuery(initData)
+	if err != nil {
+		return 0, ErrInvalid
+	}
+	hash, err := hex.DecodeString(values.Get("hash"))
+	if err != nil || len(hash) == 0 {
+		return 0, ErrInvalid
+	}
+
+	// The data check string is every field but hash, sorted by key, as
+	// key=value lines.
+	fields := make([]string, 0, len(values))
+	for key := range values {
+		if key != "hash" {
+			fields = append(fields, key+"="+values.Get(key))
+		}
+	}
+	sort.Strings(fields)
+	mac := hmac.New(sha256.New, v.key)
+	mac.Write([]byte(strings.Join(fields, "\n")))
+	if !hmac.Equal(hash, mac.Sum(nil)) {
+		return 0, ErrInvalid
+	}
+
+	authDate, err := strconv.ParseInt(values.Get("auth_date"), 10, 64)
+	if err != nil {
+		return 0, ErrInvalid
+	}
+	if now.Sub(time.Unix(authDate, 0)) > v.maxAge {
+		return 0, ErrExpired
+	}
+
+	var user struct {
+		ID int64 `json:"id"`
+	}
+	if err := json.Unmarshal([]byte(values.Get("user")), &user); err != nil || user.ID == 0 {
+		return 0, ErrInvalid
+	}
+	return user.ID, nil
+}
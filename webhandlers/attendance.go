@@ -0,0 +1,271 @@
+package webhandlers
+
+import (
+	"database/sql"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+	"worker-bot/events"
+	"worker-bot/models"
+	"worker-bot/ticket"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"github.com/skip2/go-qrcode"
+)
+
+const qrSize = 512
+
+// attendanceRow is a registration locked for check-in or check-out.
+type attendanceRow struct {
+	ID           int64      `db:"id"`
+	Status       string     `db:"status"`
+	CheckedInAt  *time.Time `db:"checked_in_at"`
+	CheckedOutAt *time.Time `db:"checked_out_at"`
+	EventEnded   bool       `db:"event_ended"`
+	EventStatus  string     `db:"event_status"`
+}
+
+func lockAttendance(tx *sqlx.Tx, eventID string, userID int64) (attendanceRow, error) {
+	var row attendanceRow
+	err := tx.Get(&row, `SELECT r.id, r.status, r.checked_in_at, r.checked_out_at,
+				e.end_date <= CURRENT_TIMESTAMP AS event_ended, e.status AS event_status
+			  FROM event_registrations r JOIN events e ON e.id = r.event_id
+			  WHERE r.event_id = $1 AND r.user_id = $2 FOR UPDATE OF r`, eventID, userID)
+	return row, err
+}
+
+// scanTicket binds and verifies the ticket of a check-in or check-out request,
+// writing the error response itself when it returns false.
+func (h *HandlerV1) scanTicket(c *gin.Context) (ticket.Claims, bool) {
+	var scan models.TicketScan
+	if err := c.ShouldBindJSON(&scan); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return ticket.Claims{}, false
+	}
+
+	claims, err := h.tickets.Verify(scan.Token, time.Now())
+	switch {
+	case err == ticket.ErrExpired:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "QR code has expired, ask the participant to refresh it"})
+		return claims, false
+	case err != nil:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid QR code"})
+		return claims, false
+	case claims.EventID != c.Param("id"):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "QR code is for another event"})
+		return claims, false
+	}
+	return claims, true
+}
+
+// @Summary     Get Event Ticket
+// @Description This API returns the check-in QR code of a registered participant as a PNG image, or as JSON with format=json. The code expires after a few minutes and is shown to an officer at check-in and check-out.
+// @Tags         Attendance
+// @Produce      png
+// @Produce      json
+// @Param        Authorization header string true "tma and the web app init data"
+// @Param        id       path  int    true  "User ID"
+// @Param        eventId  path  string true  "Event ID"
+// @Param        format   query string false "png (default) or json" Enums(png, json)
+// @Success      200  {object} models.Ticket
+// @Failure      401  {object} ErrorResponse
+// @Failure      403  {object} ErrorResponse
+// @Failure      404  {object} ErrorResponse
+// @Failure      409  {object} ErrorResponse
+// @Failure      500  {object} ErrorResponse
+// @Router       /user/{id}/events/{eventId}/ticket [get]
+func (h *HandlerV1) GetEventTicket(c *gin.Context) {
+	userID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+	eventID := c.Param("eventId")
+
+	var row attendanceRow
+	err = h.db.Get(&row, `SELECT r.id, r.status, r.checked_in_at, r.checked_out_at,
+				e.end_date <= CURRENT_TIMESTAMP AS event_ended, e.status AS event_status
+			  FROM event_registrations r JOIN events e ON e.id = r.event_id
+			  WHERE r.event_id = $1 AND r.user_id = $2`, eventID, userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Registration not found"})
+		} else {
+			log.Printf("Error fetching registration: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error creating ticket"})
+		}
+		return
+	}
+	switch {
+	case row.Status != models.RegistrationRegistered:
+		c.JSON(http.StatusConflict, gin.H{"error": "You are on the waitlist for this event"})
+		return
+	case row.EventStatus == models.EventCancelled:
+		c.JSON(http.StatusConflict, gin.H{"error": "Event was cancelled"})
+		return
+	case row.CheckedOutAt != nil || row.EventEnded || row.EventStatus == models.EventCompleted:
+		c.JSON(http.StatusConflict, gin.H{"error": "Event is over"})
+		return
+	}
+
+	token, claims := h.tickets.Issue(eventID, userID, time.Now())
+	if c.Query("format") == "json" {
+		c.JSON(http.StatusOK, models.Ticket{Token: token, ExpiresAt: claims.ExpiresAt})
+		return
+	}
+
+	png, err := qrcode.Encode(token, qrcode.Medium, qrSize)
+	if err != nil {
+		log.Printf("Error encoding QR code: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error creating ticket"})
+		return
+	}
+	c.Header("Cache-Control", "no-store")
+	c.Data(http.StatusOK, "image/png", png)
+}
+
+// @Summary     Check In
+// @Description This API checks a participant in by their scanned QR code. Officers of the event and admins only.
+// @Tags         Attendance
+// @Accept       json
+// @Produce      json
+// @Param        Authorization header string            true "tma and the web app init data"
+// @Param        id         path   string            true "Event ID"
+// @Param        ticket     body   models.TicketScan true "Scanned QR code"
+// @Success      200  {object} models.Attendance
+// @Failure      400  {object} ErrorResponse
+// @Failure      401  {object} ErrorResponse
+// @Failure      403  {object} ErrorResponse
+// @Failure      404  {object} ErrorResponse
+// @Failure      409  {object} ErrorResponse
+// @Failure      500  {object} ErrorResponse
+// @Router       /event/{id}/checkin [post]
+func (h *HandlerV1) CheckIn(c *gin.Context) {
+	claims, ok := h.scanTicket(c)
+	if !ok {
+		return
+	}
+
+	tx, err := h.db.Beginx()
+	if err != nil {
+		log.Printf("Error starting transaction: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error checking in"})
+		return
+	}
+	defer tx.Rollback()
+
+	row, err := lockAttendance(tx, claims.EventID, claims.UserID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Registration not found"})
+		} else {
+			log.Printf("Error fetching registration: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error checking in"})
+		}
+		return
+	}
+	switch {
+	case row.Status != models.RegistrationRegistered:
+		c.JSON(http.StatusConflict, gin.H{"error": "Participant is on the waitlist"})
+		return
+	case row.CheckedInAt != nil:
+		c.JSON(http.StatusConflict, gin.H{"error": "Participant is already checked in"})
+		return
+	case row.EventStatus == models.EventCancelled:
+		c.JSON(http.StatusConflict, gin.H{"error": "Event was cancelled"})
+		return
+	case row.EventEnded || row.EventStatus == models.EventCompleted:
+		c.JSON(http.StatusConflict, gin.H{"error": "Event is over"})
+		return
+	}
+
+	attendance := models.Attendance{EventID: claims.EventID, UserID: claims.UserID}
+	err = tx.Get(&attendance.CheckedInAt, `UPDATE event_registrations
+			  SET checked_in_at = CURRENT_TIMESTAMP, checked_in_by = $2, checkin_method = 'qr', updated_at = CURRENT_TIMESTAMP
+			  WHERE id = $1 RETURNING checked_in_at`, row.ID, callerID(c))
+	if err != nil {
+		log.Printf("Error checking in: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error checking in"})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing check-in: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error checking in"})
+		return
+	}
+
+	c.JSON(http.StatusOK, attendance)
+}
+
+// @Summary     Check Out
+// @Description This API checks a participant out by their scanned QR code, records the attendance in history and credits the event's XP, pro-rated by the time attended when the event has prorate_xp set. Officers of the event and admins only.
+// @Tags         Attendance
+// @Accept       json
+// @Produce      json
+// @Param        Authorization header string            true "tma and the web app init data"
+// @Param        id         path   string            true "Event ID"
+// @Param        ticket     body   models.TicketScan true "Scanned QR code"
+// @Success      200  {object} models.Attendance
+// @Failure      400  {object} ErrorResponse
+// @Failure      401  {object} ErrorResponse
+// @Failure      403  {object} ErrorResponse
+// @Failure      404  {object} ErrorResponse
+// @Failure      409  {object} ErrorResponse
+// @Failure      500  {object} ErrorResponse
+// @Router       /event/{id}/checkout [post]
+func (h *HandlerV1) CheckOut(c *gin.Context) {
+	claims, ok := h.scanTicket(c)
+	if !ok {
+		return
+	}
+	ctx := c.Request.Context()
+
+	tx, err := h.db.Beginx()
+	if err != nil {
+		log.Printf("Error starting transaction: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error checking out"})
+		return
+	}
+	defer tx.Rollback()
+
+	row, err := lockAttendance(tx, claims.EventID, claims.UserID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Registration not found"})
+		} else {
+			log.Printf("Error fetching registration: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error checking out"})
+		}
+		return
+	}
+	switch {
+	case row.CheckedInAt == nil:
+		c.JSON(http.StatusConflict, gin.H{"error": "Participant has not checked in"})
+		return
+	case row.CheckedOutAt != nil:
+		c.JSON(http.StatusConflict, gin.H{"error": "Participant is already checked out"})
+		return
+	case row.EventStatus == models.EventCancelled:
+		c.JSON(http.StatusConflict, gin.H{"error": "Event was cancelled"})
+		return
+	}
+
+	attendance, err := events.CheckOut(ctx, tx, h.rules, row.ID, callerID(c))
+	if err != nil {
+		log.Printf("Error checking out: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error checking out"})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing check-out: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error checking out"})
+		return
+	}
+	h.syncLeaderboard(ctx, attendance.UserID)
+
+	c.JSON(http.StatusOK, attendance)
+}
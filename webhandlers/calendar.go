@@ -0,0 +1,199 @@
+package webhandlers
+
+import (
+	"bytes"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"worker-bot/ical"
+	"worker-bot/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// feedPastDays is how long ended events stay in the public feed.
+const feedPastDays = 30
+
+// writeCalendar sends events as an iCalendar file, as a download when
+// filename is set.
+func writeCalendar(c *gin.Context, name, filename string, events []ical.Event) {
+	var buf bytes.Buffer
+	if err := (ical.Calendar{Name: name, Events: events}).Write(&buf); err != nil {
+		log.Printf("Error writing calendar: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error writing calendar"})
+		return
+	}
+	if filename != "" {
+		c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	}
+	c.Data(http.StatusOK, ical.ContentType, buf.Bytes())
+}
+
+func newCalendarToken() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// feedURLs returns the https and webcal URLs of a personal feed.
+func feedURLs(c *gin.Context, token string) models.CalendarFeed {
+	scheme := "http"
+	if c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	path := "://" + c.Request.Host + "/calendar/" + token + ".ics"
+	return models.CalendarFeed{URL: scheme + path, WebcalURL: "webcal" + path}
+}
+
+// @Summary     Events Calendar Feed
+// @Description This API returns an iCalendar feed of upcoming events and those that ended in the last 30 days, to subscribe to from a phone calendar. Cancelled events stay in the feed marked cancelled.
+// @Tags         Calendar
+// @Produce      text/calendar
+// @Success      200  {string} string "iCalendar file"
+// @Failure      500  {object} ErrorResponse
+// @Router       /events.ics [get]
+func (h *HandlerV1) EventsFeed(c *gin.Context) {
+	events, err := h.finder.Calendar(c.Request.Context(),
+		"e.status <> 'draft' AND e.end_date >= CURRENT_TIMESTAMP - INTERVAL '"+strconv.Itoa(feedPastDays)+" days'", "")
+	if err != nil {
+		log.Printf("Error fetching calendar events: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching events"})
+		return
+	}
+	writeCalendar(c, "Eco events", "", events)
+}
+
+// @Summary     Event Calendar File
+// @Description This API returns a single event as an iCalendar file to add it to a calendar
+// @Tags         Calendar
+// @Produce      text/calendar
+// @Param        id   path string true "Event ID"
+// @Success      200  {string} string "iCalendar file"
+// @Failure      404  {object} ErrorResponse
+// @Failure      500  {object} ErrorResponse
+// @Router       /event/{id}/ics [get]
+func (h *HandlerV1) EventCalendarFile(c *gin.Context) {
+	events, err := h.finder.Calendar(c.Request.Context(), "e.id = ? AND e.status <> 'draft'", "", c.Param("id"))
+	if err != nil {
+		log.Printf("Error fetching calendar event: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching event"})
+		return
+	}
+	if len(events) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+		return
+	}
+	writeCalendar(c, "", "event.ics", events)
+}
+
+// @Summary     Personal Calendar Feed
+// @Description This API returns an iCalendar feed of the events a user registered for, waitlisted ones as tentative and cancelled ones as cancelled. The token comes from GET /user/{id}/calendar. Events the user leaves drop out of the feed.
+// @Tags         Calendar
+// @Produce      text/calendar
+// @Param        token  path string true "Calendar token, optionally followed by .ics"
+// @Success      200  {string} string "iCalendar file"
+// @Failure      404  {object} ErrorResponse
+// @Failure      500  {object} ErrorResponse
+// @Router       /calendar/{token} [get]
+func (h *HandlerV1) PersonalFeed(c *gin.Context) {
+	token := strings.TrimSuffix(c.Param("token"), ".ics")
+
+	var userID int64
+	err := h.db.Get(&userID, `SELECT id FROM users WHERE calendar_token = $1`, token)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Calendar not found"})
+		} else {
+			log.Printf("Error fetching calendar token: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching calendar"})
+		}
+		return
+	}
+
+	events, err := h.finder.Calendar(c.Request.Context(),
+		"EXISTS (SELECT 1 FROM event_registrations r WHERE r.event_id = e.id AND r.user_id = ?)",
+		`(SELECT CASE WHEN e.status = 'cancelled' THEN '`+ical.StatusCancelled+`'
+				WHEN r.status = 'waitlisted' THEN '`+ical.StatusTentative+`' ELSE '`+ical.StatusConfirmed+`' END
+			FROM event_registrations r WHERE r.event_id = e.id AND r.user_id = ?)`,
+		userID, userID)
+	if err != nil {
+		log.Printf("Error fetching calendar events: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching events"})
+		return
+	}
+	c.Header("Cache-Control", "private, max-age=900")
+	writeCalendar(c, "My eco events", "", events)
+}
+
+// @Summary     Get Calendar Feed
+// @Description This API returns the URLs of a user's personal calendar feed of the events they registered for, creating it on first use
+// @Tags         Calendar
+// @Accept       json
+// @Produce      json
+// @Param        Authorization header string true "tma and the web app init data"
+// @Param        id  path int true "User ID"
+// @Success      200  {object} models.CalendarFeed
+// @Failure      400  {object} ErrorResponse
+// @Failure      401  {object} ErrorResponse
+// @Failure      403  {object} ErrorResponse
+// @Failure      404  {object} ErrorResponse
+// @Failure      500  {object} ErrorResponse
+// @Router       /user/{id}/calendar [get]
+func (h *HandlerV1) GetCalendarFeed(c *gin.Context) {
+	h.calendarToken(c, false)
+}
+
+// @Summary     Reset Calendar Feed
+// @Description This API replaces the URL of a user's personal calendar feed, e.g. after it was shared by mistake. The old URL stops working.
+// @Tags         Calendar
+// @Accept       json
+// @Produce      json
+// @Param        Authorization header string true "tma and the web app init data"
+// @Param        id  path int true "User ID"
+// @Success      200  {object} models.CalendarFeed
+// @Failure      400  {object} ErrorResponse
+// @Failure      401  {object} ErrorResponse
+// @Failure      403  {object} ErrorResponse
+// @Failure      404  {object} ErrorResponse
+// @Failure      500  {object} ErrorResponse
+// @Router       /user/{id}/calendar/reset [post]
+func (h *HandlerV1) ResetCalendarFeed(c *gin.Context) {
+	h.calendarToken(c, true)
+}
+
+func (h *HandlerV1) calendarToken(c *gin.Context, reset bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	token, err := newCalendarToken()
+	if err != nil {
+		log.Printf("Error creating calendar token: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error creating calendar"})
+		return
+	}
+	query := `UPDATE users SET calendar_token = COALESCE(calendar_token, $2) WHERE id = $1 RETURNING calendar_token`
+	if reset {
+		query = `UPDATE users SET calendar_token = $2 WHERE id = $1 RETURNING calendar_token`
+	}
+	err = h.db.Get(&token, query, id, token)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		} else {
+			log.Printf("Error saving calendar token: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error creating calendar"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, feedURLs(c, token))
+}
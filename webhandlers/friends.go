@@ -0,0 +1,130 @@
+package webhandlers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"worker-bot/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+)
+
+// @Summary     List Friends
+// @Description This API returns the friends of a user
+// @Tags         Friend
+// @Accept       json
+// @Produce      json
+// @Param        id  path int true "User ID"
+// @Success      200  {array}  models.Friend
+// @Failure      500  {object} ErrorResponse
+// @Router       /user/{id}/friends [get]
+func (h *HandlerV1) ListFriends(c *gin.Context) {
+	id := c.Param("id")
+
+	query := `SELECT u.id, u.first_name, u.last_name, COALESCE(u.avatar, '') AS avatar, u.xp, u.region
+			  FROM friendships f JOIN users u ON u.id = f.friend_id
+			  WHERE f.user_id = $1 ORDER BY u.first_name, u.last_name`
+
+	friends := []models.Friend{}
+	err := h.db.Select(&friends, query, id)
+	if err != nil {
+		log.Printf("Error fetching friends: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching friends"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"friends": friends})
+}
+
+// @Summary     Add Friend
+// @Description This API makes two users friends of each other
+// @Tags         Friend
+// @Accept       json
+// @Produce      json
+// @Param        Authorization header string true "tma and the web app init data"
+// @Param        id        path int true "User ID"
+// @Param        friendId  path int true "Friend user ID"
+// @Success      201  {object} models.Message
+// @Failure      400  {object} ErrorResponse
+// @Failure      401  {object} ErrorResponse
+// @Failure      403  {object} ErrorResponse
+// @Failure      404  {object} ErrorResponse
+// @Failure      500  {object} ErrorResponse
+// @Router       /user/{id}/friends/{friendId} [post]
+func (h *HandlerV1) AddFriend(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+	friendID, err := strconv.ParseInt(c.Param("friendId"), 10, 64)
+	if err != nil || friendID == id {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid friend ID"})
+		return
+	}
+
+	var found int
+	err = h.db.Get(&found, "SELECT COUNT(*) FROM users WHERE id = ANY($1)", pq.Array([]int64{id, friendID}))
+	if err != nil {
+		log.Printf("Error fetching users: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error adding friend"})
+		return
+	}
+	if found != 2 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	query := `INSERT INTO friendships (user_id, friend_id) VALUES ($1, $2), ($2, $1)
+			  ON CONFLICT DO NOTHING`
+	_, err = h.db.Exec(query, id, friendID)
+	if err != nil {
+		log.Printf("Error adding friend: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error adding friend"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Friend added"})
+}
+
+// @Summary     Remove Friend
+// @Description This API removes the friendship between two users
+// @Tags         Friend
+// @Accept       json
+// @Produce      json
+// @Param        Authorization header string true "tma and the web app init data"
+// @Param        id        path int true "User ID"
+// @Param        friendId  path int true "Friend user ID"
+// @Success      204
+// @Failure      401  {object} ErrorResponse
+// @Failure      403  {object} ErrorResponse
+// @Failure      404  {object} ErrorResponse
+// @Failure      500  {object} ErrorResponse
+// @Router       /user/{id}/friends/{friendId} [delete]
+func (h *HandlerV1) RemoveFriend(c *gin.Context) {
+	id := c.Param("id")
+	friendID := c.Param("friendId")
+
+	query := `DELETE FROM friendships WHERE (user_id = $1 AND friend_id = $2) OR (user_id = $2 AND friend_id = $1)`
+	result, err := h.db.Exec(query, id, friendID)
+	if err != nil {
+		log.Printf("Error removing friend: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error removing friend"})
+		return
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		log.Printf("Error checking rows affected: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error checking rows affected"})
+		return
+	}
+
+	if rowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Friend not found"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
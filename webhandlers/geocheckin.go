@@ -0,0 +1,206 @@
+package webhandlers
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"worker-bot/checkin"
+	"worker-bot/geo"
+	"worker-bot/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary     Self Check In
+// @Description This API checks a user in to the running event they are registered for when the shared location is within the event's check-in radius. Suspicious check-ins, e.g. one device used by several users or an impossible travel speed, succeed but are flagged for review.
+// @Tags         Attendance
+// @Accept       json
+// @Produce      json
+// @Param        Authorization header string true "tma and the web app init data"
+// @Param        id        path int                    true "User ID"
+// @Param        location  body models.LocationCheckIn true "Current location"
+// @Success      200  {object} checkin.Result
+// @Failure      400  {object} ErrorResponse
+// @Failure      401  {object} ErrorResponse
+// @Failure      403  {object} ErrorResponse
+// @Failure      404  {object} ErrorResponse
+// @Failure      409  {object} ErrorResponse
+// @Failure      500  {object} ErrorResponse
+// @Router       /user/{id}/checkin [post]
+func (h *HandlerV1) SelfCheckIn(c *gin.Context) {
+	userID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var body models.LocationCheckIn
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !geo.ValidPoint(*body.Latitude, *body.Longitude) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid coordinates"})
+		return
+	}
+
+	result, err := h.checkins.SelfCheckIn(c.Request.Context(), checkin.Attempt{
+		UserID:   userID,
+		Lat:      *body.Latitude,
+		Lng:      *body.Longitude,
+		Accuracy: body.Accuracy,
+		DeviceID: body.DeviceID,
+	})
+	var tooFar *checkin.TooFarError
+	switch {
+	case err == nil:
+		c.JSON(http.StatusOK, result)
+	case errors.As(err, &tooFar):
+		c.JSON(http.StatusForbidden, gin.H{"error": "You are too far from the event", "distance_m": int(tooFar.Distance), "radius_m": tooFar.Radius})
+	case err == checkin.ErrNoEvent:
+		c.JSON(http.StatusNotFound, gin.H{"error": "No registered event is running"})
+	case err == checkin.ErrAlreadyCheckedIn:
+		c.JSON(http.StatusConflict, gin.H{"error": "Already checked in"})
+	default:
+		log.Printf("Error checking in by location: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error checking in"})
+	}
+}
+
+var flagSorts = map[string]sortKey{
+	"created_at": {Column: "created_at", Field: "created_at", Cast: "timestamptz"},
+}
+
+// @Summary     List Check-in Flags
+// @Description This API lists self check-ins flagged as suspicious. Officers see the flags of their events, admins all flags.
+// @Tags         Attendance
+// @Accept       json
+// @Produce      json
+// @Param        Authorization header string true  "tma and the web app init data"
+// @Param        status     query  string false "Review status, open by default" Enums(open, dismissed, confirmed)
+// @Param        event_id   query  string false "Only flags of this event"
+// @Param        limit      query  int    false "Page size (1-100)"
+// @Param        cursor     query  string false "next_cursor of the previous page"
+// @Success      200  {array}  models.CheckinFlag
+// @Failure      400  {object} ErrorResponse
+// @Failure      401  {object} ErrorResponse
+// @Failure      403  {object} ErrorResponse
+// @Failure      500  {object} ErrorResponse
+// @Router       /checkin-flags [get]
+func (h *HandlerV1) ListCheckinFlags(c *gin.Context) {
+	q, err := parsePageQuery(c, flagSorts, "-created_at", "bigint")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	switch status := c.DefaultQuery("status", models.FlagOpen); status {
+	case models.FlagOpen, models.FlagDismissed, models.FlagConfirmed:
+		q.Filter("status = ?", status)
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status must be one of open, dismissed, confirmed"})
+		return
+	}
+	if eventID := c.Query("event_id"); eventID != "" {
+		q.Filter("event_id = ?", eventID)
+	}
+	if !callerIsAdmin(c) {
+		q.Filter("event_id IN (SELECT event_id FROM event_officers WHERE user_id = ?)", callerID(c))
+	}
+
+	query := `SELECT * FROM (SELECT f.id, f.user_id, u.first_name || ' ' || u.last_name AS user_name,
+				f.event_id, e.name AS event_name, f.reason, f.details, f.status,
+				f.reviewed_by, f.reviewed_at, f.created_at
+			  FROM checkin_flags f JOIN users u ON u.id = f.user_id JOIN events e ON e.id = f.event_id) flags`
+
+	flags := []models.CheckinFlag{}
+	total, nextCursor, err := h.listPage(&flags, query, "SELECT COUNT(*) FROM checkin_flags", q)
+	if err != nil {
+		log.Printf("Error fetching check-in flags: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching check-in flags"})
+		return
+	}
+
+	c.JSON(http.StatusOK, pageResponse("flags", flags, total, nextCursor))
+}
+
+// @Summary     Review Check-in Flag
+// @Description This API dismisses or confirms a flagged check-in. Confirming it revokes the check-in unless the participant has already checked out. Officers of the flag's event and admins only.
+// @Tags         Attendance
+// @Accept       json
+// @Produce      json
+// @Param        Authorization header string            true "tma and the web app init data"
+// @Param        id         path   int               true "Flag ID"
+// @Param        review     body   models.FlagReview true "Review"
+// @Success      200  {object} models.CheckinFlag
+// @Failure      400  {object} ErrorResponse
+// @Failure      401  {object} ErrorResponse
+// @Failure      403  {object} ErrorResponse
+// @Failure      404  {object} ErrorResponse
+// @Failure      500  {object} ErrorResponse
+// @Router       /checkin-flags/{id} [put]
+func (h *HandlerV1) ReviewCheckinFlag(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid flag ID"})
+		return
+	}
+
+	var review models.FlagReview
+	if err := c.ShouldBindJSON(&review); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if review.Status != models.FlagDismissed && review.Status != models.FlagConfirmed {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status must be one of dismissed, confirmed"})
+		return
+	}
+
+	tx, err := h.db.Beginx()
+	if err != nil {
+		log.Printf("Error starting transaction: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error reviewing flag"})
+		return
+	}
+	defer tx.Rollback()
+
+	var flag models.CheckinFlag
+	err = tx.Get(&flag, `UPDATE checkin_flags f SET status = $2, reviewed_by = $3, reviewed_at = now()
+			  FROM users u, events e
+			  WHERE f.id = $1 AND f.status = 'open' AND u.id = f.user_id AND e.id = f.event_id
+				AND ($4 OR EXISTS (SELECT 1 FROM event_officers o WHERE o.event_id = f.event_id AND o.user_id = $3))
+			  RETURNING f.id, f.user_id, u.first_name || ' ' || u.last_name AS user_name,
+				f.event_id, e.name AS event_name, f.reason, f.details, f.status,
+				f.reviewed_by, f.reviewed_at, f.created_at`, id, review.Status, callerID(c), callerIsAdmin(c))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Open flag not found"})
+		} else {
+			log.Printf("Error reviewing flag: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error reviewing flag"})
+		}
+		return
+	}
+
+	if review.Status == models.FlagConfirmed {
+		_, err = tx.Exec(`UPDATE event_registrations
+				  SET checked_in_at = NULL, checkin_method = NULL, updated_at = CURRENT_TIMESTAMP
+				  WHERE event_id = $1 AND user_id = $2 AND checkin_method = 'geo' AND checked_out_at IS NULL`,
+			flag.EventID, flag.UserID)
+		if err != nil {
+			log.Printf("Error revoking check-in: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error reviewing flag"})
+			return
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing flag review: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error reviewing flag"})
+		return
+	}
+
+	c.JSON(http.StatusOK, flag)
+}
@@ -0,0 +1,138 @@
+package webhandlers
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"worker-bot/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// transitionFailed answers a status change that matched no event: 404 if
+// the event does not exist, 409 with its current status otherwise.
+func (h *HandlerV1) transitionFailed(c *gin.Context, id, action string) {
+	var status string
+	err := h.db.Get(&status, "SELECT status FROM events WHERE id = $1", id)
+	switch {
+	case err == sql.ErrNoRows:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+	case err != nil:
+		log.Printf("Error fetching event status: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error updating event"})
+	default:
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("A %s event cannot be %s", status, action)})
+	}
+}
+
+// @Summary     Publish Event
+// @Description This API publishes a draft event so users can see it and register. Admins only.
+// @Tags         Event
+// @Accept       json
+// @Produce      json
+// @Param        Authorization header string true "tma and the web app init data"
+// @Param        id         path   string true "Event ID"
+// @Success      200  {object} models.EventStatus
+// @Failure      401  {object} ErrorResponse
+// @Failure      403  {object} ErrorResponse
+// @Failure      404  {object} ErrorResponse
+// @Failure      409  {object} ErrorResponse
+// @Failure      500  {object} ErrorResponse
+// @Router       /event/{id}/publish [post]
+func (h *HandlerV1) PublishEvent(c *gin.Context) {
+	id := c.Param("id")
+
+	var status models.EventStatus
+	err := h.db.Get(&status, `UPDATE events SET status = 'published', updated_at = CURRENT_TIMESTAMP
+			  WHERE id = $1 AND status = 'draft' RETURNING id, status`, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			h.transitionFailed(c, id, "published")
+		} else {
+			log.Printf("Error publishing event: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error publishing event"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+// @Summary     Cancel Event
+// @Description This API cancels an event that has not completed and notifies everyone registered or waitlisted through the bot. XP already credited at check-out is kept. Admins only.
+// @Tags         Event
+// @Accept       json
+// @Produce      json
+// @Param        Authorization header string                   true  "tma and the web app init data"
+// @Param        id            path   string                   true  "Event ID"
+// @Param        cancellation  body   models.EventCancellation false "Reason sent to registrants"
+// @Success      200  {object} models.EventStatus
+// @Failure      400  {object} ErrorResponse
+// @Failure      401  {object} ErrorResponse
+// @Failure      403  {object} ErrorResponse
+// @Failure      404  {object} ErrorResponse
+// @Failure      409  {object} ErrorResponse
+// @Failure      500  {object} ErrorResponse
+// @Router       /event/{id}/cancel [post]
+func (h *HandlerV1) CancelEvent(c *gin.Context) {
+	id := c.Param("id")
+	var body models.EventCancellation
+	if err := c.ShouldBindJSON(&body); err != nil && err != io.EOF {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tx, err := h.db.Beginx()
+	if err != nil {
+		log.Printf("Error starting transaction: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error cancelling event"})
+		return
+	}
+	defer tx.Rollback()
+
+	// A cancelled occurrence is detached so edits of its series leave it
+	// cancelled.
+	var event struct {
+		models.EventStatus
+		Name string `db:"name"`
+	}
+	err = tx.Get(&event, `UPDATE events SET status = 'cancelled', cancelled_at = CURRENT_TIMESTAMP,
+				cancel_reason = NULLIF($2, ''), detached = series_id IS NOT NULL, updated_at = CURRENT_TIMESTAMP
+			  WHERE id = $1 AND status IN ('draft', 'published', 'ongoing') RETURNING id, status, name`, id, body.Reason)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			h.transitionFailed(c, id, "cancelled")
+		} else {
+			log.Printf("Error cancelling event: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error cancelling event"})
+		}
+		return
+	}
+
+	var registrants []int64
+	if err := tx.Select(&registrants, "SELECT user_id FROM event_registrations WHERE event_id = $1", id); err != nil {
+		log.Printf("Error fetching registrants: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error cancelling event"})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing cancellation: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error cancelling event"})
+		return
+	}
+
+	text := fmt.Sprintf("Afsuski, \"%s\" tadbiri bekor qilindi.", event.Name)
+	if body.Reason != "" {
+		text += "\nSabab: " + body.Reason
+	}
+	for _, userID := range registrants {
+		if err := h.notifier.Notify(userID, text); err != nil {
+			log.Printf("Error notifying user %d: %v", userID, err)
+		}
+	}
+
+	c.JSON(http.StatusOK, event.EventStatus)
+}
@@ -0,0 +1,220 @@
+package webhandlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"worker-bot/events"
+	"worker-bot/geo"
+
+	"github.com/gin-gonic/gin"
+)
+
+const maxMapFeatures = 1000
+
+// parseBBox reads a minLng,minLat,maxLng,maxLat bounding box.
+func parseBBox(value string) (minLng, minLat, maxLng, maxLat float64, err error) {
+	parts := strings.Split(value, ",")
+	if len(parts) != 4 {
+		return 0, 0, 0, 0, errors.New("bbox must be minLng,minLat,maxLng,maxLat")
+	}
+	var v [4]float64
+	for i, part := range parts {
+		if v[i], err = strconv.ParseFloat(strings.TrimSpace(part), 64); err != nil {
+			return 0, 0, 0, 0, errors.New("bbox must be minLng,minLat,maxLng,maxLat")
+		}
+	}
+	if !geo.ValidPoint(v[1], v[0]) || !geo.ValidPoint(v[3], v[2]) || v[0] > v[2] || v[1] > v[3] {
+		return 0, 0, 0, 0, errors.New("bbox is not a valid bounding box")
+	}
+	return v[0], v[1], v[2], v[3], nil
+}
+
+type mapEvent struct {
+	ID         string    `db:"id"`
+	Name       string    `db:"name"`
+	StartDate  time.Time `db:"start_date"`
+	EndDate    time.Time `db:"end_date"`
+	TotalXP    int64     `db:"total_xp"`
+	Latitude   float64   `db:"latitude"`
+	Longitude  float64   `db:"longitude"`
+	Address    string    `db:"address"`
+	Region     *string   `db:"region"`
+	Capacity   *int      `db:"capacity"`
+	Registered int       `db:"registered"`
+	Attended   int       `db:"attended"`
+}
+
+// @Summary     Events Map
+// @Description This API returns events with coordinates as a GeoJSON FeatureCollection of points, for the map view
+// @Tags         Map
+// @Accept       json
+// @Produce      json
+// @Param        bbox    query string false "Bounding box minLng,minLat,maxLng,maxLat"
+// @Param        from    query string false "Events ending on or after this date (YYYY-MM-DD or RFC3339)"
+// @Param        to      query string false "Events starting on or before this date (YYYY-MM-DD or RFC3339)"
+// @Param        status  query string false "Event status, all by default" Enums(upcoming, ongoing, past)
+// @Success      200  {object} geo.FeatureCollection
+// @Failure      400  {object} ErrorResponse
+// @Failure      500  {object} ErrorResponse
+// @Router       /map/events [get]
+func (h *HandlerV1) MapEvents(c *gin.Context) {
+	conds := []string{"status NOT IN ('draft', 'cancelled')", "latitude IS NOT NULL", "longitude IS NOT NULL"}
+	var args []interface{}
+
+	if value := c.Query("bbox"); value != "" {
+		minLng, minLat, maxLng, maxLat, err := parseBBox(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		conds = append(conds, "latitude BETWEEN ? AND ?", "longitude BETWEEN ? AND ?")
+		args = append(args, minLat, maxLat, minLng, maxLng)
+	}
+	if from, ok, err := parseTimeQuery(c, "from"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	} else if ok {
+		conds = append(conds, "end_date >= ?")
+		args = append(args, from)
+	}
+	if to, ok, err := parseTimeQuery(c, "to"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	} else if ok {
+		conds = append(conds, "start_date <= ?")
+		args = append(args, to)
+	}
+	if status := c.Query("status"); status != "" {
+		filter, ok := events.StatusFilter(status)
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "status must be one of upcoming, ongoing, past"})
+			return
+		}
+		conds = append(conds, filter)
+	}
+
+	query := h.db.Rebind(`SELECT id, name, start_date, end_date, total_xp, latitude, longitude,
+				COALESCE(address, '') AS address, region, capacity,
+				(SELECT COUNT(*) FROM event_registrations r WHERE r.event_id = e.id AND r.status = 'registered') AS registered,
+				(SELECT COUNT(DISTINCT user_id) FROM history hs WHERE hs.event_id = e.id) AS attended
+			  FROM events e WHERE ` + strings.Join(conds, " AND ") + `
+			  ORDER BY start_date LIMIT ` + strconv.Itoa(maxMapFeatures))
+
+	var rows []mapEvent
+	if err := h.db.Select(&rows, query, args...); err != nil {
+		log.Printf("Error fetching map events: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching events"})
+		return
+	}
+
+	now := time.Now()
+	features := make([]geo.Feature, len(rows))
+	for i, e := range rows {
+		status := events.StatusOngoing
+		switch {
+		case e.StartDate.After(now):
+			status = events.StatusUpcoming
+		case e.EndDate.Before(now):
+			status = events.StatusPast
+		}
+		features[i] = geo.Feature{
+			Type:     "Feature",
+			ID:       e.ID,
+			Geometry: geo.PointGeometry(e.Latitude, e.Longitude),
+			Properties: map[string]interface{}{
+				"name":       e.Name,
+				"start_date": e.StartDate,
+				"end_date":   e.EndDate,
+				"status":     status,
+				"total_xp":   e.TotalXP,
+				"address":    e.Address,
+				"region":     e.Region,
+				"capacity":   e.Capacity,
+				"registered": e.Registered,
+				"attended":   e.Attended,
+			},
+		}
+	}
+
+	c.JSON(http.StatusOK, geo.NewFeatureCollection(features))
+}
+
+type regionImpact struct {
+	Region       string `db:"region"`
+	EventsHeld   int    `db:"events_held"`
+	Participants int    `db:"participants"`
+	XP           int64  `db:"xp"`
+}
+
+// @Summary     Regional Impact Map
+// @Description This API returns the region boundaries of Uzbekistan as a GeoJSON FeatureCollection of polygons with the events held, distinct participants and XP earned in each, for a choropleth of eco activity. Completed events count towards the region they took place in.
+// @Tags         Map
+// @Accept       json
+// @Produce      json
+// @Param        from  query string false "Events ending on or after this date (YYYY-MM-DD or RFC3339)"
+// @Param        to    query string false "Events starting on or before this date (YYYY-MM-DD or RFC3339)"
+// @Success      200  {object} geo.FeatureCollection
+// @Failure      400  {object} ErrorResponse
+// @Failure      500  {object} ErrorResponse
+// @Router       /map/regions [get]
+func (h *HandlerV1) MapRegions(c *gin.Context) {
+	conds := []string{"e.region IS NOT NULL", "e.status = 'completed'"}
+	var args []interface{}
+
+	if from, ok, err := parseTimeQuery(c, "from"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	} else if ok {
+		conds = append(conds, "e.end_date >= ?")
+		args = append(args, from)
+	}
+	if to, ok, err := parseTimeQuery(c, "to"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	} else if ok {
+		conds = append(conds, "e.start_date <= ?")
+		args = append(args, to)
+	}
+
+	query := h.db.Rebind(`SELECT LOWER(TRIM(e.region)) AS region, COUNT(DISTINCT e.id) AS events_held,
+				COUNT(DISTINCT hs.user_id) AS participants, COALESCE(SUM(hs.xp_earned), 0) AS xp
+			  FROM events e LEFT JOIN history hs ON hs.event_id = e.id
+			  WHERE ` + strings.Join(conds, " AND ") + `
+			  GROUP BY 1`)
+
+	var rows []regionImpact
+	if err := h.db.Select(&rows, query, args...); err != nil {
+		log.Printf("Error fetching regional impact: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching regional impact"})
+		return
+	}
+	impact := make(map[string]regionImpact, len(rows))
+	for _, row := range rows {
+		impact[row.Region] = row
+	}
+
+	regions := geo.Regions()
+	for _, f := range regions.Features {
+		var row regionImpact
+		for _, key := range []string{f.ID, f.Properties["name"].(string), f.Properties["name_uz"].(string)} {
+			if r, ok := impact[geo.RegionKey(key)]; ok {
+				row.EventsHeld += r.EventsHeld
+				row.Participants += r.Participants
+				row.XP += r.XP
+				delete(impact, geo.RegionKey(key))
+			}
+		}
+		f.Properties["events_held"] = row.EventsHeld
+		f.Properties["participants"] = row.Participants
+		f.Properties["xp"] = row.XP
+	}
+	for name := range impact {
+		log.Printf("Events in unknown region %q are left off the map", name)
+	}
+
+	c.JSON(http.StatusOK, regions)
+}
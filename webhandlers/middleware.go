@@ -0,0 +1,141 @@
+package webhandlers
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"worker-bot/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	callerIDKey   = "caller_id"
+	callerRoleKey = "caller_role"
+)
+
+// errNoCaller is returned by authenticate for requests sent without init
+// data.
+var errNoCaller = errors.New("no Authorization header")
+
+// authenticate identifies the caller by the Telegram web app init data sent
+// as "Authorization: tma <init data>". The init data is signed with the bot
+// token, so the caller cannot be forged.
+func (h *HandlerV1) authenticate(c *gin.Context) (int64, error) {
+	header := c.GetHeader("Authorization")
+	if header == "" {
+		return 0, errNoCaller
+	}
+	initData, ok := strings.CutPrefix(header, "tma ")
+	if !ok {
+		return 0, errors.New("Authorization header must be tma <init data>")
+	}
+	return h.auth.Verify(initData, time.Now())
+}
+
+// RequireRole only lets through callers whose users.role is one of roles.
+func (h *HandlerV1) RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		callerID, err := h.authenticate(c)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Missing or invalid Authorization header"})
+			return
+		}
+
+		var role string
+		err = h.db.Get(&role, "SELECT role FROM users WHERE id = $1", callerID)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+			} else {
+				log.Printf("Error fetching caller role: %v", err)
+				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Error fetching user data"})
+			}
+			return
+		}
+
+		for _, allowed := range roles {
+			if role == allowed {
+				c.Set(callerIDKey, callerID)
+				c.Set(callerRoleKey, role)
+				c.Next()
+				return
+			}
+		}
+
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions"})
+	}
+}
+
+// RequireUser only lets through callers acting on their own account, the
+// user ID in the path parameter param.
+func (h *HandlerV1) RequireUser(param string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		callerID, err := h.authenticate(c)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Missing or invalid Authorization header"})
+			return
+		}
+		if strconv.FormatInt(callerID, 10) != c.Param(param) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "You can only act on your own account"})
+			return
+		}
+		c.Set(callerIDKey, callerID)
+		c.Next()
+	}
+}
+
+// RequireEventOfficer only lets through admins and the officers of the event
+// in the id path parameter. It runs after RequireRole.
+func (h *HandlerV1) RequireEventOfficer(c *gin.Context) {
+	if callerIsAdmin(c) {
+		c.Next()
+		return
+	}
+
+	var assigned bool
+	err := h.db.Get(&assigned, "SELECT EXISTS (SELECT 1 FROM event_officers WHERE event_id = $1 AND user_id = $2)",
+		c.Param("id"), callerID(c))
+	if err != nil {
+		log.Printf("Error checking event officers: %v", err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Error fetching event data"})
+		return
+	}
+	if !assigned {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "You are not an officer of this event"})
+		return
+	}
+	c.Next()
+}
+
+// isAdmin reports whether the optional Authorization header identifies an
+// admin, for endpoints that show admins more, e.g. draft events.
+func (h *HandlerV1) isAdmin(c *gin.Context) bool {
+	callerID, err := h.authenticate(c)
+	if err != nil {
+		return false
+	}
+	var role string
+	if err := h.db.Get(&role, "SELECT role FROM users WHERE id = $1", callerID); err != nil {
+		if err != sql.ErrNoRows {
+			log.Printf("Error fetching caller role: %v", err)
+		}
+		return false
+	}
+	return role == models.RoleAdmin
+}
+
+// callerID returns the user ID stored by RequireRole or RequireUser.
+func callerID(c *gin.Context) int64 {
+	return c.GetInt64(callerIDKey)
+}
+
+// callerIsAdmin reports whether the caller let through by RequireRole is an
+// admin.
+func callerIsAdmin(c *gin.Context) bool {
+	return c.GetString(callerRoleKey) == models.RoleAdmin
+}
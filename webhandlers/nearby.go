@@ -0,0 +1,74 @@
+package webhandlers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"worker-bot/events"
+	"worker-bot/geo"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultNearbyRadius = 10000
+	maxNearbyRadius     = 200000
+)
+
+// @Summary     Nearby Events
+// @Description This API returns events within a radius of a point, nearest first. By default only events that have not ended are returned.
+// @Tags         Event
+// @Accept       json
+// @Produce      json
+// @Param        lat     query number true  "Latitude"
+// @Param        lng     query number true  "Longitude"
+// @Param        radius  query number false "Radius in meters (up to 200000, default 10000)"
+// @Param        status  query string false "Event status" Enums(upcoming, ongoing, past)
+// @Param        limit   query int    false "Maximum number of events (1-100)"
+// @Success      200  {array}  models.NearbyEvent
+// @Failure      400  {object} ErrorResponse
+// @Failure      500  {object} ErrorResponse
+// @Router       /events/nearby [get]
+func (h *HandlerV1) NearbyEvents(c *gin.Context) {
+	lat, errLat := strconv.ParseFloat(c.Query("lat"), 64)
+	lng, errLng := strconv.ParseFloat(c.Query("lng"), 64)
+	if errLat != nil || errLng != nil || !geo.ValidPoint(lat, lng) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "lat and lng must be valid coordinates"})
+		return
+	}
+
+	radius := float64(defaultNearbyRadius)
+	if value := c.Query("radius"); value != "" {
+		r, err := strconv.ParseFloat(value, 64)
+		if err != nil || r <= 0 || r > maxNearbyRadius {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "radius must be between 0 and 200000 meters"})
+			return
+		}
+		radius = r
+	}
+
+	limit := defaultPageLimit
+	if value := c.Query("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 || n > maxPageLimit {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 100"})
+			return
+		}
+		limit = n
+	}
+
+	status := c.Query("status")
+	if _, ok := events.StatusFilter(status); !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status must be one of upcoming, ongoing, past"})
+		return
+	}
+
+	nearby, err := h.finder.Nearby(c.Request.Context(), lat, lng, radius, status, limit)
+	if err != nil {
+		log.Printf("Error fetching nearby events: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching events"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"events": nearby})
+}
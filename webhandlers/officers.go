@@ -0,0 +1,141 @@
+package webhandlers
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"worker-bot/events"
+	"worker-bot/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// badOfficers answers an assignment of users who are not officers with a
+// 400. It reports whether err was such an error.
+func badOfficers(c *gin.Context, err error) bool {
+	var notOfficer *events.NotOfficerError
+	if !errors.As(err, &notOfficer) {
+		return false
+	}
+	c.JSON(http.StatusBadRequest, gin.H{"error": notOfficer.Error()})
+	return true
+}
+
+// @Summary     List My Officer Events
+// @Description This API returns a page of the events the caller runs as lead officer or assistant, drafts included. Officers and admins only.
+// @Tags         Event
+// @Accept       json
+// @Produce      json
+// @Param        Authorization header string true  "tma and the web app init data"
+// @Param        role      query  string false "Only events where the caller has this role" Enums(lead, assistant)
+// @Param        limit     query  int    false "Page size (1-100)"
+// @Param        cursor    query  string false "next_cursor of the previous page"
+// @Param        sort      query  string false "Sort key, prefix with - for descending" Enums(start_date, -start_date, end_date, -end_date, name, -name, total_xp, -total_xp)
+// @Param        from      query  string false "Events ending on or after this date (YYYY-MM-DD or RFC3339)"
+// @Param        to        query  string false "Events starting on or before this date (YYYY-MM-DD or RFC3339)"
+// @Param        status    query  string false "Event status" Enums(upcoming, ongoing, past)
+// @Param        state     query  string false "Lifecycle status" Enums(draft, published, ongoing, completed, cancelled)
+// @Success      200  {array}  models.Event
+// @Failure      400  {object} ErrorResponse
+// @Failure      401  {object} ErrorResponse
+// @Failure      403  {object} ErrorResponse
+// @Failure      500  {object} ErrorResponse
+// @Router       /officer/events [get]
+func (h *HandlerV1) ListOfficerEvents(c *gin.Context) {
+	q, err := parsePageQuery(c, eventSorts, "start_date", "uuid")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := filterEvents(c, q); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	switch role := c.Query("role"); role {
+	case "":
+		q.Filter("id IN (SELECT event_id FROM event_officers WHERE user_id = ?)", callerID(c))
+	case models.OfficerLead, models.OfficerAssistant:
+		q.Filter("id IN (SELECT event_id FROM event_officers WHERE user_id = ? AND role = ?)", callerID(c), role)
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "role must be one of lead, assistant"})
+		return
+	}
+
+	h.listEvents(c, q)
+}
+
+// @Summary     List Unassigned Officers
+// @Description This API lists the officer names events and series had before officers were user accounts, for which no officer is assigned yet. Assigning the officers of the event or series resolves them. Admins only.
+// @Tags         Event
+// @Produce      json
+// @Param        Authorization header string true "tma and the web app init data"
+// @Success      200  {array}  models.UnassignedOfficer
+// @Failure      401  {object} ErrorResponse
+// @Failure      403  {object} ErrorResponse
+// @Failure      500  {object} ErrorResponse
+// @Router       /officers/unassigned [get]
+func (h *HandlerV1) ListUnassignedOfficers(c *gin.Context) {
+	officers, err := events.UnassignedOfficers(c.Request.Context(), h.db)
+	if err != nil {
+		log.Printf("Error listing unassigned officers: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error listing unassigned officers"})
+		return
+	}
+	c.JSON(http.StatusOK, officers)
+}
+
+// @Summary     Set User Role
+// @Description This API gives a user the user, teacher, officer or admin role. Admins cannot change their own role. Admins only.
+// @Tags         User
+// @Accept       json
+// @Produce      json
+// @Param        Authorization header string            true "tma and the web app init data"
+// @Param        id         path   int               true "User ID"
+// @Param        role       body   models.RoleUpdate true "New role"
+// @Success      200  {object} models.User
+// @Failure      400  {object} ErrorResponse
+// @Failure      401  {object} ErrorResponse
+// @Failure      403  {object} ErrorResponse
+// @Failure      404  {object} ErrorResponse
+// @Failure      500  {object} ErrorResponse
+// @Router       /user/{id}/role [put]
+func (h *HandlerV1) SetUserRole(c *gin.Context) {
+	userID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+	var body models.RoleUpdate
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	switch body.Role {
+	case models.RoleUser, models.RoleTeacher, models.RoleOfficer, models.RoleAdmin:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "role must be one of user, teacher, officer, admin"})
+		return
+	}
+	if userID == callerID(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Admins cannot change their own role"})
+		return
+	}
+
+	var user models.User
+	err = h.db.Get(&user, `UPDATE users SET role = $2, updated_at = CURRENT_TIMESTAMP WHERE id = $1
+			  RETURNING id, first_name, last_name, avatar, birth_date, location, phone_number, xp, role, region`,
+		userID, body.Role)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		} else {
+			log.Printf("Error setting user role: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error updating user"})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, user)
+}
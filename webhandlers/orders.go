@@ -0,0 +1,371 @@
+package webhandlers
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"fmt"
+	"log"
+	"math/big"
+	"net/http"
+	"strconv"
+	"worker-bot/models"
+	"worker-bot/xp"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+)
+
+const orderColumns = `o.id, o.user_id, o.item_id, m.name AS item_name, o.order_number, o.status,
+				o.xp_spent, o.pickup_code, o.handled_by, o.created_at, o.updated_at`
+
+// newPickupCode returns a random 6 digit code the user shows at hand-over.
+func newPickupCode() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%06d", n.Int64()), nil
+}
+
+// transitionOrder moves a locked order to status to. Cancelling or refunding
+// gives the spent XP back to the user and puts the item back in stock.
+// Callers check models.CanTransitionOrder first.
+func transitionOrder(ctx context.Context, tx *sqlx.Tx, order *models.Order, to string, handledBy int64) error {
+	if to == models.OrderCancelled || to == models.OrderRefunded {
+		err := xp.Apply(ctx, tx, int64(order.UserID), order.XPSpent, xp.SourceRefund, strconv.Itoa(order.ID))
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec("UPDATE market SET count = count + 1, updated_at = CURRENT_TIMESTAMP WHERE id = $1", order.ItemID)
+		if err != nil {
+			return err
+		}
+	}
+
+	var handler *int64
+	if handledBy != 0 {
+		handler = &handledBy
+	}
+
+	query := `UPDATE orders SET status = $1, handled_by = COALESCE($2, handled_by), updated_at = CURRENT_TIMESTAMP
+			  WHERE id = $3 RETURNING handled_by, updated_at`
+	err := tx.QueryRow(query, to, handler, order.ID).Scan(&order.HandledBy, &order.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	order.Status = to
+	return nil
+}
+
+// lockOrder loads an order inside tx and locks its row until the transaction ends.
+func lockOrder(tx *sqlx.Tx, orderID string) (models.Order, error) {
+	var order models.Order
+	query := `SELECT ` + orderColumns + ` FROM orders o JOIN market m ON m.id = o.item_id
+			  WHERE o.id = $1 FOR UPDATE OF o`
+	err := tx.Get(&order, query, orderID)
+	return order, err
+}
+
+// @Summary     List My Orders
+// @Description This API returns the orders placed by a user, newest first
+// @Tags         Order
+// @Accept       json
+// @Produce      json
+// @Param        Authorization header string true "tma and the web app init data"
+// @Param        id      path  int     true   "User ID"
+// @Param        status  query string  false  "Order status" Enums(pending, ready_for_pickup, fulfilled, cancelled, refunded)
+// @Success      200  {array}  models.Order
+// @Failure      401  {object} ErrorResponse
+// @Failure      403  {object} ErrorResponse
+// @Failure      500  {object} ErrorResponse
+// @Router       /user/{id}/orders [get]
+func (h *HandlerV1) ListUserOrders(c *gin.Context) {
+	id := c.Param("id")
+	status := c.Query("status")
+
+	query := `SELECT ` + orderColumns + ` FROM orders o JOIN market m ON m.id = o.item_id
+			  WHERE o.user_id = $1 AND ($2 = '' OR o.status = $2)
+			  ORDER BY o.created_at DESC`
+
+	orders := []models.Order{}
+	err := h.db.Select(&orders, query, id, status)
+	if err != nil {
+		log.Printf("Error fetching orders: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching orders"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"orders": orders})
+}
+
+// @Summary     Get My Order
+// @Description This API returns a single order of a user including its pickup code
+// @Tags         Order
+// @Accept       json
+// @Produce      json
+// @Param        Authorization header string true "tma and the web app init data"
+// @Param        id       path int  true  "User ID"
+// @Param        orderId  path int  true  "Order ID"
+// @Success      200  {object} models.Order
+// @Failure      401  {object} ErrorResponse
+// @Failure      403  {object} ErrorResponse
+// @Failure      404  {object} ErrorResponse
+// @Failure      500  {object} ErrorResponse
+// @Router       /user/{id}/orders/{orderId} [get]
+func (h *HandlerV1) GetUserOrder(c *gin.Context) {
+	id := c.Param("id")
+	orderId := c.Param("orderId")
+
+	query := `SELECT ` + orderColumns + ` FROM orders o JOIN market m ON m.id = o.item_id
+			  WHERE o.id = $1 AND o.user_id = $2`
+
+	var order models.Order
+	err := h.db.Get(&order, query, orderId, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Order not found"})
+		} else {
+			log.Printf("Error fetching order: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching order"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, order)
+}
+
+// @Summary     Cancel My Order
+// @Description This API lets a user cancel an order that has not been handed over yet. The XP is returned and the item restocked.
+// @Tags         Order
+// @Accept       json
+// @Produce      json
+// @Param        Authorization header string true "tma and the web app init data"
+// @Param        id       path int  true  "User ID"
+// @Param        orderId  path int  true  "Order ID"
+// @Success      200  {object} models.Order
+// @Failure      400  {object} ErrorResponse
+// @Failure      401  {object} ErrorResponse
+// @Failure      403  {object} ErrorResponse
+// @Failure      404  {object} ErrorResponse
+// @Failure      500  {object} ErrorResponse
+// @Router       /user/{id}/orders/{orderId}/cancel [post]
+func (h *HandlerV1) CancelUserOrder(c *gin.Context) {
+	id := c.Param("id")
+	orderId := c.Param("orderId")
+
+	tx, err := h.db.Beginx()
+	if err != nil {
+		log.Printf("Error starting transaction: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error cancelling order"})
+		return
+	}
+	defer tx.Rollback()
+
+	order, err := lockOrder(tx, orderId)
+	if err == nil && strconv.Itoa(order.UserID) != id {
+		err = sql.ErrNoRows
+	}
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Order not found"})
+		} else {
+			log.Printf("Error fetching order: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching order"})
+		}
+		return
+	}
+
+	if !models.CanTransitionOrder(order.Status, models.OrderCancelled) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Cannot move order from %s to %s", order.Status, models.OrderCancelled)})
+		return
+	}
+
+	if err := transitionOrder(c.Request.Context(), tx, &order, models.OrderCancelled, 0); err != nil {
+		log.Printf("Error updating order status: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error cancelling order"})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing order cancellation: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error cancelling order"})
+		return
+	}
+	h.syncLeaderboard(c.Request.Context(), int64(order.UserID))
+
+	c.JSON(http.StatusOK, order)
+}
+
+// @Summary     List Orders
+// @Description This API returns all orders for admins and officers, optionally filtered by status. Pickup codes are hidden.
+// @Tags         Order
+// @Accept       json
+// @Produce      json
+// @Param        Authorization header string  true   "tma and the web app init data"
+// @Param        status     query  string  false  "Order status" Enums(pending, ready_for_pickup, fulfilled, cancelled, refunded)
+// @Success      200  {array}  models.Order
+// @Failure      401  {object} ErrorResponse
+// @Failure      403  {object} ErrorResponse
+// @Failure      500  {object} ErrorResponse
+// @Router       /orders [get]
+func (h *HandlerV1) ListOrders(c *gin.Context) {
+	status := c.Query("status")
+
+	query := `SELECT ` + orderColumns + ` FROM orders o JOIN market m ON m.id = o.item_id
+			  WHERE ($1 = '' OR o.status = $1)
+			  ORDER BY o.created_at DESC`
+
+	orders := []models.Order{}
+	err := h.db.Select(&orders, query, status)
+	if err != nil {
+		log.Printf("Error fetching orders: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching orders"})
+		return
+	}
+
+	for i := range orders {
+		orders[i].PickupCode = ""
+	}
+
+	c.JSON(http.StatusOK, gin.H{"orders": orders})
+}
+
+// @Summary     Update Order Status
+// @Description This API moves an order to ready_for_pickup, cancelled or refunded. Cancelling or refunding returns the XP and restocks the item.
+// @Tags         Order
+// @Accept       json
+// @Produce      json
+// @Param        Authorization header string                    true  "tma and the web app init data"
+// @Param        id         path   int                       true  "Order ID"
+// @Param        body       body   models.OrderStatusUpdate  true  "New status"
+// @Success      200  {object} models.Order
+// @Failure      400  {object} ErrorResponse
+// @Failure      401  {object} ErrorResponse
+// @Failure      403  {object} ErrorResponse
+// @Failure      404  {object} ErrorResponse
+// @Failure      500  {object} ErrorResponse
+// @Router       /orders/{id}/status [put]
+func (h *HandlerV1) UpdateOrderStatus(c *gin.Context) {
+	id := c.Param("id")
+	var body models.OrderStatusUpdate
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	if body.Status == models.OrderFulfilled {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Orders are fulfilled by verifying the pickup code"})
+		return
+	}
+
+	tx, err := h.db.Beginx()
+	if err != nil {
+		log.Printf("Error starting transaction: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error updating order"})
+		return
+	}
+	defer tx.Rollback()
+
+	order, err := lockOrder(tx, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Order not found"})
+		} else {
+			log.Printf("Error fetching order: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching order"})
+		}
+		return
+	}
+
+	if !models.CanTransitionOrder(order.Status, body.Status) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Cannot move order from %s to %s", order.Status, body.Status)})
+		return
+	}
+
+	if err := transitionOrder(c.Request.Context(), tx, &order, body.Status, callerID(c)); err != nil {
+		log.Printf("Error updating order status: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error updating order"})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing order update: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error updating order"})
+		return
+	}
+	if order.Status == models.OrderCancelled || order.Status == models.OrderRefunded {
+		h.syncLeaderboard(c.Request.Context(), int64(order.UserID))
+	}
+
+	order.PickupCode = ""
+	c.JSON(http.StatusOK, order)
+}
+
+// @Summary     Fulfil Order
+// @Description This API hands an order over to the user once the officer has verified the pickup code shown by the user
+// @Tags         Order
+// @Accept       json
+// @Produce      json
+// @Param        Authorization header string                     true  "tma and the web app init data"
+// @Param        id         path   int                        true  "Order ID"
+// @Param        body       body   models.PickupVerification  true  "Pickup code"
+// @Success      200  {object} models.Order
+// @Failure      400  {object} ErrorResponse
+// @Failure      401  {object} ErrorResponse
+// @Failure      403  {object} ErrorResponse
+// @Failure      404  {object} ErrorResponse
+// @Failure      500  {object} ErrorResponse
+// @Router       /orders/{id}/fulfil [post]
+func (h *HandlerV1) FulfilOrder(c *gin.Context) {
+	id := c.Param("id")
+	var body models.PickupVerification
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	tx, err := h.db.Beginx()
+	if err != nil {
+		log.Printf("Error starting transaction: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fulfilling order"})
+		return
+	}
+	defer tx.Rollback()
+
+	order, err := lockOrder(tx, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Order not found"})
+		} else {
+			log.Printf("Error fetching order: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching order"})
+		}
+		return
+	}
+
+	if order.PickupCode != body.PickupCode {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid pickup code"})
+		return
+	}
+
+	if !models.CanTransitionOrder(order.Status, models.OrderFulfilled) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Cannot move order from %s to %s", order.Status, models.OrderFulfilled)})
+		return
+	}
+
+	if err := transitionOrder(c.Request.Context(), tx, &order, models.OrderFulfilled, callerID(c)); err != nil {
+		log.Printf("Error updating order status: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fulfilling order"})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing order fulfilment: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fulfilling order"})
+		return
+	}
+
+	order.PickupCode = ""
+	c.JSON(http.StatusOK, order)
+}
@@ -0,0 +1,220 @@
+package webhandlers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultPageLimit = 20
+	maxPageLimit     = 100
+)
+
+// sortKey is a whitelisted sort option of a list endpoint.
+type sortKey struct {
+	Column string // column used in ORDER BY and in the keyset condition
+	Field  string // db tag of the struct field holding the column value
+	Cast   string // SQL type the cursor value is cast back to
+}
+
+type cursor struct {
+	Value string `json:"v"`
+	ID    string `json:"id"`
+}
+
+func encodeCursor(c cursor) string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeCursor(s string) (*cursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	var c cursor
+	if err := json.Unmarshal(b, &c); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// pageQuery collects the filters, sort order and cursor of a list request.
+// Conditions use ? placeholders and are rebound for Postgres when run.
+type pageQuery struct {
+	Limit  int
+	Sort   sortKey
+	Desc   bool
+	IDCast string
+	Cursor *cursor
+
+	filters []string
+	args    []interface{}
+}
+
+// parsePageQuery reads limit, cursor and sort from the query string.
+// sort is one of the keys of sorts, prefixed with "-" for descending order.
+func parsePageQuery(c *gin.Context, sorts map[string]sortKey, defaultSort, idCast string) (*pageQuery, error) {
+	q := &pageQuery{Limit: defaultPageLimit, IDCast: idCast}
+
+	if limit := c.Query("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 1 || n > maxPageLimit {
+			return nil, fmt.Errorf("limit must be between 1 and %d", maxPageLimit)
+		}
+		q.Limit = n
+	}
+
+	name := c.DefaultQuery("sort", defaultSort)
+	if strings.HasPrefix(name, "-") {
+		q.Desc = true
+		name = name[1:]
+	}
+	key, ok := sorts[name]
+	if !ok {
+		allowed := make([]string, 0, len(sorts))
+		for name := range sorts {
+			allowed = append(allowed, name)
+		}
+		sort.Strings(allowed)
+		return nil, fmt.Errorf("sort must be one of %s", strings.Join(allowed, ", "))
+	}
+	q.Sort = key
+
+	if raw := c.Query("cursor"); raw != "" {
+		cur, err := decodeCursor(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cursor")
+		}
+		q.Cursor = cur
+	}
+
+	return q, nil
+}
+
+// Filter adds a condition that applies to both the page and the total count.
+func (q *pageQuery) Filter(cond string, args ...interface{}) {
+	q.filters = append(q.filters, cond)
+	q.args = append(q.args, args...)
+}
+
+// Where renders the filters as a WHERE clause.
+func (q *pageQuery) Where() (string, []interface{}) {
+	if len(q.filters) == 0 {
+		return "", nil
+	}
+	return " WHERE " + strings.Join(q.filters, " AND "), q.args
+}
+
+// Page renders the filters, the keyset condition for the cursor, ORDER BY
+// and LIMIT. One extra row is fetched to know whether a next page exists.
+func (q *pageQuery) Page() (string, []interface{}) {
+	filters := append([]string{}, q.filters...)
+	args := append([]interface{}{}, q.args...)
+
+	if q.Cursor != nil {
+		op := ">"
+		if q.Desc {
+			op = "<"
+		}
+		filters = append(filters, fmt.Sprintf("(%[1]s %[2]s CAST(? AS %[3]s) OR (%[1]s = CAST(? AS %[3]s) AND id > CAST(? AS %[4]s)))",
+			q.Sort.Column, op, q.Sort.Cast, q.IDCast))
+		args = append(args, q.Cursor.Value, q.Cursor.Value, q.Cursor.ID)
+	}
+
+	var clause string
+	if len(filters) > 0 {
+		clause = " WHERE " + strings.Join(filters, " AND ")
+	}
+
+	dir := "ASC"
+	if q.Desc {
+		dir = "DESC"
+	}
+	clause += fmt.Sprintf(" ORDER BY %s %s, id ASC LIMIT %d", q.Sort.Column, dir, q.Limit+1)
+	return clause, args
+}
+
+// trimPage cuts the extra row fetched by Page off dest, a pointer to a slice
+// of structs, and returns the cursor of the next page or "" on the last page.
+func (q *pageQuery) trimPage(dest interface{}) string {
+	items := reflect.ValueOf(dest).Elem()
+	if items.Len() <= q.Limit {
+		return ""
+	}
+	items.Set(items.Slice(0, q.Limit))
+
+	last := items.Index(q.Limit - 1)
+	return encodeCursor(cursor{
+		Value: fieldByTag(last, q.Sort.Field),
+		ID:    fieldByTag(last, "id"),
+	})
+}
+
+// fieldByTag formats the field of struct v whose db tag is tag, looking into
+// embedded structs as sqlx does.
+func fieldByTag(v reflect.Value, tag string) string {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Anonymous && f.Type.Kind() == reflect.Struct {
+			if s := fieldByTag(v.Field(i), tag); s != "" {
+				return s
+			}
+			continue
+		}
+		if f.Tag.Get("db") != tag {
+			continue
+		}
+		switch val := v.Field(i).Interface().(type) {
+		case time.Time:
+			return val.Format(time.RFC3339Nano)
+		default:
+			return fmt.Sprint(val)
+		}
+	}
+	return ""
+}
+
+// parseTimeQuery reads a YYYY-MM-DD or RFC3339 query parameter.
+func parseTimeQuery(c *gin.Context, name string) (time.Time, bool, error) {
+	value := c.Query(name)
+	if value == "" {
+		return time.Time{}, false, nil
+	}
+	for _, layout := range []string{"2006-01-02", time.RFC3339} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, true, nil
+		}
+	}
+	return time.Time{}, false, fmt.Errorf("%s must be a date (YYYY-MM-DD) or RFC3339 timestamp", name)
+}
+
+// listPage runs selectQuery and countQuery, both without a WHERE clause, with
+// the filters of q and stores the current page in dest.
+func (h *HandlerV1) listPage(dest interface{}, selectQuery, countQuery string, q *pageQuery) (total int, nextCursor string, err error) {
+	where, args := q.Where()
+	if err = h.db.Get(&total, h.db.Rebind(countQuery+where), args...); err != nil {
+		return 0, "", err
+	}
+
+	page, args := q.Page()
+	if err = h.db.Select(dest, h.db.Rebind(selectQuery+page), args...); err != nil {
+		return 0, "", err
+	}
+
+	return total, q.trimPage(dest), nil
+}
+
+// pageResponse is the envelope shared by all paginated list endpoints.
+func pageResponse(key string, items interface{}, total int, nextCursor string) gin.H {
+	return gin.H{key: items, "total": total, "next_cursor": nextCursor}
+}
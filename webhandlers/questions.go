@@ -0,0 +1,382 @@
+package webhandlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strings"
+	"worker-bot/models"
+	"worker-bot/quiz"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// maxImportSize bounds the size of an imported file.
+const maxImportSize = 5 << 20
+
+var questionSorts = map[string]sortKey{
+	"created_at": {Column: "created_at", Field: "created_at", Cast: "timestamp"},
+	"updated_at": {Column: "updated_at", Field: "updated_at", Cast: "timestamp"},
+}
+
+var timingSorts = map[string]sortKey{
+	"served":       {Column: "served", Field: "served", Cast: "bigint"},
+	"correct_rate": {Column: "correct_rate", Field: "correct_rate", Cast: "double precision"},
+	"late_rate":    {Column: "late_rate", Field: "late_rate", Cast: "double precision"},
+	"time_used":    {Column: "time_used", Field: "time_used", Cast: "double precision"},
+}
+
+// questionID reads the question ID path parameter, answering a malformed ID
+// with a 400.
+func questionID(c *gin.Context) (string, bool) {
+	id := c.Param("id")
+	if _, err := uuid.Parse(id); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid question ID"})
+		return "", false
+	}
+	return id, true
+}
+
+// questionFailed answers a failed question bank operation.
+func questionFailed(c *gin.Context, err error, action string) {
+	switch {
+	case errors.Is(err, quiz.ErrQuestionNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Question not found"})
+	case errors.Is(err, quiz.ErrInvalidQuiz):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, quiz.ErrDuplicateQuestion), errors.Is(err, quiz.ErrStatusUnchanged):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		log.Printf("Error %s question: %v", action, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error " + action + " question"})
+	}
+}
+
+// @Summary     List Bank Questions
+// @Description This API lists the questions of the quiz question bank, those waiting for review by default. Teachers and admins only.
+// @Tags         Question
+// @Accept       json
+// @Produce      json
+// @Param        Authorization header string true  "tma and the web app init data"
+// @Param        status      query  string false "Review status, pending by default" Enums(pending, approved, rejected)
+// @Param        difficulty  query  string false "Difficulty" Enums(EASY, MEDIUM, HARD)
+// @Param        topic       query  string false "Topic"
+// @Param        language    query  string false "Language"
+// @Param        source      query  string false "Provider that generated the question, import or seed"
+// @Param        event_id    query  string false "Only the questions of this event's quiz"
+// @Param        limit       query  int    false "Page size (1-100)"
+// @Param        cursor      query  string false "next_cursor of the previous page"
+// @Param        sort        query  string false "Sort key, prefix with - for descending" Enums(created_at, -created_at, updated_at, -updated_at)
+// @Success      200  {array}  quiz.BankQuestion
+// @Failure      400  {object} ErrorResponse
+// @Failure      401  {object} ErrorResponse
+// @Failure      403  {object} ErrorResponse
+// @Failure      500  {object} ErrorResponse
+// @Router       /questions [get]
+func (h *HandlerV1) ListQuestions(c *gin.Context) {
+	q, err := parsePageQuery(c, questionSorts, "created_at", "uuid")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	switch status := c.DefaultQuery("status", quiz.StatusPending); status {
+	case quiz.StatusPending, quiz.StatusApproved, quiz.StatusRejected:
+		q.Filter("status = ?", status)
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status must be one of pending, approved, rejected"})
+		return
+	}
+	for _, name := range []string{"topic", "language", "source", "event_id"} {
+		if value := c.Query(name); value != "" {
+			q.Filter(name+" = ?", value)
+		}
+	}
+	if difficulty := c.Query("difficulty"); difficulty != "" {
+		q.Filter("difficulty = ?", strings.ToUpper(difficulty))
+	}
+
+	questions := []quiz.BankQuestion{}
+	total, nextCursor, err := h.listPage(&questions, `SELECT `+quiz.BankColumns+` FROM questions`,
+		"SELECT COUNT(*) FROM questions", q)
+	if err != nil {
+		log.Printf("Error fetching questions: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching questions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, pageResponse("questions", questions, total, nextCursor))
+}
+
+// @Summary     Import Questions
+// @Description This API imports questions into the quiz question bank from a CSV or JSON file, sent as the request body or as the multipart field "file". CSV files have a header row with the columns difficulty, topic, language, question, a, b, c, d and answer; topic and language are optional. JSON files are an array of questions as exported. Imported questions wait for review. Invalid and duplicate rows are skipped and reported by row number, counting data rows from 1. Teachers and admins only.
+// @Tags         Question
+// @Accept       text/csv,json,mpfd
+// @Produce      json
+// @Param        Authorization header   string true  "tma and the web app init data"
+// @Param        format     query    string false "File format, taken from the content type or file name by default" Enums(csv, json)
+// @Param        file       formData file   false "CSV or JSON file"
+// @Success      200  {object} quiz.ImportReport
+// @Failure      400  {object} ErrorResponse
+// @Failure      401  {object} ErrorResponse
+// @Failure      403  {object} ErrorResponse
+// @Failure      500  {object} ErrorResponse
+// @Router       /questions/import [post]
+func (h *HandlerV1) ImportQuestions(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportSize)
+
+	var body io.Reader = c.Request.Body
+	format := c.Query("format")
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		header, err := c.FormFile("file")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Missing file"})
+			return
+		}
+		file, err := header.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid file"})
+			return
+		}
+		defer file.Close()
+		body = file
+		if format == "" {
+			format = strings.TrimPrefix(strings.ToLower(filepath.Ext(header.Filename)), ".")
+		}
+	}
+	if format == "" {
+		switch c.ContentType() {
+		case "text/csv":
+			format = "csv"
+		case "application/json":
+			format = "json"
+		}
+	}
+
+	var rows []quiz.ImportRow
+	var rowErrors []quiz.RowError
+	var err error
+	switch format {
+	case "csv":
+		rows, rowErrors, err = quiz.ReadCSV(body)
+	case "json":
+		rows, rowErrors, err = quiz.ReadJSON(body)
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be one of csv, json"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid %s file: %v", format, err)})
+		return
+	}
+
+	report, err := h.bank.Import(c.Request.Context(), callerID(c), rows)
+	if err != nil {
+		log.Printf("Error importing questions: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error importing questions"})
+		return
+	}
+	report.Errors = append(report.Errors, rowErrors...)
+	sort.Slice(report.Errors, func(i, j int) bool { return report.Errors[i].Row < report.Errors[j].Row })
+
+	c.JSON(http.StatusOK, report)
+}
+
+// @Summary     Export Questions
+// @Description This API exports the questions of the quiz question bank as a CSV or JSON file that can be imported again. Teachers and admins only.
+// @Tags         Question
+// @Accept       json
+// @Produce      text/csv,json
+// @Param        Authorization header string true  "tma and the web app init data"
+// @Param        format      query  string false "File format, csv by default" Enums(csv, json)
+// @Param        status      query  string false "Review status, all by default" Enums(pending, approved, rejected)
+// @Param        difficulty  query  string false "Difficulty" Enums(EASY, MEDIUM, HARD)
+// @Param        topic       query  string false "Topic"
+// @Param        language    query  string false "Language"
+// @Success      200  {array}  quiz.BankQuestion
+// @Failure      400  {object} ErrorResponse
+// @Failure      401  {object} ErrorResponse
+// @Failure      403  {object} ErrorResponse
+// @Failure      500  {object} ErrorResponse
+// @Router       /questions/export [get]
+func (h *HandlerV1) ExportQuestions(c *gin.Context) {
+	format := c.DefaultQuery("format", "csv")
+	if format != "csv" && format != "json" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be one of csv, json"})
+		return
+	}
+
+	questions, err := h.bank.Export(c.Request.Context(), quiz.ExportFilter{
+		Status:     c.Query("status"),
+		Difficulty: strings.ToUpper(c.Query("difficulty")),
+		Topic:      c.Query("topic"),
+		Language:   c.Query("language"),
+	})
+	if err != nil {
+		log.Printf("Error exporting questions: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error exporting questions"})
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="questions.`+format+`"`)
+	if format == "json" {
+		c.JSON(http.StatusOK, questions)
+		return
+	}
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Status(http.StatusOK)
+	if err := quiz.WriteCSV(c.Writer, questions); err != nil {
+		log.Printf("Error writing questions CSV: %v", err)
+	}
+}
+
+// @Summary     Edit Question
+// @Description This API replaces the difficulty, topic, language, text, variants and answer of a bank question, keeping its review status. The edit is recorded in the question's history. Teachers and admins only.
+// @Tags         Question
+// @Accept       json
+// @Produce      json
+// @Param        Authorization header string             true "tma and the web app init data"
+// @Param        id         path   string             true "Question ID"
+// @Param        question   body   quiz.QuestionInput true "Question"
+// @Success      200  {object} quiz.BankQuestion
+// @Failure      400  {object} ErrorResponse
+// @Failure      401  {object} ErrorResponse
+// @Failure      403  {object} ErrorResponse
+// @Failure      404  {object} ErrorResponse
+// @Failure      409  {object} ErrorResponse
+// @Failure      500  {object} ErrorResponse
+// @Router       /questions/{id} [put]
+func (h *HandlerV1) EditQuestion(c *gin.Context) {
+	id, ok := questionID(c)
+	if !ok {
+		return
+	}
+	var input quiz.QuestionInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	question, err := h.bank.Edit(c.Request.Context(), id, callerID(c), input)
+	if err != nil {
+		questionFailed(c, err, "editing")
+		return
+	}
+	c.JSON(http.StatusOK, question)
+}
+
+// @Summary     Review Question
+// @Description This API approves a bank question, adding it to quizzes, or rejects it. Approved questions can be rejected later to take them out of quizzes. The review is recorded in the question's history. Teachers and admins only.
+// @Tags         Question
+// @Accept       json
+// @Produce      json
+// @Param        Authorization header string                true "tma and the web app init data"
+// @Param        id         path   string                true "Question ID"
+// @Param        review     body   models.QuestionReview true "approved or rejected, with an optional note"
+// @Success      200  {object} quiz.BankQuestion
+// @Failure      400  {object} ErrorResponse
+// @Failure      401  {object} ErrorResponse
+// @Failure      403  {object} ErrorResponse
+// @Failure      404  {object} ErrorResponse
+// @Failure      409  {object} ErrorResponse
+// @Failure      500  {object} ErrorResponse
+// @Router       /questions/{id}/review [put]
+func (h *HandlerV1) ReviewQuestion(c *gin.Context) {
+	id, ok := questionID(c)
+	if !ok {
+		return
+	}
+	var review models.QuestionReview
+	if err := c.ShouldBindJSON(&review); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if review.Status != quiz.StatusApproved && review.Status != quiz.StatusRejected {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status must be one of approved, rejected"})
+		return
+	}
+
+	question, err := h.bank.Review(c.Request.Context(), id, callerID(c), review.Status, review.Note)
+	if err != nil {
+		questionFailed(c, err, "reviewing")
+		return
+	}
+	c.JSON(http.StatusOK, question)
+}
+
+// @Summary     Question History
+// @Description This API returns the imports, edits and reviews of a bank question, oldest first, each with the question before and after it. Teachers and admins only.
+// @Tags         Question
+// @Accept       json
+// @Produce      json
+// @Param        Authorization header string true "tma and the web app init data"
+// @Param        id         path   string true "Question ID"
+// @Success      200  {array}  quiz.AuditEntry
+// @Failure      400  {object} ErrorResponse
+// @Failure      401  {object} ErrorResponse
+// @Failure      403  {object} ErrorResponse
+// @Failure      404  {object} ErrorResponse
+// @Failure      500  {object} ErrorResponse
+// @Router       /questions/{id}/history [get]
+func (h *HandlerV1) QuestionHistory(c *gin.Context) {
+	id, ok := questionID(c)
+	if !ok {
+		return
+	}
+	history, err := h.bank.History(c.Request.Context(), id)
+	if err != nil {
+		questionFailed(c, err, "fetching")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"history": history})
+}
+
+// @Summary     List Question Timings
+// @Description This API reports how bank questions fared in timed quizzes: how often they were served, the share answered correctly in time, the share answered late or not at all, the mean share of the time limit used and the median answer time. Questions served often enough get a suggested difficulty: EASY when most answer correctly with time to spare, HARD when most fail or take nearly all the time, MEDIUM otherwise. Teachers and admins only.
+// @Tags         Question
+// @Produce      json
+// @Param        Authorization header string true  "tma and the web app init data"
+// @Param        difficulty  query  string false "Difficulty" Enums(EASY, MEDIUM, HARD)
+// @Param        topic       query  string false "Topic"
+// @Param        mismatched  query  bool   false "Only questions whose suggested difficulty differs from their difficulty"
+// @Param        sort        query  string false "Sort key, prefixed with - for descending order, -served by default" Enums(served, -served, correct_rate, -correct_rate, late_rate, -late_rate, time_used, -time_used)
+// @Param        limit       query  int    false "Page size (1-100)"
+// @Param        cursor      query  string false "next_cursor of the previous page"
+// @Success      200  {array}  quiz.QuestionTiming
+// @Failure      400  {object} ErrorResponse
+// @Failure      401  {object} ErrorResponse
+// @Failure      403  {object} ErrorResponse
+// @Failure      500  {object} ErrorResponse
+// @Router       /questions/timings [get]
+func (h *HandlerV1) ListQuestionTimings(c *gin.Context) {
+	q, err := parsePageQuery(c, timingSorts, "-served", "uuid")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if difficulty := c.Query("difficulty"); difficulty != "" {
+		q.Filter("difficulty = ?", strings.ToUpper(difficulty))
+	}
+	if topic := c.Query("topic"); topic != "" {
+		q.Filter("topic = ?", topic)
+	}
+	if c.Query("mismatched") == "true" {
+		q.Filter("suggested_difficulty <> difficulty")
+	}
+
+	timings := []quiz.QuestionTiming{}
+	total, nextCursor, err := h.listPage(&timings, quiz.TimingsQuery, "SELECT COUNT(*) FROM ("+quiz.TimingsQuery+") counted", q)
+	if err != nil {
+		log.Printf("Error fetching question timings: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching question timings"})
+		return
+	}
+
+	c.JSON(http.StatusOK, pageResponse("questions", timings, total, nextCursor))
+}
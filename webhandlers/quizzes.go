@@ -0,0 +1,219 @@
+package webhandlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"worker-bot/models"
+	"worker-bot/quiz"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary     List Quiz Topics
+// @Description This API lists the topics quizzes can be started with.
+// @Tags         Question
+// @Produce      json
+// @Success      200  {array} quiz.Topic
+// @Router       /quiz/topics [get]
+func (h *HandlerV1) ListQuizTopics(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"topics": quiz.Topics})
+}
+
+// @Summary     List User Ratings
+// @Description This API lists the skill ratings of a user, overall under general and per topic. Ratings start at 1200, the rating of MEDIUM questions, with EASY questions at 1000 and HARD at 1400, and move with every answered question.
+// @Tags         Question
+// @Produce      json
+// @Param        Authorization header string true "tma and the web app init data"
+// @Param        id   path int true "User ID"
+// @Success      200  {array} quiz.Rating
+// @Failure      400  {object} ErrorResponse
+// @Failure      401  {object} ErrorResponse
+// @Failure      403  {object} ErrorResponse
+// @Failure      500  {object} ErrorResponse
+// @Router       /user/{id}/ratings [get]
+func (h *HandlerV1) ListUserRatings(c *gin.Context) {
+	userID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+	ratings, err := h.quizzes.Ratings(c.Request.Context(), userID)
+	if err != nil {
+		log.Printf("Error listing ratings: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error listing ratings"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"ratings": ratings})
+}
+
+// @Summary     Start Event Quiz
+// @Description This API starts the quiz of an event the user checked in to. It opens when the event is completed and closes after the event quiz window, and earns bonus XP on top of the usual reward. It is timed as MEDIUM quizzes are. Each user has one session of it open at a time and submits it once.
+// @Tags         Question
+// @Accept       json
+// @Produce      json
+// @Param        Authorization header string true "tma and the web app init data"
+// @Param        id       path int    true "User ID"
+// @Param        eventId  path string true "Event ID"
+// @Success      201  {object} quiz.Session
+// @Failure      400  {object} ErrorResponse
+// @Failure      401  {object} ErrorResponse
+// @Failure      403  {object} ErrorResponse
+// @Failure      409  {object} ErrorResponse
+// @Failure      500  {object} ErrorResponse
+// @Failure      503  {object} ErrorResponse
+// @Router       /user/{id}/events/{eventId}/quiz [post]
+func (h *HandlerV1) StartEventQuiz(c *gin.Context) {
+	userID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+	eventID := c.Param("eventId")
+	ctx := c.Request.Context()
+
+	if err := h.quizzes.CheckEventQuiz(ctx, userID, eventID, h.eventQuizzes); err != nil {
+		eventQuizFailed(c, err)
+		return
+	}
+	drawn, err := h.bank.DrawEvent(ctx, eventID, quiz.QuestionCount)
+	if err != nil {
+		eventQuizFailed(c, err)
+		return
+	}
+	if len(drawn.Questions) == 0 {
+		eventQuizFailed(c, quiz.ErrNoQuestions)
+		return
+	}
+
+	session, err := h.quizzes.CreateForEvent(ctx, userID, eventID, h.eventQuizzes, drawn)
+	if err != nil {
+		eventQuizFailed(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, session)
+}
+
+// eventQuizFailed answers a failed attempt to start an event quiz.
+func eventQuizFailed(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, quiz.ErrNotAttended):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only attendees can take the event quiz"})
+	case errors.Is(err, quiz.ErrEventQuizClosed):
+		c.JSON(http.StatusConflict, gin.H{"error": "The event quiz is not open"})
+	case errors.Is(err, quiz.ErrAlreadySubmitted):
+		c.JSON(http.StatusConflict, gin.H{"error": "Event quiz was already submitted"})
+	case errors.Is(err, quiz.ErrEventQuizOpen):
+		c.JSON(http.StatusConflict, gin.H{"error": "Event quiz is already in progress"})
+	case errors.Is(err, quiz.ErrNoQuestions):
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "The event quiz is not ready yet"})
+	default:
+		log.Printf("Error starting event quiz: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error starting event quiz"})
+	}
+}
+
+// quizQuestion reads the user ID and question number path parameters,
+// answering malformed ones with a 400.
+func quizQuestion(c *gin.Context) (int64, int, bool) {
+	userID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return 0, 0, false
+	}
+	number, err := strconv.Atoi(c.Param("number"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid question number"})
+		return 0, 0, false
+	}
+	return userID, number, true
+}
+
+// timedQuizFailed answers a failed request on a question of a timed quiz.
+func timedQuizFailed(c *gin.Context, err error, action string) {
+	switch {
+	case errors.Is(err, quiz.ErrSessionNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Quiz session not found"})
+	case errors.Is(err, quiz.ErrNoSuchQuestion):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Question not found"})
+	case errors.Is(err, quiz.ErrInvalidAnswer):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, quiz.ErrAlreadySubmitted):
+		c.JSON(http.StatusConflict, gin.H{"error": "Quiz was already submitted"})
+	case errors.Is(err, quiz.ErrNotTimed), errors.Is(err, quiz.ErrNotServed), errors.Is(err, quiz.ErrAlreadyAnswered):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, quiz.ErrSessionExpired):
+		c.JSON(http.StatusGone, gin.H{"error": "Quiz session has expired"})
+	default:
+		log.Printf("Error %s quiz question: %v", action, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error " + action + " question"})
+	}
+}
+
+// @Summary     Serve Quiz Question
+// @Description This API serves a question of a timed quiz, by number from 1, and starts its time limit. Serving it again returns the same deadline. Answers after the deadline count wrong.
+// @Tags         Question
+// @Produce      json
+// @Param        Authorization header string true "tma and the web app init data"
+// @Param        id         path int    true "User ID"
+// @Param        sessionId  path string true "Quiz session ID"
+// @Param        number     path int    true "Question number"
+// @Success      200  {object} quiz.Served
+// @Failure      400  {object} ErrorResponse
+// @Failure      401  {object} ErrorResponse
+// @Failure      403  {object} ErrorResponse
+// @Failure      404  {object} ErrorResponse
+// @Failure      409  {object} ErrorResponse
+// @Failure      410  {object} ErrorResponse
+// @Failure      500  {object} ErrorResponse
+// @Router       /user/{id}/quizzes/{sessionId}/questions/{number} [post]
+func (h *HandlerV1) ServeQuizQuestion(c *gin.Context) {
+	userID, number, ok := quizQuestion(c)
+	if !ok {
+		return
+	}
+	served, err := h.quizzes.Serve(c.Request.Context(), userID, c.Param("sessionId"), number)
+	if err != nil {
+		timedQuizFailed(c, err, "serving")
+		return
+	}
+	c.JSON(http.StatusOK, served)
+}
+
+// @Summary     Answer Quiz Question
+// @Description This API records the answer to a served question of a timed quiz. Each question is answered once, and late answers count wrong. Whether it is correct is told when the quiz is submitted.
+// @Tags         Question
+// @Accept       json
+// @Produce      json
+// @Param        Authorization header string true "tma and the web app init data"
+// @Param        id         path int               true "User ID"
+// @Param        sessionId  path string            true "Quiz session ID"
+// @Param        number     path int               true "Question number"
+// @Param        answer     body models.QuizAnswer true "Chosen variant"
+// @Success      200  {object} quiz.Answered
+// @Failure      400  {object} ErrorResponse
+// @Failure      401  {object} ErrorResponse
+// @Failure      403  {object} ErrorResponse
+// @Failure      404  {object} ErrorResponse
+// @Failure      409  {object} ErrorResponse
+// @Failure      410  {object} ErrorResponse
+// @Failure      500  {object} ErrorResponse
+// @Router       /user/{id}/quizzes/{sessionId}/questions/{number}/answer [post]
+func (h *HandlerV1) AnswerQuizQuestion(c *gin.Context) {
+	userID, number, ok := quizQuestion(c)
+	if !ok {
+		return
+	}
+	var request models.QuizAnswer
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	answered, err := h.quizzes.Answer(c.Request.Context(), userID, c.Param("sessionId"), number, request.Answer)
+	if err != nil {
+		timedQuizFailed(c, err, "answering")
+		return
+	}
+	c.JSON(http.StatusOK, answered)
+}
@@ -0,0 +1,406 @@
+package webhandlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+	"worker-bot/leaderboard"
+	"worker-bot/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+)
+
+var rankingSorts = map[string]sortKey{
+	"xp": {Column: "xp", Field: "xp", Cast: "bigint"},
+}
+
+// parseBoard reads the period and rank query parameters. Seasons are
+// resolved by seasonBoard.
+func parseBoard(c *gin.Context) (leaderboard.Board, error) {
+	var b leaderboard.Board
+
+	if period := c.DefaultQuery("period", leaderboard.PeriodAll); period != leaderboard.PeriodSeason {
+		since, err := leaderboard.PeriodStart(period, time.Now())
+		if err != nil {
+			return b, err
+		}
+		b.Since = since
+	}
+
+	switch c.DefaultQuery("rank", "competition") {
+	case "competition":
+	case "dense":
+		b.Dense = true
+	default:
+		return b, errors.New("rank must be one of competition, dense")
+	}
+
+	switch c.DefaultQuery("scope", "global") {
+	case "global", "region", "friends":
+	default:
+		return b, errors.New("scope must be one of global, region, friends")
+	}
+	return b, nil
+}
+
+// seasonBoard switches b to the season asked for by the season query
+// parameter, or to the running season for period=season. It returns a status
+// and message for the client when the season cannot be resolved.
+func (h *HandlerV1) seasonBoard(c *gin.Context, b *leaderboard.Board) (int, string, error) {
+	var (
+		season models.Season
+		err    error
+	)
+	ctx := c.Request.Context()
+
+	if value := c.Query("season"); value != "" {
+		id, convErr := strconv.Atoi(value)
+		if convErr != nil {
+			return http.StatusBadRequest, "Invalid season ID", nil
+		}
+		season, err = h.board.Season(ctx, id)
+	} else if c.Query("period") == leaderboard.PeriodSeason {
+		season, err = h.board.CurrentSeason(ctx, time.Now())
+	} else {
+		return 0, "", nil
+	}
+
+	switch err {
+	case nil:
+	case leaderboard.ErrSeasonNotFound:
+		return http.StatusNotFound, "Season not found", nil
+	case leaderboard.ErrNoSeason:
+		return http.StatusNotFound, "No season is running", nil
+	default:
+		return 0, "", err
+	}
+
+	sb := leaderboard.SeasonBoard(season)
+	b.Since, b.Until, b.Season = sb.Since, sb.Until, sb.Season
+	return 0, "", nil
+}
+
+// scopeBoard limits b to the region or the friends of userID as asked by the
+// scope query parameter. It returns a message for the client when the scope
+// cannot be resolved.
+func (h *HandlerV1) scopeBoard(c *gin.Context, b *leaderboard.Board, userID int64) (string, error) {
+	switch c.DefaultQuery("scope", "global") {
+	case "region":
+		b.Region = c.Query("region")
+		if b.Region == "" && userID != 0 {
+			err := h.db.GetContext(c.Request.Context(), &b.Region, "SELECT COALESCE(region, '') FROM users WHERE id = $1", userID)
+			if err != nil && err != sql.ErrNoRows {
+				return "", err
+			}
+		}
+		if b.Region == "" {
+			return "region is required for the region scope", nil
+		}
+	case "friends":
+		if userID == 0 {
+			return "a user is required for the friends scope", nil
+		}
+		b.UserIDs = []int64{userID}
+		var friends []int64
+		err := h.db.SelectContext(c.Request.Context(), &friends, "SELECT friend_id FROM friendships WHERE user_id = $1", userID)
+		if err != nil {
+			return "", err
+		}
+		b.UserIDs = append(b.UserIDs, friends...)
+	}
+	return "", nil
+}
+
+// boardFor parses and scopes the board of a ranking request, writing the
+// error response itself when it returns false.
+func (h *HandlerV1) boardFor(c *gin.Context, userID int64) (leaderboard.Board, bool) {
+	b, err := parseBoard(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return b, false
+	}
+	status, msg, err := h.seasonBoard(c, &b)
+	if err != nil {
+		log.Printf("Error resolving season: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching data"})
+		return b, false
+	}
+	if msg != "" {
+		c.JSON(status, gin.H{"error": msg})
+		return b, false
+	}
+	msg, err = h.scopeBoard(c, &b, userID)
+	if err != nil {
+		log.Printf("Error resolving leaderboard scope: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching data"})
+		return b, false
+	}
+	if msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return b, false
+	}
+	return b, true
+}
+
+// syncLeaderboard copies the current XP of users from Postgres into the
+// leaderboard. Call it after the transaction that changed their XP commits.
+func (h *HandlerV1) syncLeaderboard(ctx context.Context, userIDs ...int64) {
+	var users []models.User
+	err := h.db.SelectContext(ctx, &users, "SELECT id, xp FROM users WHERE id = ANY($1)", pq.Array(userIDs))
+	if err != nil {
+		log.Printf("Error fetching XP for leaderboard: %v", err)
+		return
+	}
+	for _, user := range users {
+		h.board.Set(ctx, int64(user.ID), int64(user.XP))
+	}
+}
+
+// rankingResponses joins leaderboard entries with the users' profiles.
+func (h *HandlerV1) rankingResponses(ctx context.Context, entries []leaderboard.Entry) ([]models.RankingResponse, error) {
+	ids := make([]int64, len(entries))
+	for i, entry := range entries {
+		ids[i] = entry.UserID
+	}
+
+	var users []models.User
+	query := `SELECT id, first_name, last_name, avatar, xp, location FROM users WHERE id = ANY($1)`
+	if err := h.db.SelectContext(ctx, &users, query, pq.Array(ids)); err != nil {
+		return nil, err
+	}
+	byID := make(map[int64]models.User, len(users))
+	for _, user := range users {
+		byID[int64(user.ID)] = user
+	}
+
+	rankings := make([]models.RankingResponse, 0, len(entries))
+	for _, entry := range entries {
+		user, ok := byID[entry.UserID]
+		if !ok {
+			continue
+		}
+		rankings = append(rankings, models.RankingResponse{
+			ID:       user.ID,
+			Rank:     int(entry.Rank),
+			UserName: user.FirstName + " " + user.LastName,
+			XP:       int(entry.XP),
+			Avatar:   "https://i.pravatar.cc/150?img=" + strconv.Itoa(int(entry.Rank)-1),
+			Location: user.Location,
+		})
+	}
+	return rankings, nil
+}
+
+// @Summary     Get Rankings
+// @Description This API returns a leaderboard page by page. Boards can be limited to a region or to the caller and their friends, and to XP earned today, this week, this month or this season. Past seasons are served from their archived final standings. Users with equal XP share a rank. When Authorization is sent the caller's own entry is returned as "me", even outside the page.
+// @Tags  	    Ranking
+// @Accept      json
+// @Produce     json
+// @Param       Authorization header string false "tma and the web app init data"
+// @Param       scope      query  string false "Who is ranked" Enums(global, region, friends)
+// @Param       region     query  string false "Region for the region scope, the caller's region by default"
+// @Param       period     query  string false "XP earned within" Enums(all, today, week, month, season)
+// @Param       season     query  int    false "Season ID, overrides period"
+// @Param       rank       query  string false "competition (1, 2, 2, 4) or dense (1, 2, 2, 3)" Enums(competition, dense)
+// @Param       limit      query  int    false "Page size (1-100)"
+// @Param       cursor     query  string false "next_cursor of the previous page"
+// @Success     200 {object} []models.RankingResponse
+// @Failure     400 {object} ErrorResponse
+// @Failure     404 {object} ErrorResponse
+// @Failure     500 {object} ErrorResponse
+// @Router      /ranking [get]
+func (h *HandlerV1) GetRanking(c *gin.Context) {
+	q, err := parsePageQuery(c, rankingSorts, "-xp", "bigint")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	caller, err := h.authenticate(c)
+	if err != nil && err != errNoCaller {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid Authorization header"})
+		return
+	}
+
+	board, ok := h.boardFor(c, caller)
+	if !ok {
+		return
+	}
+
+	var after int64
+	if q.Cursor != nil {
+		after, err = strconv.ParseInt(q.Cursor.ID, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid cursor"})
+			return
+		}
+	}
+
+	ctx := c.Request.Context()
+	entries, err := h.board.Page(ctx, board, after, q.Limit+1)
+	if err != nil {
+		log.Printf("Error fetching leaderboard: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching data"})
+		return
+	}
+
+	var nextCursor string
+	if len(entries) > q.Limit {
+		entries = entries[:q.Limit]
+		last := entries[len(entries)-1]
+		nextCursor = encodeCursor(cursor{
+			Value: strconv.FormatInt(last.XP, 10),
+			ID:    strconv.FormatInt(last.UserID, 10),
+		})
+	}
+
+	total, err := h.board.Total(ctx, board)
+	if err != nil {
+		log.Printf("Error counting leaderboard: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching data"})
+		return
+	}
+
+	rankings, err := h.rankingResponses(ctx, entries)
+	if err != nil {
+		log.Printf("Error fetching ranked users: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching data"})
+		return
+	}
+
+	resp := pageResponse("rankings", rankings, int(total), nextCursor)
+	resp["me"] = nil
+	if caller != 0 {
+		entry, err := h.board.Rank(ctx, board, caller)
+		if err != nil && err != leaderboard.ErrNotRanked {
+			log.Printf("Error fetching caller rank: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching data"})
+			return
+		}
+		if err == nil {
+			mine, err := h.rankingResponses(ctx, []leaderboard.Entry{entry})
+			if err != nil {
+				log.Printf("Error fetching ranked users: %v", err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching data"})
+				return
+			}
+			if len(mine) == 1 {
+				resp["me"] = mine[0]
+			}
+		}
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// @Summary     Get User Rank
+// @Description This API returns the rank of a single user on the global, regional or friends board
+// @Tags  	    Ranking
+// @Accept      json
+// @Produce     json
+// @Param       id      path  int    true  "User ID"
+// @Param       scope   query string false "Who is ranked" Enums(global, region, friends)
+// @Param       region  query string false "Region for the region scope, the user's region by default"
+// @Param       period  query string false "XP earned within" Enums(all, today, week, month, season)
+// @Param       season  query int    false "Season ID, overrides period"
+// @Param       rank    query string false "competition (1, 2, 2, 4) or dense (1, 2, 2, 3)" Enums(competition, dense)
+// @Success     200 {object} models.RankingResponse
+// @Failure     400 {object} ErrorResponse
+// @Failure     404 {object} ErrorResponse
+// @Failure     500 {object} ErrorResponse
+// @Router      /ranking/user/{id} [get]
+func (h *HandlerV1) GetUserRank(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	board, ok := h.boardFor(c, id)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	entry, err := h.board.Rank(ctx, board, id)
+	if err != nil {
+		if err == leaderboard.ErrNotRanked {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		} else {
+			log.Printf("Error fetching user rank: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching data"})
+		}
+		return
+	}
+
+	rankings, err := h.rankingResponses(ctx, []leaderboard.Entry{entry})
+	if err != nil || len(rankings) == 0 {
+		log.Printf("Error fetching ranked user: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching data"})
+		return
+	}
+
+	c.JSON(http.StatusOK, rankings[0])
+}
+
+// @Summary     Get Rankings Around User
+// @Description This API returns the users ranked directly above and below a user
+// @Tags  	    Ranking
+// @Accept      json
+// @Produce     json
+// @Param       id      path  int    true  "User ID"
+// @Param       radius  query int    false "Number of users on each side (1-50, default 5)"
+// @Param       scope   query string false "Who is ranked" Enums(global, region, friends)
+// @Param       region  query string false "Region for the region scope, the user's region by default"
+// @Param       period  query string false "XP earned within" Enums(all, today, week, month, season)
+// @Param       season  query int    false "Season ID, overrides period"
+// @Param       rank    query string false "competition (1, 2, 2, 4) or dense (1, 2, 2, 3)" Enums(competition, dense)
+// @Success     200 {object} []models.RankingResponse
+// @Failure     400 {object} ErrorResponse
+// @Failure     404 {object} ErrorResponse
+// @Failure     500 {object} ErrorResponse
+// @Router      /ranking/user/{id}/around [get]
+func (h *HandlerV1) GetRankingAround(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	radius, err := strconv.Atoi(c.DefaultQuery("radius", "5"))
+	if err != nil || radius < 1 || radius > 50 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "radius must be between 1 and 50"})
+		return
+	}
+
+	board, ok := h.boardFor(c, id)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	entries, err := h.board.Around(ctx, board, id, radius)
+	if err != nil {
+		if err == leaderboard.ErrNotRanked {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		} else {
+			log.Printf("Error fetching leaderboard: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching data"})
+		}
+		return
+	}
+
+	rankings, err := h.rankingResponses(ctx, entries)
+	if err != nil {
+		log.Printf("Error fetching ranked users: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching data"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"rankings": rankings})
+}
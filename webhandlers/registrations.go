@@ -0,0 +1,365 @@
+package webhandlers
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"worker-bot/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+)
+
+// registrationPosition computes the waitlist position of registration r.
+const registrationPosition = `CASE WHEN r.status = 'waitlisted' THEN
+				(SELECT COUNT(*) FROM event_registrations w
+				 WHERE w.event_id = r.event_id AND w.status = 'waitlisted' AND w.id <= r.id) END`
+
+type eventState struct {
+	Name     string `db:"name"`
+	Capacity *int   `db:"capacity"`
+	// Closed is true once the registration cutoff has passed.
+	Closed  bool   `db:"closed"`
+	Started bool   `db:"started"`
+	Status  string `db:"status"`
+}
+
+// lockEvent locks an event row so registrations for it are serialised.
+func lockEvent(ctx context.Context, tx *sqlx.Tx, eventID string) (eventState, error) {
+	var e eventState
+	err := tx.GetContext(ctx, &e, `SELECT name, capacity,
+				start_date - make_interval(mins => registration_cutoff_minutes) <= CURRENT_TIMESTAMP AS closed,
+				start_date <= CURRENT_TIMESTAMP AS started, status
+			  FROM events WHERE id = $1 FOR UPDATE`, eventID)
+	return e, err
+}
+
+// promoteWaitlist moves waitlisted users into the free places of a locked
+// event in the order they joined and returns the promoted users.
+func promoteWaitlist(ctx context.Context, tx *sqlx.Tx, eventID string) ([]int64, error) {
+	var promoted []int64
+	err := tx.SelectContext(ctx, &promoted, `UPDATE event_registrations SET status = 'registered', updated_at = CURRENT_TIMESTAMP
+			  WHERE id IN (
+				SELECT id FROM event_registrations
+				WHERE event_id = $1 AND status = 'waitlisted'
+				ORDER BY id
+				-- LIMIT NULL promotes everyone once the capacity is removed.
+				LIMIT (SELECT CASE WHEN e.capacity IS NULL THEN NULL
+						ELSE GREATEST(e.capacity - (SELECT COUNT(*) FROM event_registrations
+							WHERE event_id = $1 AND status = 'registered'), 0) END
+					   FROM events e WHERE e.id = $1))
+			  RETURNING user_id`, eventID)
+	return promoted, err
+}
+
+// notifyPromoted tells users taken off the waitlist that they have a place.
+func (h *HandlerV1) notifyPromoted(eventName string, userIDs []int64) {
+	text := fmt.Sprintf("\"%s\" tadbirida joy bo'shadi. Siz ishtirokchilar ro'yxatiga qo'shildingiz!", eventName)
+	for _, userID := range userIDs {
+		if err := h.notifier.Notify(userID, text); err != nil {
+			log.Printf("Error notifying user %d: %v", userID, err)
+		}
+	}
+}
+
+// fillFromWaitlist promotes waitlisted users of an event that has not started
+// into any free places, e.g. after its capacity was raised.
+func (h *HandlerV1) fillFromWaitlist(ctx context.Context, eventID string) error {
+	tx, err := h.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	event, err := lockEvent(ctx, tx, eventID)
+	if err == sql.ErrNoRows || (err == nil && (event.Started || event.Status == models.EventCancelled)) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	promoted, err := promoteWaitlist(ctx, tx, eventID)
+	if err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	h.notifyPromoted(event.Name, promoted)
+	return nil
+}
+
+// @Summary     Join Event
+// @Description This API registers a user for an event. When the event is full the user is put on the waitlist and gets a place, with a Telegram notification, as soon as someone leaves. Registration closes registration_cutoff_minutes before the event starts.
+// @Tags         Event
+// @Accept       json
+// @Produce      json
+// @Param        Authorization header string true "tma and the web app init data"
+// @Param        id       path int    true "User ID"
+// @Param        eventId  path string true "Event ID"
+// @Success      201  {object} models.EventRegistration
+// @Failure      400  {object} ErrorResponse
+// @Failure      401  {object} ErrorResponse
+// @Failure      403  {object} ErrorResponse
+// @Failure      404  {object} ErrorResponse
+// @Failure      409  {object} ErrorResponse
+// @Failure      500  {object} ErrorResponse
+// @Router       /user/{id}/events/{eventId} [post]
+func (h *HandlerV1) JoinEvent(c *gin.Context) {
+	userID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+	eventID := c.Param("eventId")
+	ctx := c.Request.Context()
+
+	var exists bool
+	if err := h.db.Get(&exists, "SELECT EXISTS (SELECT 1 FROM users WHERE id = $1)", userID); err != nil {
+		log.Printf("Error fetching user: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error joining event"})
+		return
+	}
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	tx, err := h.db.Beginx()
+	if err != nil {
+		log.Printf("Error starting transaction: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error joining event"})
+		return
+	}
+	defer tx.Rollback()
+
+	event, err := lockEvent(ctx, tx, eventID)
+	if err == nil && event.Status == models.EventDraft {
+		err = sql.ErrNoRows
+	}
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+		} else {
+			log.Printf("Error fetching event: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error joining event"})
+		}
+		return
+	}
+	if event.Status == models.EventCancelled {
+		c.JSON(http.StatusConflict, gin.H{"error": "Event was cancelled"})
+		return
+	}
+	if event.Closed {
+		c.JSON(http.StatusConflict, gin.H{"error": "Registration is closed"})
+		return
+	}
+
+	status := models.RegistrationRegistered
+	if event.Capacity != nil {
+		var registered int
+		err = tx.Get(&registered, "SELECT COUNT(*) FROM event_registrations WHERE event_id = $1 AND status = 'registered'", eventID)
+		if err != nil {
+			log.Printf("Error counting registrations: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error joining event"})
+			return
+		}
+		if registered >= *event.Capacity {
+			status = models.RegistrationWaitlisted
+		}
+	}
+
+	registration := models.EventRegistration{EventName: event.Name}
+	err = tx.Get(&registration, `INSERT INTO event_registrations (event_id, user_id, status) VALUES ($1, $2, $3)
+			  ON CONFLICT (event_id, user_id) DO NOTHING
+			  RETURNING event_id, user_id, status, created_at`,
+		eventID, userID, status)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusConflict, gin.H{"error": "Already registered for this event"})
+		} else {
+			log.Printf("Error inserting registration: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error joining event"})
+		}
+		return
+	}
+	if status == models.RegistrationWaitlisted {
+		err = tx.Get(&registration.Position, `SELECT `+registrationPosition+` FROM event_registrations r
+				  WHERE r.event_id = $1 AND r.user_id = $2`, eventID, userID)
+		if err != nil {
+			log.Printf("Error fetching waitlist position: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error joining event"})
+			return
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing registration: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error joining event"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, registration)
+}
+
+// @Summary     Leave Event
+// @Description This API cancels a user's registration for an event that has not started. The first user on the waitlist takes the freed place.
+// @Tags         Event
+// @Accept       json
+// @Produce      json
+// @Param        Authorization header string true "tma and the web app init data"
+// @Param        id       path int    true "User ID"
+// @Param        eventId  path string true "Event ID"
+// @Success      204
+// @Failure      401  {object} ErrorResponse
+// @Failure      403  {object} ErrorResponse
+// @Failure      404  {object} ErrorResponse
+// @Failure      409  {object} ErrorResponse
+// @Failure      500  {object} ErrorResponse
+// @Router       /user/{id}/events/{eventId} [delete]
+func (h *HandlerV1) LeaveEvent(c *gin.Context) {
+	userID := c.Param("id")
+	eventID := c.Param("eventId")
+	ctx := c.Request.Context()
+
+	tx, err := h.db.Beginx()
+	if err != nil {
+		log.Printf("Error starting transaction: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error leaving event"})
+		return
+	}
+	defer tx.Rollback()
+
+	event, err := lockEvent(ctx, tx, eventID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+		} else {
+			log.Printf("Error fetching event: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error leaving event"})
+		}
+		return
+	}
+	if event.Started {
+		c.JSON(http.StatusConflict, gin.H{"error": "Event has already started"})
+		return
+	}
+
+	var status string
+	err = tx.Get(&status, `DELETE FROM event_registrations WHERE event_id = $1 AND user_id = $2 RETURNING status`,
+		eventID, userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Registration not found"})
+		} else {
+			log.Printf("Error deleting registration: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error leaving event"})
+		}
+		return
+	}
+
+	var promoted []int64
+	if status == models.RegistrationRegistered {
+		promoted, err = promoteWaitlist(ctx, tx, eventID)
+		if err != nil {
+			log.Printf("Error promoting waitlisted users: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error leaving event"})
+			return
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing registration cancellation: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error leaving event"})
+		return
+	}
+	h.notifyPromoted(event.Name, promoted)
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary     List User Registrations
+// @Description This API lists the events a user is registered or waitlisted for, latest events first
+// @Tags         Event
+// @Accept       json
+// @Produce      json
+// @Param        id  path int true "User ID"
+// @Success      200  {array}  models.EventRegistration
+// @Failure      500  {object} ErrorResponse
+// @Router       /user/{id}/events [get]
+func (h *HandlerV1) ListUserRegistrations(c *gin.Context) {
+	id := c.Param("id")
+
+	query := `SELECT r.event_id, e.name AS event_name, r.user_id, r.status, r.created_at,
+				` + registrationPosition + ` AS position
+			  FROM event_registrations r JOIN events e ON e.id = r.event_id
+			  WHERE r.user_id = $1 ORDER BY e.start_date DESC, r.id DESC`
+
+	registrations := []models.EventRegistration{}
+	if err := h.db.Select(&registrations, query, id); err != nil {
+		log.Printf("Error fetching registrations: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching registrations"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"registrations": registrations})
+}
+
+// @Summary     List Participants
+// @Description This API returns the roster of an event: registered participants followed by the waitlist in order. Officers of the event and admins only.
+// @Tags         Event
+// @Accept       json
+// @Produce      json
+// @Param        Authorization header string true  "tma and the web app init data"
+// @Param        id         path   string true  "Event ID"
+// @Param        status     query  string false "Only registered or waitlisted users" Enums(registered, waitlisted)
+// @Success      200  {array}  models.Participant
+// @Failure      400  {object} ErrorResponse
+// @Failure      401  {object} ErrorResponse
+// @Failure      403  {object} ErrorResponse
+// @Failure      404  {object} ErrorResponse
+// @Failure      500  {object} ErrorResponse
+// @Router       /event/{id}/participants [get]
+func (h *HandlerV1) ListParticipants(c *gin.Context) {
+	id := c.Param("id")
+
+	status := c.Query("status")
+	switch status {
+	case "", models.RegistrationRegistered, models.RegistrationWaitlisted:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status must be one of registered, waitlisted"})
+		return
+	}
+
+	var capacity *int
+	err := h.db.Get(&capacity, "SELECT capacity FROM events WHERE id = $1", id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+		} else {
+			log.Printf("Error fetching event: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching participants"})
+		}
+		return
+	}
+
+	query := `SELECT r.user_id, u.first_name, u.last_name, COALESCE(u.phone_number, '') AS phone_number,
+				r.status, r.created_at, r.checked_in_at, r.checked_out_at,
+				` + registrationPosition + ` AS position
+			  FROM event_registrations r JOIN users u ON u.id = r.user_id
+			  WHERE r.event_id = $1 AND ($2 = '' OR r.status = $2)
+			  ORDER BY r.status = 'waitlisted', r.id`
+
+	participants := []models.Participant{}
+	if err := h.db.Select(&participants, query, id, status); err != nil {
+		log.Printf("Error fetching participants: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching participants"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"participants": participants, "capacity": capacity})
+}
@@ -0,0 +1,107 @@
+package webhandlers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"worker-bot/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+const headlineOptions = `'StartSel=<b>, StopSel=</b>, MaxFragments=2, MaxWords=20, MinWords=5'`
+
+// escapeHTML returns an SQL expression for the text of expr with the HTML
+// special characters escaped, so the <b> tags ts_headline adds are the only
+// markup in a highlight.
+func escapeHTML(expr string) string {
+	return `replace(replace(replace(replace(` + expr + `, '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '"', '&quot;')`
+}
+
+// searchQueries holds one SELECT per result type. $1 is the raw search text
+// and tsq is the combined simple/russian tsquery built in the WITH clause.
+// Rows match on full-text search or, for typos and partial words, on trigram
+// similarity of the name. Highlights are cut from the name and description,
+// so rows matching on their name alone are highlighted too.
+var searchQueries = map[string]string{
+	"event": `SELECT 'event' AS type, e.id::text AS id, e.name AS title,
+				ts_headline('simple', ` + escapeHTML(`e.name || E'\n' || coalesce(e.description, '')`) + `, tsq.query, ` + headlineOptions + `) AS highlight,
+				GREATEST(ts_rank(e.search_vector, tsq.query), similarity(e.name, $1)) AS rank
+			  FROM events e, tsq
+			  WHERE e.status <> 'draft' AND (e.search_vector @@ tsq.query OR e.name % $1)`,
+	"market": `SELECT 'market' AS type, m.id::text AS id, m.name AS title,
+				ts_headline('simple', ` + escapeHTML(`m.name || E'\n' || coalesce(m.description, '')`) + `, tsq.query, ` + headlineOptions + `) AS highlight,
+				GREATEST(ts_rank(m.search_vector, tsq.query), similarity(m.name, $1)) AS rank
+			  FROM market m, tsq
+			  WHERE m.search_vector @@ tsq.query OR m.name % $1`,
+	"user": `SELECT 'user' AS type, u.id::text AS id, u.first_name || ' ' || u.last_name AS title,
+				ts_headline('simple', ` + escapeHTML(`u.first_name || ' ' || u.last_name`) + `, tsq.query, ` + headlineOptions + `) AS highlight,
+				GREATEST(ts_rank(u.search_vector, tsq.query), similarity(u.first_name || ' ' || u.last_name, $1)) AS rank
+			  FROM users u, tsq
+			  WHERE u.search_vector @@ tsq.query OR (u.first_name || ' ' || u.last_name) % $1`,
+}
+
+// @Summary     Search
+// @Description This API searches events, market items and users by text and returns ranked results with highlighted matches
+// @Tags         Search
+// @Accept       json
+// @Produce      json
+// @Param        q      query string true  "Search text"
+// @Param        types  query string false "Comma separated result types (event, market, user), all by default"
+// @Param        limit  query int    false "Maximum number of results (1-100)"
+// @Success      200  {array}  models.SearchResult
+// @Failure      400  {object} ErrorResponse
+// @Failure      500  {object} ErrorResponse
+// @Router       /search [get]
+func (h *HandlerV1) Search(c *gin.Context) {
+	text := strings.TrimSpace(c.Query("q"))
+	if text == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "q is required"})
+		return
+	}
+
+	limit := defaultPageLimit
+	if value := c.Query("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 || n > maxPageLimit {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 100"})
+			return
+		}
+		limit = n
+	}
+
+	types := []string{"event", "market", "user"}
+	if value := c.Query("types"); value != "" {
+		types = strings.Split(value, ",")
+	}
+
+	var parts []string
+	seen := map[string]bool{}
+	for _, t := range types {
+		t = strings.TrimSpace(t)
+		query, ok := searchQueries[t]
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "types must be a list of event, market, user"})
+			return
+		}
+		if !seen[t] {
+			seen[t] = true
+			parts = append(parts, query)
+		}
+	}
+
+	query := `WITH tsq AS (SELECT websearch_to_tsquery('simple', $1) || websearch_to_tsquery('russian', $1) AS query) ` +
+		strings.Join(parts, " UNION ALL ") +
+		` ORDER BY rank DESC LIMIT $2`
+
+	results := []models.SearchResult{}
+	err := h.db.Select(&results, query, text, limit)
+	if err != nil {
+		log.Printf("Error searching: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error searching"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"results": results})
+}
@@ -0,0 +1,192 @@
+package webhandlers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"worker-bot/leaderboard"
+	"worker-bot/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+var seasonSorts = map[string]sortKey{
+	"starts_at": {Column: "starts_at", Field: "starts_at", Cast: "timestamptz"},
+	"ends_at":   {Column: "ends_at", Field: "ends_at", Cast: "timestamptz"},
+}
+
+// @Summary     List Seasons
+// @Description This API lists seasons, newest first by default. Closed seasons have closed_at set and their final standings can be browsed with /ranking?season=ID
+// @Tags        Seasons
+// @Accept      json
+// @Produce     json
+// @Param       status  query string false "Only running, upcoming or closed seasons" Enums(running, upcoming, closed)
+// @Param       sort    query string false "Sort key, prefixed with - for descending order" Enums(starts_at, -starts_at, ends_at, -ends_at)
+// @Param       limit   query int    false "Page size (1-100)"
+// @Param       cursor  query string false "next_cursor of the previous page"
+// @Success     200 {array}  models.Season
+// @Failure     400 {object} ErrorResponse
+// @Failure     500 {object} ErrorResponse
+// @Router      /seasons [get]
+func (h *HandlerV1) ListSeasons(c *gin.Context) {
+	q, err := parsePageQuery(c, seasonSorts, "-starts_at", "int")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	switch c.Query("status") {
+	case "":
+	case "running":
+		q.Filter("starts_at <= now() AND ends_at > now()")
+	case "upcoming":
+		q.Filter("starts_at > now()")
+	case "closed":
+		q.Filter("closed_at IS NOT NULL")
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status must be one of running, upcoming, closed"})
+		return
+	}
+
+	seasons := []models.Season{}
+	total, nextCursor, err := h.listPage(&seasons, "SELECT id, name, starts_at, ends_at, closed_at FROM seasons",
+		"SELECT COUNT(*) FROM seasons", q)
+	if err != nil {
+		log.Printf("Error fetching seasons: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching seasons"})
+		return
+	}
+
+	c.JSON(http.StatusOK, pageResponse("seasons", seasons, total, nextCursor))
+}
+
+// @Summary     Get Season
+// @Description This API returns a season with its prizes
+// @Tags        Seasons
+// @Accept      json
+// @Produce     json
+// @Param       id  path int true "Season ID"
+// @Success     200 {object} models.Season
+// @Failure     400 {object} ErrorResponse
+// @Failure     404 {object} ErrorResponse
+// @Failure     500 {object} ErrorResponse
+// @Router      /seasons/{id} [get]
+func (h *HandlerV1) GetSeason(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid season ID"})
+		return
+	}
+
+	season, err := h.board.Season(c.Request.Context(), id)
+	if err != nil {
+		if err == leaderboard.ErrSeasonNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Season not found"})
+		} else {
+			log.Printf("Error fetching season: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching season"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, season)
+}
+
+// @Summary     Create Season
+// @Description This API creates a season with the prizes awarded to the top users of every region when it closes. Admins only.
+// @Tags        Seasons
+// @Accept      json
+// @Produce     json
+// @Param       Authorization header string         true "tma and the web app init data"
+// @Param       season     body   models.Season  true "Season"
+// @Success     201 {object} models.Season
+// @Failure     400 {object} ErrorResponse
+// @Failure     401 {object} ErrorResponse
+// @Failure     403 {object} ErrorResponse
+// @Failure     500 {object} ErrorResponse
+// @Router      /seasons [post]
+func (h *HandlerV1) CreateSeason(c *gin.Context) {
+	var season models.Season
+	if err := c.ShouldBindJSON(&season); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !season.EndsAt.After(season.StartsAt) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ends_at must be after starts_at"})
+		return
+	}
+	for _, prize := range season.Prizes {
+		if prize.TopN < 1 || prize.XP < 0 || (prize.XP == 0 && prize.Badge == "") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "every prize needs a positive top_n and XP or a badge"})
+			return
+		}
+	}
+
+	tx, err := h.db.Beginx()
+	if err != nil {
+		log.Printf("Error starting transaction: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error creating season"})
+		return
+	}
+	defer tx.Rollback()
+
+	err = tx.Get(&season.ID, `INSERT INTO seasons (name, starts_at, ends_at) VALUES ($1, $2, $3) RETURNING id`,
+		season.Name, season.StartsAt, season.EndsAt)
+	if err != nil {
+		log.Printf("Error inserting season: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error creating season"})
+		return
+	}
+
+	if season.Prizes == nil {
+		season.Prizes = []models.SeasonPrize{}
+	}
+	for i := range season.Prizes {
+		prize := &season.Prizes[i]
+		prize.SeasonID = season.ID
+		err = tx.Get(&prize.ID, `INSERT INTO season_prizes (season_id, top_n, xp, badge) VALUES ($1, $2, $3, $4) RETURNING id`,
+			prize.SeasonID, prize.TopN, prize.XP, prize.Badge)
+		if err != nil {
+			log.Printf("Error inserting season prize: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error creating season"})
+			return
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing season: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error creating season"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, season)
+}
+
+// @Summary     List User Badges
+// @Description This API lists the badges a user has won
+// @Tags        Seasons
+// @Accept      json
+// @Produce     json
+// @Param       id  path int true "User ID"
+// @Success     200 {array}  models.Badge
+// @Failure     400 {object} ErrorResponse
+// @Failure     500 {object} ErrorResponse
+// @Router      /user/{id}/badges [get]
+func (h *HandlerV1) ListUserBadges(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	badges := []models.Badge{}
+	err = h.db.Select(&badges, `SELECT id, user_id, season_id, badge, region, rank, awarded_at
+				FROM user_badges WHERE user_id = $1 ORDER BY awarded_at DESC, id DESC`, id)
+	if err != nil {
+		log.Printf("Error fetching badges: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching badges"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"badges": badges})
+}
@@ -0,0 +1,141 @@
+package webhandlers
+
+import (
+	"log"
+	"net/http"
+	"worker-bot/events"
+	"worker-bot/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary     Create Event Series
+// @Description This API creates a recurring event from an iCalendar RRULE, e.g. FREQ=WEEKLY;BYDAY=SA for weekly park clean-ups. Every occurrence starts at the wall clock time of start in timezone and becomes an event of its own, created up to 90 days ahead, that users register for and check in to like any other. The officers, one lead and any assistants, run every occurrence. Admins only.
+// @Tags         Event
+// @Accept       json
+// @Produce      json
+// @Param        Authorization header string              true "tma and the web app init data"
+// @Param        series     body   models.EventSeries  true "Series"
+// @Success      201  {object} models.EventSeries
+// @Failure      400  {object} ErrorResponse
+// @Failure      401  {object} ErrorResponse
+// @Failure      403  {object} ErrorResponse
+// @Failure      500  {object} ErrorResponse
+// @Router       /series [post]
+func (h *HandlerV1) CreateSeries(c *gin.Context) {
+	var series models.EventSeries
+	if err := c.ShouldBindJSON(&series); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := events.Prepare(&series); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	if err := h.series.Create(ctx, &series); err != nil {
+		if !badOfficers(c, err) {
+			log.Printf("Error creating series: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error creating series"})
+		}
+		return
+	}
+
+	created, err := h.series.Series(ctx, series.ID)
+	if err != nil {
+		log.Printf("Error fetching series: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error creating series"})
+		return
+	}
+	c.JSON(http.StatusCreated, created)
+}
+
+// @Summary     Get Event Series
+// @Description This API returns a recurring event with its occurrences that have not ended
+// @Tags         Event
+// @Accept       json
+// @Produce      json
+// @Param        id  path string true "Series ID"
+// @Success      200  {object} models.EventSeries
+// @Failure      404  {object} ErrorResponse
+// @Failure      500  {object} ErrorResponse
+// @Router       /series/{id} [get]
+func (h *HandlerV1) GetSeries(c *gin.Context) {
+	series, err := h.series.Series(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if err == events.ErrSeriesNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Series not found"})
+		} else {
+			log.Printf("Error fetching series: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching series"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, series)
+}
+
+// @Summary     Update Future Occurrences
+// @Description This API replaces the definition of a recurring event for all future occurrences, starting with the occurrence given by from or with the next one. Earlier occurrences keep the old definition, in which case the future ones move to a new series that is returned. Upcoming occurrences keep their registrations as they move, in order. Occurrences edited on their own with PUT /event/{id} are left alone. To edit a single occurrence, update its event instead. Admins only.
+// @Tags         Event
+// @Accept       json
+// @Produce      json
+// @Param        Authorization header string              true  "tma and the web app init data"
+// @Param        id         path   string              true  "Series ID"
+// @Param        from       query  string              false "Event ID of the first occurrence to change"
+// @Param        series     body   models.EventSeries  true  "New definition"
+// @Success      200  {object} models.EventSeries
+// @Failure      400  {object} ErrorResponse
+// @Failure      401  {object} ErrorResponse
+// @Failure      403  {object} ErrorResponse
+// @Failure      404  {object} ErrorResponse
+// @Failure      409  {object} ErrorResponse
+// @Failure      500  {object} ErrorResponse
+// @Router       /series/{id} [put]
+func (h *HandlerV1) UpdateSeries(c *gin.Context) {
+	var series models.EventSeries
+	if err := c.ShouldBindJSON(&series); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := events.Prepare(&series); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	id, updated, err := h.series.UpdateFuture(ctx, c.Param("id"), c.Query("from"), &series)
+	switch {
+	case err == events.ErrSeriesNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Series not found"})
+		return
+	case err == events.ErrNotOccurrence:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Occurrence not found in this series"})
+		return
+	case err == events.ErrOccurrenceStarted:
+		c.JSON(http.StatusConflict, gin.H{"error": "Occurrence has already started"})
+		return
+	case badOfficers(c, err):
+		return
+	case err != nil:
+		log.Printf("Error updating series: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error updating series"})
+		return
+	}
+
+	// A larger capacity frees places for the waitlist.
+	for _, eventID := range updated {
+		if err := h.fillFromWaitlist(ctx, eventID); err != nil {
+			log.Printf("Error promoting waitlisted users: %v", err)
+		}
+	}
+
+	result, err := h.series.Series(ctx, id)
+	if err != nil {
+		log.Printf("Error fetching series: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error updating series"})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,176 @@
+package webhandlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"worker-bot/models"
+	"worker-bot/xp"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary     List XP Flags
+// @Description This API lists accounts flagged by the anomaly detector: earning_rate flags for earning far more XP than other users, fast_quiz flags for perfect quizzes answered faster than a person reads. Admins only.
+// @Tags         XP Rules
+// @Produce      json
+// @Param        Authorization header string true  "tma and the web app init data"
+// @Param        status     query  string false "Review status, open by default" Enums(open, dismissed, confirmed)
+// @Param        reason     query  string false "Only flags for this reason" Enums(earning_rate, fast_quiz)
+// @Param        user_id    query  int    false "Only flags of this user"
+// @Param        limit      query  int    false "Page size (1-100)"
+// @Param        cursor     query  string false "next_cursor of the previous page"
+// @Success      200  {array}  xp.Flag
+// @Failure      400  {object} ErrorResponse
+// @Failure      401  {object} ErrorResponse
+// @Failure      403  {object} ErrorResponse
+// @Failure      500  {object} ErrorResponse
+// @Router       /xp/flags [get]
+func (h *HandlerV1) ListXPFlags(c *gin.Context) {
+	q, err := parsePageQuery(c, flagSorts, "-created_at", "bigint")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	switch status := c.DefaultQuery("status", models.FlagOpen); status {
+	case models.FlagOpen, models.FlagDismissed, models.FlagConfirmed:
+		q.Filter("status = ?", status)
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status must be one of open, dismissed, confirmed"})
+		return
+	}
+	switch reason := c.Query("reason"); reason {
+	case "":
+	case xp.FlagEarningRate, xp.FlagFastQuiz:
+		q.Filter("reason = ?", reason)
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "reason must be one of earning_rate, fast_quiz"})
+		return
+	}
+	if raw := c.Query("user_id"); raw != "" {
+		userID, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+			return
+		}
+		q.Filter("user_id = ?", userID)
+	}
+
+	flags := []xp.Flag{}
+	total, nextCursor, err := h.listPage(&flags, xp.FlagsQuery, "SELECT COUNT(*) FROM xp_flags", q)
+	if err != nil {
+		log.Printf("Error fetching XP flags: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching XP flags"})
+		return
+	}
+
+	c.JSON(http.StatusOK, pageResponse("flags", flags, total, nextCursor))
+}
+
+// @Summary     Review XP Flag
+// @Description This API dismisses or confirms an XP anomaly flag. Confirming it can also freeze the account, so it earns no XP and cannot start quizzes or order from the market, and reverse the XP the flag covers: the quiz's XP for fast_quiz flags, all XP earned over the flagged period for earning_rate flags. Reversed XP already spent leaves a negative balance. Admins only.
+// @Tags         XP Rules
+// @Accept       json
+// @Produce      json
+// @Param        Authorization header string              true "tma and the web app init data"
+// @Param        id         path   int                 true "Flag ID"
+// @Param        review     body   models.XPFlagReview true "Review"
+// @Success      200  {object} xp.Flag
+// @Failure      400  {object} ErrorResponse
+// @Failure      401  {object} ErrorResponse
+// @Failure      403  {object} ErrorResponse
+// @Failure      404  {object} ErrorResponse
+// @Failure      500  {object} ErrorResponse
+// @Router       /xp/flags/{id} [put]
+func (h *HandlerV1) ReviewXPFlag(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid flag ID"})
+		return
+	}
+
+	var review models.XPFlagReview
+	if err := c.ShouldBindJSON(&review); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if review.Status != models.FlagDismissed && review.Status != models.FlagConfirmed {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status must be one of dismissed, confirmed"})
+		return
+	}
+	if review.Status == models.FlagDismissed && (review.Freeze || review.Reverse) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Only confirmed flags freeze accounts or reverse XP"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	flag, err := h.rules.ReviewFlag(ctx, id, callerID(c), review.Status, review.Freeze, review.Reverse)
+	if err != nil {
+		if errors.Is(err, xp.ErrFlagNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Open flag not found"})
+		} else {
+			log.Printf("Error reviewing XP flag: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error reviewing flag"})
+		}
+		return
+	}
+	if flag.ReversedXP > 0 {
+		h.syncLeaderboard(ctx, flag.UserID)
+	}
+
+	c.JSON(http.StatusOK, flag)
+}
+
+// @Summary     Freeze User
+// @Description This API freezes an account: it earns no XP and cannot start quizzes or order from the market until unfrozen. Admins only.
+// @Tags         XP Rules
+// @Produce      json
+// @Param        Authorization header string true "tma and the web app init data"
+// @Param        id         path   int true "User ID"
+// @Success      204
+// @Failure      400  {object} ErrorResponse
+// @Failure      401  {object} ErrorResponse
+// @Failure      403  {object} ErrorResponse
+// @Failure      404  {object} ErrorResponse
+// @Failure      500  {object} ErrorResponse
+// @Router       /user/{id}/freeze [post]
+func (h *HandlerV1) FreezeUser(c *gin.Context) {
+	h.setFrozen(c, true)
+}
+
+// @Summary     Unfreeze User
+// @Description This API unfreezes a frozen account. XP reversed while it was frozen stays reversed. Admins only.
+// @Tags         XP Rules
+// @Produce      json
+// @Param        Authorization header string true "tma and the web app init data"
+// @Param        id         path   int true "User ID"
+// @Success      204
+// @Failure      400  {object} ErrorResponse
+// @Failure      401  {object} ErrorResponse
+// @Failure      403  {object} ErrorResponse
+// @Failure      404  {object} ErrorResponse
+// @Failure      500  {object} ErrorResponse
+// @Router       /user/{id}/unfreeze [post]
+func (h *HandlerV1) UnfreezeUser(c *gin.Context) {
+	h.setFrozen(c, false)
+}
+
+func (h *HandlerV1) setFrozen(c *gin.Context, frozen bool) {
+	userID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+	if err := h.rules.SetFrozen(c.Request.Context(), userID, callerID(c), frozen); err != nil {
+		if errors.Is(err, xp.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		} else {
+			log.Printf("Error freezing user: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error updating user"})
+		}
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
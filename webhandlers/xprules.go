@@ -0,0 +1,227 @@
+package webhandlers
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"worker-bot/xp"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxRulesSize bounds the size of a rule set.
+const maxRulesSize = 1 << 20
+
+var ruleSetSorts = map[string]sortKey{
+	"created_at": {Column: "created_at", Field: "created_at", Cast: "timestamptz"},
+}
+
+// ruleSetID parses the rule set ID of the request, answering 400 when it is
+// invalid.
+func ruleSetID(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid rule set ID"})
+		return 0, false
+	}
+	return id, true
+}
+
+// ruleSetFailed answers a failed request on XP rule sets.
+func ruleSetFailed(c *gin.Context, err error, action string) {
+	switch {
+	case errors.Is(err, xp.ErrRuleSetNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Rule set not found"})
+	case errors.Is(err, xp.ErrInvalidRules):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, xp.ErrRuleSetActive):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		log.Printf("Error %s rule set: %v", action, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error " + action + " rule set"})
+	}
+}
+
+// @Summary     Get XP Rules
+// @Description This API returns the XP rules in force and where they come from: the active rule set, the rules file or the built-in rules. Admins only.
+// @Tags         XP Rules
+// @Produce      json
+// @Param        Authorization header string true "tma and the web app init data"
+// @Success      200  {object} xp.ActiveRules
+// @Failure      401  {object} ErrorResponse
+// @Failure      403  {object} ErrorResponse
+// @Router       /xp/rules [get]
+func (h *HandlerV1) GetXPRules(c *gin.Context) {
+	c.JSON(http.StatusOK, h.rules.Active())
+}
+
+// @Summary     List XP Rule Sets
+// @Description This API lists the stored XP rule sets, newest first by default. Admins only.
+// @Tags         XP Rules
+// @Produce      json
+// @Param        Authorization header string true  "tma and the web app init data"
+// @Param        sort       query  string false "Sort key, prefixed with - for descending order" Enums(created_at, -created_at)
+// @Param        limit      query  int    false "Page size (1-100)"
+// @Param        cursor     query  string false "next_cursor of the previous page"
+// @Success      200  {array}  xp.RuleSet
+// @Failure      400  {object} ErrorResponse
+// @Failure      401  {object} ErrorResponse
+// @Failure      403  {object} ErrorResponse
+// @Failure      500  {object} ErrorResponse
+// @Router       /xp/rule-sets [get]
+func (h *HandlerV1) ListXPRuleSets(c *gin.Context) {
+	q, err := parsePageQuery(c, ruleSetSorts, "-created_at", "int")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	sets := []xp.RuleSet{}
+	total, nextCursor, err := h.listPage(&sets, `SELECT `+xp.RuleSetColumns+` FROM xp_rule_sets`,
+		"SELECT COUNT(*) FROM xp_rule_sets", q)
+	if err != nil {
+		log.Printf("Error fetching rule sets: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching rule sets"})
+		return
+	}
+
+	c.JSON(http.StatusOK, pageResponse("rule_sets", sets, total, nextCursor))
+}
+
+// @Summary     Create XP Rule Set
+// @Description This API stores an XP rule set, inactive, from a YAML or JSON body. Rules have rewards per action (signup, quiz_answer, quiz_speed), multipliers, daily_caps and hourly_caps per source, and limits on quizzes (quizzes_per_day, and quiz_cooldown in seconds). A multiplier has a name and a factor and applies while all of its conditions hold: sources, weekdays, min_streak (consecutive days with earned XP), from and until (campaigns) and season (a season ID). Multipliers applying together multiply. Rule sets cannot be edited; preview a new one and activate it instead. Admins only.
+// @Tags         XP Rules
+// @Accept       plain,json
+// @Produce      json
+// @Param        Authorization header string true "tma and the web app init data"
+// @Param        name       query  string true "Name of the rule set"
+// @Param        rules      body   xp.Rules true "Rules, as YAML or JSON"
+// @Success      201  {object} xp.RuleSet
+// @Failure      400  {object} ErrorResponse
+// @Failure      401  {object} ErrorResponse
+// @Failure      403  {object} ErrorResponse
+// @Failure      500  {object} ErrorResponse
+// @Router       /xp/rule-sets [post]
+func (h *HandlerV1) CreateXPRuleSet(c *gin.Context) {
+	name := strings.TrimSpace(c.Query("name"))
+	if name == "" || len(name) > 100 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name must be 1 to 100 characters"})
+		return
+	}
+	raw, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxRulesSize))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+	rules, err := xp.ParseRules(raw)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	set, err := h.rules.CreateRuleSet(c.Request.Context(), name, rules, callerID(c))
+	if err != nil {
+		ruleSetFailed(c, err, "creating")
+		return
+	}
+	c.JSON(http.StatusCreated, set)
+}
+
+// @Summary     Get XP Rule Set
+// @Description This API returns a stored XP rule set. Admins only.
+// @Tags         XP Rules
+// @Produce      json
+// @Param        Authorization header string true "tma and the web app init data"
+// @Param        id         path   int true "Rule set ID"
+// @Success      200  {object} xp.RuleSet
+// @Failure      400  {object} ErrorResponse
+// @Failure      401  {object} ErrorResponse
+// @Failure      403  {object} ErrorResponse
+// @Failure      404  {object} ErrorResponse
+// @Failure      500  {object} ErrorResponse
+// @Router       /xp/rule-sets/{id} [get]
+func (h *HandlerV1) GetXPRuleSet(c *gin.Context) {
+	id, ok := ruleSetID(c)
+	if !ok {
+		return
+	}
+	set, err := h.rules.RuleSet(c.Request.Context(), id)
+	if err != nil {
+		ruleSetFailed(c, err, "fetching")
+		return
+	}
+	c.JSON(http.StatusOK, set)
+}
+
+// @Summary     Preview XP Rule Set
+// @Description This API is a dry run of a rule set: it replays the XP earned in the last days under the rule set, without crediting anything, and compares it with the XP actually credited, per source. Base XP is scaled by the rule set's rewards relative to the rules in force, and multipliers and caps are applied anew. Admins only.
+// @Tags         XP Rules
+// @Produce      json
+// @Param        Authorization header string true  "tma and the web app init data"
+// @Param        id         path   int true  "Rule set ID"
+// @Param        days       query  int false "Days to replay, counting today (1-90, default 7)"
+// @Success      200  {object} xp.Preview
+// @Failure      400  {object} ErrorResponse
+// @Failure      401  {object} ErrorResponse
+// @Failure      403  {object} ErrorResponse
+// @Failure      404  {object} ErrorResponse
+// @Failure      500  {object} ErrorResponse
+// @Router       /xp/rule-sets/{id}/preview [post]
+func (h *HandlerV1) PreviewXPRuleSet(c *gin.Context) {
+	id, ok := ruleSetID(c)
+	if !ok {
+		return
+	}
+	days := 7
+	if raw := c.Query("days"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > xp.MaxPreviewDays {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "days must be between 1 and " + strconv.Itoa(xp.MaxPreviewDays)})
+			return
+		}
+		days = n
+	}
+
+	ctx := c.Request.Context()
+	set, err := h.rules.RuleSet(ctx, id)
+	if err != nil {
+		ruleSetFailed(c, err, "previewing")
+		return
+	}
+	preview, err := h.rules.Preview(ctx, set.Rules, days)
+	if err != nil {
+		ruleSetFailed(c, err, "previewing")
+		return
+	}
+	c.JSON(http.StatusOK, preview)
+}
+
+// @Summary     Activate XP Rule Set
+// @Description This API puts a rule set in force in place of the active one. XP earned from then on is credited under it. Admins only.
+// @Tags         XP Rules
+// @Produce      json
+// @Param        Authorization header string true "tma and the web app init data"
+// @Param        id         path   int true "Rule set ID"
+// @Success      200  {object} xp.RuleSet
+// @Failure      400  {object} ErrorResponse
+// @Failure      401  {object} ErrorResponse
+// @Failure      403  {object} ErrorResponse
+// @Failure      404  {object} ErrorResponse
+// @Failure      409  {object} ErrorResponse
+// @Failure      500  {object} ErrorResponse
+// @Router       /xp/rule-sets/{id}/activate [post]
+func (h *HandlerV1) ActivateXPRuleSet(c *gin.Context) {
+	id, ok := ruleSetID(c)
+	if !ok {
+		return
+	}
+	set, err := h.rules.Activate(c.Request.Context(), id, callerID(c))
+	if err != nil {
+		ruleSetFailed(c, err, "activating")
+		return
+	}
+	c.JSON(http.StatusOK, set)
+}
@@ -0,0 +1,219 @@
+package xp
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// Reasons accounts are flagged for.
+const (
+	// FlagEarningRate is earning far more XP than other users.
+	FlagEarningRate = "earning_rate"
+	// FlagFastQuiz is a perfect quiz score at an inhuman answer speed.
+	FlagFastQuiz = "fast_quiz"
+)
+
+// FlagConfirmed is the status of flags confirmed on review. Flags are open
+// until reviewed, and dismissed when they are false alarms.
+const FlagConfirmed = "confirmed"
+
+// ErrFlagNotFound is returned when reviewing a flag that is not open.
+var ErrFlagNotFound = errors.New("open flag not found")
+
+// AnomalyPolicy configures the anomaly detector.
+type AnomalyPolicy struct {
+	// Window is the period earning rates are compared over, and quizzes
+	// are checked for.
+	Window time.Duration
+	// Deviations is how many standard deviations above the mean XP earned
+	// in Window flag an account.
+	Deviations float64
+	// MinXP is the least XP earned in Window that is flagged, so quiet
+	// periods flag no one.
+	MinXP int64
+	// MinAnswerTime is the least time a person takes to answer a question.
+	// Perfect quizzes answered faster on average are flagged, timing each
+	// question of timed quizzes from when it was served.
+	MinAnswerTime time.Duration
+}
+
+// Flag is an account flagged by the anomaly detector, for admin review. It
+// covers the XP earned from StartedAt to EndedAt; for fast quizzes, that of
+// the quiz session Ref.
+type Flag struct {
+	ID        int64     `db:"id" json:"id"`
+	UserID    int64     `db:"user_id" json:"user_id"`
+	UserName  string    `db:"user_name" json:"user_name"`
+	Reason    string    `db:"reason" json:"reason"`
+	Ref       string    `db:"ref" json:"ref"`
+	Details   string    `db:"details" json:"details"`
+	StartedAt time.Time `db:"started_at" json:"started_at"`
+	EndedAt   time.Time `db:"ended_at" json:"ended_at"`
+	Status    string    `db:"status" json:"status"`
+	// ReversedXP is the XP taken back on review.
+	ReversedXP int64 `db:"reversed_xp" json:"reversed_xp"`
+	// Frozen reports whether the account is frozen now.
+	Frozen     bool       `db:"frozen" json:"frozen"`
+	ReviewedBy *int64     `db:"reviewed_by" json:"reviewed_by"`
+	ReviewedAt *time.Time `db:"reviewed_at" json:"reviewed_at"`
+	CreatedAt  time.Time  `db:"created_at" json:"created_at"`
+}
+
+// FlagsQuery selects Flags, to be filtered on their columns.
+const FlagsQuery = `SELECT * FROM (SELECT f.id, f.user_id, u.first_name || ' ' || u.last_name AS user_name,
+				f.reason, f.ref, f.details, f.started_at, f.ended_at, f.status, f.reversed_xp,
+				u.frozen_at IS NOT NULL AS frozen, f.reviewed_by, f.reviewed_at, f.created_at
+			  FROM xp_flags f JOIN users u ON u.id = f.user_id) flags`
+
+// Detector flags accounts whose XP looks farmed.
+type Detector struct {
+	db     *sqlx.DB
+	policy AnomalyPolicy
+}
+
+func NewDetector(db *sqlx.DB, policy AnomalyPolicy) *Detector {
+	return &Detector{db: db, policy: policy}
+}
+
+// Detect flags accounts that earned far more XP than others over the window,
+// at most once a day, and accounts with perfect quizzes answered faster than
+// a person can read them. It returns the number of new flags.
+func (d *Detector) Detect(ctx context.Context) (int64, error) {
+	now := time.Now()
+	res, err := d.db.ExecContext(ctx, `WITH earned AS (
+					SELECT user_id, SUM(amount) AS xp FROM xp_ledger
+					WHERE source = ANY($1) AND created_at >= $2 GROUP BY user_id
+				), stats AS (
+					SELECT AVG(xp) AS mean, COALESCE(STDDEV_POP(xp), 0) AS sd FROM earned
+				)
+				INSERT INTO xp_flags (user_id, reason, ref, details, started_at, ended_at)
+				SELECT e.user_id, $3, $4, format('earned %s XP in %s, against %s on average', e.xp, $5::text, ROUND(s.mean)),
+					$2, $6
+				FROM earned e, stats s WHERE e.xp >= $7 AND e.xp > s.mean + $8 * s.sd
+				ON CONFLICT (user_id, reason, ref) DO NOTHING`,
+		pq.Array(EarnedSources), now.Add(-d.policy.Window), FlagEarningRate, dayKey(now), d.policy.Window.String(),
+		now, d.policy.MinXP, d.policy.Deviations)
+	if err != nil {
+		return 0, err
+	}
+	flagged, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	// Timed sessions are timed from when each question was served to its
+	// answer, others from the start of the session to its submission.
+	res, err = d.db.ExecContext(ctx, `INSERT INTO xp_flags (user_id, reason, ref, details, started_at, ended_at)
+				SELECT user_id, $1, id::text, format('answered all %s questions correctly in %s seconds', n, ROUND(seconds)),
+					created_at, submitted_at
+				FROM (SELECT id, user_id, correct_count, jsonb_array_length(questions) AS n, created_at, submitted_at,
+						CASE WHEN timed THEN (SELECT EXTRACT(EPOCH FROM SUM(a.answered_at - a.served_at))
+								FROM quiz_answers a WHERE a.session_id = s.id)
+							ELSE EXTRACT(EPOCH FROM submitted_at - created_at) END AS seconds
+					FROM quiz_sessions s WHERE submitted_at >= $2) s
+				WHERE n > 0 AND correct_count = n AND seconds < n * $3
+				ON CONFLICT (user_id, reason, ref) DO NOTHING`,
+		FlagFastQuiz, now.Add(-d.policy.Window), d.policy.MinAnswerTime.Seconds())
+	if err != nil {
+		return flagged, err
+	}
+	n, err := res.RowsAffected()
+	return flagged + n, err
+}
+
+// ReviewFlag dismisses or confirms an open flag on behalf of an admin. A
+// confirmed flag can freeze the account and reverse the XP the flag covers.
+func (e *Engine) ReviewFlag(ctx context.Context, id, adminID int64, status string, freeze, reverse bool) (Flag, error) {
+	tx, err := e.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return Flag{}, err
+	}
+	defer tx.Rollback()
+
+	var flag Flag
+	_, err = tx.ExecContext(ctx, `SELECT 1 FROM xp_flags WHERE id = $1 FOR UPDATE`, id)
+	if err == nil {
+		err = tx.GetContext(ctx, &flag, FlagsQuery+` WHERE id = $1 AND status = 'open'`, id)
+	}
+	if err == sql.ErrNoRows {
+		return flag, ErrFlagNotFound
+	}
+	if err != nil {
+		return flag, err
+	}
+
+	var reversed int64
+	if status == FlagConfirmed {
+		if freeze {
+			if err := setFrozen(ctx, tx, flag.UserID, adminID, true); err != nil {
+				return flag, err
+			}
+		}
+		if reverse {
+			if reversed, err = reverseFlag(ctx, tx, flag); err != nil {
+				return flag, err
+			}
+		}
+	}
+	_, err = tx.ExecContext(ctx, `UPDATE xp_flags SET status = $2, reversed_xp = $3, reviewed_by = $4, reviewed_at = now()
+				WHERE id = $1`, id, status, reversed, adminID)
+	if err != nil {
+		return flag, err
+	}
+	err = tx.GetContext(ctx, &flag, FlagsQuery+` WHERE id = $1`, id)
+	if err != nil {
+		return flag, err
+	}
+	return flag, tx.Commit()
+}
+
+// SetFrozen freezes or unfreezes an account on behalf of an admin.
+func (e *Engine) SetFrozen(ctx context.Context, userID, adminID int64, frozen bool) error {
+	return setFrozen(ctx, e.db, userID, adminID, frozen)
+}
+
+func setFrozen(ctx context.Context, ex Execer, userID, adminID int64, frozen bool) error {
+	res, err := ex.ExecContext(ctx, `UPDATE users SET
+					frozen_at = CASE WHEN $2 THEN COALESCE(frozen_at, now()) END,
+					frozen_by = CASE WHEN $2 THEN COALESCE(frozen_by, $3) END
+				WHERE id = $1`, userID, frozen, adminID)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+// reverseFlag takes back the earned XP a flag covers that was not taken back
+// before, and returns it. XP already spent leaves the balance negative.
+func reverseFlag(ctx context.Context, tx *sqlx.Tx, flag Flag) (int64, error) {
+	covered := `created_at BETWEEN $3 AND $4`
+	args := []interface{}{flag.UserID, pq.Array(RuledSources), flag.StartedAt, flag.EndedAt}
+	if flag.Reason == FlagFastQuiz {
+		covered = `source = $3 AND ref = $4`
+		args = []interface{}{flag.UserID, pq.Array(RuledSources), SourceQuiz, flag.Ref}
+	}
+	var total int64
+	err := tx.GetContext(ctx, &total, `WITH reversed AS (
+					UPDATE xp_ledger SET reversed_at = now()
+					WHERE user_id = $1 AND source = ANY($2) AND amount > 0 AND reversed_at IS NULL AND `+covered+`
+					RETURNING amount
+				)
+				SELECT COALESCE(SUM(amount), 0) FROM reversed`, args...)
+	if err != nil || total == 0 {
+		return 0, err
+	}
+	return total, Apply(ctx, tx, flag.UserID, -total, SourceReversal, "flag:"+strconv.FormatInt(flag.ID, 10))
+}
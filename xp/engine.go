@@ -0,0 +1,479 @@
+package xp
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// Origins of the active rules.
+const (
+	OriginRuleSet = "rule_set"
+	OriginFile    = "file"
+	OriginDefault = "default"
+)
+
+// MaxPreviewDays bounds the days of XP a preview replays.
+const MaxPreviewDays = 90
+
+var (
+	ErrRuleSetNotFound = errors.New("rule set not found")
+	ErrRuleSetActive   = errors.New("rule set is already active")
+)
+
+// Queryer is satisfied by *sql.DB, *sql.Tx, *sqlx.DB and *sqlx.Tx.
+type Queryer interface {
+	Execer
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// ActiveRules are the rules in force and where they come from.
+type ActiveRules struct {
+	// RuleSetID is the active rule set, nil unless Origin is rule_set.
+	RuleSetID *int64 `json:"rule_set_id"`
+	Origin    string `json:"origin"`
+	Rules     Rules  `json:"rules"`
+}
+
+// Award is XP credited under the active rules.
+type Award struct {
+	Base float64 `json:"base"`
+	// Factor is the product of the factors of Multipliers, the names of the
+	// multipliers applied.
+	Factor      float64  `json:"factor"`
+	Multipliers []string `json:"multipliers"`
+	// Capped reports whether a cap of the source cut the award.
+	Capped bool `json:"capped"`
+	// Frozen reports that the account is frozen and earned nothing.
+	Frozen bool  `json:"frozen"`
+	Amount int64 `json:"amount"`
+}
+
+// Engine credits earned XP under the active rules: the active rule set, or
+// the rules file, or DefaultRules. Rule sets are stored in the database and
+// previewed before they are activated.
+type Engine struct {
+	db       *sqlx.DB
+	origin   string
+	fallback Rules
+
+	mu       sync.RWMutex
+	active   ActiveRules
+	compiled *compiled
+}
+
+// NewEngine returns an engine whose rules, until a rule set is activated, are
+// read from a YAML file, or DefaultRules when file is empty.
+func NewEngine(db *sqlx.DB, file string) (*Engine, error) {
+	e := &Engine{db: db, origin: OriginDefault, fallback: DefaultRules}
+	if file != "" {
+		rules, err := ReadRulesFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %v", file, err)
+		}
+		e.origin, e.fallback = OriginFile, rules
+	}
+	e.active = ActiveRules{Origin: e.origin, Rules: e.fallback}
+	e.compiled = &compiled{Rules: e.fallback, seasons: map[int]window{}}
+	return e, nil
+}
+
+// Reload loads the active rules, picking up rule sets activated by other
+// instances and changes to seasons.
+func (e *Engine) Reload(ctx context.Context) error {
+	active := ActiveRules{Origin: e.origin, Rules: e.fallback}
+	var set RuleSet
+	err := e.db.GetContext(ctx, &set, `SELECT `+RuleSetColumns+` FROM xp_rule_sets WHERE active`)
+	switch {
+	case err == nil:
+		active = ActiveRules{RuleSetID: &set.ID, Origin: OriginRuleSet, Rules: set.Rules}
+	case err != sql.ErrNoRows:
+		return err
+	}
+	c, err := compile(ctx, e.db, active.Rules)
+	if err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	e.active, e.compiled = active, c
+	e.mu.Unlock()
+	return nil
+}
+
+// Active returns the rules in force.
+func (e *Engine) Active() ActiveRules {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.active
+}
+
+// Reward returns the XP of an action under the active rules.
+func (e *Engine) Reward(action string) float64 {
+	return e.Active().Rules.Rewards[action]
+}
+
+func (e *Engine) current() (*compiled, *int64) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.compiled, e.active.RuleSetID
+}
+
+// Award credits a user base XP earned from a source, times the multipliers
+// that apply and within the caps of the source, and records the base and the
+// rule set in the ledger. Frozen accounts are credited nothing. The user is
+// locked until q's transaction ends, so concurrent awards respect the caps.
+func (e *Engine) Award(ctx context.Context, q Queryer, userID int64, base float64, source, ref string) (Award, error) {
+	c, ruleSetID := e.current()
+	at := time.Now()
+
+	var frozen bool
+	err := q.QueryRowContext(ctx, `SELECT frozen_at IS NOT NULL FROM users WHERE id = $1 FOR UPDATE`, userID).Scan(&frozen)
+	if err == sql.ErrNoRows {
+		return Award{}, ErrUserNotFound
+	}
+	if err != nil {
+		return Award{}, err
+	}
+	if frozen {
+		return Award{Base: base, Factor: 1, Multipliers: []string{}, Frozen: true}, nil
+	}
+
+	userStreak := 0
+	if needed := c.streakNeeded(source); needed > 0 {
+		days, err := activeDays(ctx, q, userID, DayStart(at).AddDate(0, 0, 1-needed))
+		if err != nil {
+			return Award{}, err
+		}
+		days[dayKey(at)] = true
+		userStreak = streak(days, at, needed)
+	}
+
+	award := Award{Base: base}
+	award.Factor, award.Multipliers = c.factor(source, at, userStreak)
+	award.Amount = int64(math.Round(base * award.Factor))
+	if award.Amount < 0 {
+		award.Amount = 0
+	}
+	periods := []struct {
+		caps  map[string]int64
+		since time.Time
+	}{{c.DailyCaps, DayStart(at)}, {c.HourlyCaps, at.Add(-time.Hour)}}
+	for _, period := range periods {
+		limit, ok := period.caps[source]
+		if !ok {
+			continue
+		}
+		var earned int64
+		err := q.QueryRowContext(ctx, `SELECT COALESCE(SUM(amount), 0) FROM xp_ledger
+					WHERE user_id = $1 AND source = $2 AND created_at >= $3`, userID, source, period.since).Scan(&earned)
+		if err != nil {
+			return Award{}, err
+		}
+		if left := limit - earned; award.Amount > left {
+			award.Amount, award.Capped = max(left, 0), true
+		}
+	}
+
+	if award.Amount > 0 {
+		if err := apply(ctx, q, userID, award.Amount, source, ref, &base, ruleSetID); err != nil {
+			return Award{}, err
+		}
+	}
+	return award, nil
+}
+
+// activeDays returns the local days since a time on which a user earned XP.
+func activeDays(ctx context.Context, q Queryer, userID int64, since time.Time) (map[string]bool, error) {
+	rows, err := q.QueryContext(ctx, `SELECT created_at FROM xp_ledger
+				WHERE user_id = $1 AND source = ANY($2) AND amount > 0 AND created_at >= $3`,
+		userID, pq.Array(RuledSources), since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	days := map[string]bool{}
+	for rows.Next() {
+		var at time.Time
+		if err := rows.Scan(&at); err != nil {
+			return nil, err
+		}
+		days[dayKey(at)] = true
+	}
+	return days, rows.Err()
+}
+
+// compile looks up the seasons rules refer to. Seasons that no longer exist
+// are left out, so their multipliers never apply.
+func compile(ctx context.Context, db *sqlx.DB, rules Rules) (*compiled, error) {
+	c := &compiled{Rules: rules, seasons: map[int]window{}}
+	var ids []int64
+	for _, m := range rules.Multipliers {
+		if m.Season != 0 {
+			ids = append(ids, int64(m.Season))
+		}
+	}
+	if len(ids) == 0 {
+		return c, nil
+	}
+	var seasons []struct {
+		ID int `db:"id"`
+		window
+	}
+	err := db.SelectContext(ctx, &seasons, `SELECT id, starts_at, ends_at FROM seasons WHERE id = ANY($1)`, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range seasons {
+		c.seasons[s.ID] = s.window
+	}
+	return c, nil
+}
+
+// RuleSet is a stored set of rules. Rule sets are not edited; a new one is
+// created and activated instead, keeping the rules of past XP on record.
+type RuleSet struct {
+	ID          int64      `db:"id" json:"id"`
+	Name        string     `db:"name" json:"name"`
+	Rules       Rules      `db:"rules" json:"rules"`
+	Active      bool       `db:"active" json:"active"`
+	CreatedBy   *int64     `db:"created_by" json:"created_by"`
+	CreatedAt   time.Time  `db:"created_at" json:"created_at"`
+	ActivatedBy *int64     `db:"activated_by" json:"activated_by"`
+	ActivatedAt *time.Time `db:"activated_at" json:"activated_at"`
+}
+
+// RuleSetColumns selects a RuleSet from the xp_rule_sets table.
+const RuleSetColumns = `id, name, rules, active, created_by, created_at, activated_by, activated_at`
+
+// CreateRuleSet stores rules, inactive, on behalf of an admin.
+func (e *Engine) CreateRuleSet(ctx context.Context, name string, rules Rules, userID int64) (RuleSet, error) {
+	if err := rules.Validate(); err != nil {
+		return RuleSet{}, err
+	}
+	c, err := compile(ctx, e.db, rules)
+	if err != nil {
+		return RuleSet{}, err
+	}
+	for _, m := range rules.Multipliers {
+		if _, ok := c.seasons[m.Season]; m.Season != 0 && !ok {
+			return RuleSet{}, fmt.Errorf("%w: multiplier %q applies during unknown season %d", ErrInvalidRules, m.Name, m.Season)
+		}
+	}
+
+	var set RuleSet
+	err = e.db.GetContext(ctx, &set, `INSERT INTO xp_rule_sets (name, rules, created_by) VALUES ($1, $2, $3)
+				RETURNING `+RuleSetColumns, name, rules, userID)
+	return set, err
+}
+
+// RuleSet returns a stored rule set.
+func (e *Engine) RuleSet(ctx context.Context, id int64) (RuleSet, error) {
+	var set RuleSet
+	err := e.db.GetContext(ctx, &set, `SELECT `+RuleSetColumns+` FROM xp_rule_sets WHERE id = $1`, id)
+	if err == sql.ErrNoRows {
+		return set, ErrRuleSetNotFound
+	}
+	return set, err
+}
+
+// Activate puts a rule set in force in place of the active one, on behalf of
+// an admin. Other instances pick it up on their next Reload.
+func (e *Engine) Activate(ctx context.Context, id, userID int64) (RuleSet, error) {
+	tx, err := e.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return RuleSet{}, err
+	}
+	defer tx.Rollback()
+
+	var set RuleSet
+	err = tx.GetContext(ctx, &set, `SELECT `+RuleSetColumns+` FROM xp_rule_sets WHERE id = $1 FOR UPDATE`, id)
+	switch {
+	case err == sql.ErrNoRows:
+		return set, ErrRuleSetNotFound
+	case err != nil:
+		return set, err
+	case set.Active:
+		return set, ErrRuleSetActive
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE xp_rule_sets SET active = false WHERE active`); err != nil {
+		return RuleSet{}, err
+	}
+	err = tx.GetContext(ctx, &set, `UPDATE xp_rule_sets SET active = true, activated_by = $2, activated_at = now()
+				WHERE id = $1 RETURNING `+RuleSetColumns, id, userID)
+	if err != nil {
+		return RuleSet{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return RuleSet{}, err
+	}
+	return set, e.Reload(ctx)
+}
+
+// Preview is the outcome of a dry run of rules over the XP earned in a
+// period: the XP actually credited and the XP the rules would have credited.
+type Preview struct {
+	From    time.Time       `json:"from"`
+	Until   time.Time       `json:"until"`
+	Sources []SourcePreview `json:"sources"`
+	Actual  int64           `json:"actual"`
+	Preview int64           `json:"preview"`
+	// Users is the number of users whose XP would differ.
+	Users int `json:"users"`
+}
+
+// SourcePreview is the part of a Preview earned from a source.
+type SourcePreview struct {
+	Source  string `json:"source"`
+	Entries int    `json:"entries"`
+	Actual  int64  `json:"actual"`
+	Preview int64  `json:"preview"`
+}
+
+// actionOf is the action whose reward sets the base XP of a source.
+var actionOf = map[string]string{SourceSignup: ActionSignup, SourceQuiz: ActionQuizAnswer}
+
+// Preview replays the XP earned in the last days under rules, without
+// crediting anything. The base XP of each entry is scaled by the ratio of the
+// rules' reward for its action to the active one, and multipliers and caps
+// are applied anew.
+func (e *Engine) Preview(ctx context.Context, rules Rules, days int) (Preview, error) {
+	if err := rules.Validate(); err != nil {
+		return Preview{}, err
+	}
+	c, err := compile(ctx, e.db, rules)
+	if err != nil {
+		return Preview{}, err
+	}
+	active := e.Active().Rules
+	until := time.Now()
+	from := DayStart(until).AddDate(0, 0, 1-days)
+	p := Preview{From: from, Until: until, Sources: []SourcePreview{}}
+
+	var entries []struct {
+		UserID    int64     `db:"user_id"`
+		Amount    int64     `db:"amount"`
+		Base      float64   `db:"base"`
+		Source    string    `db:"source"`
+		CreatedAt time.Time `db:"created_at"`
+	}
+	err = e.db.SelectContext(ctx, &entries, `SELECT user_id, amount, COALESCE(base, amount) AS base, source, created_at
+				FROM xp_ledger WHERE source = ANY($1) AND created_at >= $2 AND created_at < $3
+				ORDER BY created_at, id`, pq.Array(RuledSources), from, until)
+	if err != nil {
+		return p, err
+	}
+
+	// Days users earned XP on, from as far back as streaks reach.
+	streaks := map[int64]map[string]bool{}
+	needed := 0
+	for _, source := range RuledSources {
+		if n := c.streakNeeded(source); n > needed {
+			needed = n
+		}
+	}
+	if needed > 0 {
+		var earlier []struct {
+			UserID    int64     `db:"user_id"`
+			CreatedAt time.Time `db:"created_at"`
+		}
+		err = e.db.SelectContext(ctx, &earlier, `SELECT user_id, created_at FROM xp_ledger
+					WHERE source = ANY($1) AND amount > 0 AND created_at >= $2 AND created_at < $3`,
+			pq.Array(RuledSources), from.AddDate(0, 0, 1-needed), from)
+		if err != nil {
+			return p, err
+		}
+		for _, row := range earlier {
+			markDay(streaks, row.UserID, row.CreatedAt)
+		}
+		for _, entry := range entries {
+			if entry.Amount > 0 {
+				markDay(streaks, entry.UserID, entry.CreatedAt)
+			}
+		}
+	}
+
+	bySource := map[string]*SourcePreview{}
+	earned := map[string]int64{}
+	lastHour := map[string][]credited{}
+	diff := map[int64]int64{}
+	for _, entry := range entries {
+		base := entry.Base
+		if action, ok := actionOf[entry.Source]; ok {
+			switch {
+			case entry.Source == SourceSignup:
+				base = rules.Rewards[action]
+			case active.Rewards[action] > 0:
+				base *= rules.Rewards[action] / active.Rewards[action]
+			}
+		}
+		factor, _ := c.factor(entry.Source, entry.CreatedAt, streak(streaks[entry.UserID], entry.CreatedAt, c.streakNeeded(entry.Source)))
+		amount := int64(math.Round(base * factor))
+		if amount < 0 {
+			amount = 0
+		}
+		day := fmt.Sprintf("%d/%s/%s", entry.UserID, entry.Source, dayKey(entry.CreatedAt))
+		if limit, ok := c.DailyCaps[entry.Source]; ok {
+			amount = min(amount, max(limit-earned[day], 0))
+		}
+		hour := fmt.Sprintf("%d/%s", entry.UserID, entry.Source)
+		recent := lastHour[hour]
+		for len(recent) > 0 && recent[0].at.Before(entry.CreatedAt.Add(-time.Hour)) {
+			recent = recent[1:]
+		}
+		if limit, ok := c.HourlyCaps[entry.Source]; ok {
+			var sum int64
+			for _, r := range recent {
+				sum += r.amount
+			}
+			amount = min(amount, max(limit-sum, 0))
+		}
+		earned[day] += amount
+		lastHour[hour] = append(recent, credited{entry.CreatedAt, amount})
+
+		s, ok := bySource[entry.Source]
+		if !ok {
+			s = &SourcePreview{Source: entry.Source}
+			bySource[entry.Source] = s
+		}
+		s.Entries++
+		s.Actual += entry.Amount
+		s.Preview += amount
+		p.Actual += entry.Amount
+		p.Preview += amount
+		diff[entry.UserID] += amount - entry.Amount
+	}
+
+	for _, s := range bySource {
+		p.Sources = append(p.Sources, *s)
+	}
+	sort.Slice(p.Sources, func(i, j int) bool { return p.Sources[i].Source < p.Sources[j].Source })
+	for _, d := range diff {
+		if d != 0 {
+			p.Users++
+		}
+	}
+	return p, nil
+}
+
+// credited is XP credited in a replay.
+type credited struct {
+	at     time.Time
+	amount int64
+}
+
+func markDay(days map[int64]map[string]bool, userID int64, at time.Time) {
+	if days[userID] == nil {
+		days[userID] = map[string]bool{}
+	}
+	days[userID][dayKey(at)] = true
+}
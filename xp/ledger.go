@@ -0,0 +1,68 @@
+// Package xp changes users' XP and records every change in the xp_ledger
+// table, which periodic leaderboards and seasons are computed from.
+package xp
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+const (
+	SourceSignup = "signup"
+	SourceQuiz   = "quiz"
+	SourceEvent  = "event"
+	SourceOrder  = "order"
+	SourceRefund = "refund"
+	// SourceSeasonPrize is XP awarded to the top users of a closed season.
+	SourceSeasonPrize = "season_prize"
+	// SourceReversal is earned XP taken back from a flagged account.
+	SourceReversal = "reversal"
+)
+
+// EarnedSources are the sources that count as earned XP, reversals taking
+// it back. Spending XP in the market, getting it back on a refund and season
+// prizes do not count.
+var EarnedSources = []string{SourceSignup, SourceQuiz, SourceEvent, SourceReversal}
+
+// RuledSources are the sources XP rules apply to.
+var RuledSources = []string{SourceSignup, SourceQuiz, SourceEvent}
+
+var (
+	// ErrUserNotFound is returned when the user whose XP changes does not
+	// exist.
+	ErrUserNotFound = errors.New("user not found")
+	// ErrAccountFrozen is returned for frozen accounts, which earn no XP.
+	ErrAccountFrozen = errors.New("account is frozen")
+)
+
+// Execer is satisfied by *sql.DB, *sql.Tx, *sqlx.DB and *sqlx.Tx.
+type Execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// Apply adds amount (negative to spend) to the user's XP and writes the
+// ledger row in the same statement. ref identifies what caused the change,
+// e.g. an order or event ID.
+func Apply(ctx context.Context, ex Execer, userID, amount int64, source, ref string) error {
+	return apply(ctx, ex, userID, amount, source, ref, nil, nil)
+}
+
+// apply is Apply recording the base XP and the rule set of earned XP.
+func apply(ctx context.Context, ex Execer, userID, amount int64, source, ref string, base *float64, ruleSetID *int64) error {
+	query := `WITH u AS (UPDATE users SET xp = xp + $2 WHERE id = $1 RETURNING id)
+			  INSERT INTO xp_ledger (user_id, amount, source, ref, base, rule_set_id)
+			  SELECT id, $2, $3, $4, $5::double precision, $6::integer FROM u`
+	res, err := ex.ExecContext(ctx, query, userID, amount, source, ref, base, ruleSetID)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
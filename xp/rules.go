@@ -0,0 +1,283 @@
+package xp
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Actions rewarded by rule sets.
+const (
+	// ActionSignup is registering through the bot.
+	ActionSignup = "signup"
+	// ActionQuizAnswer is a correct quiz answer to a question at the user's
+	// rating. Harder questions earn up to twice as much, easier ones less.
+	ActionQuizAnswer = "quiz_answer"
+	// ActionQuizSpeed is a speed bonus for a correct answer to a question of
+	// a timed quiz, earned in proportion to the share of the time limit left.
+	ActionQuizSpeed = "quiz_speed"
+)
+
+var actions = []string{ActionSignup, ActionQuizAnswer, ActionQuizSpeed}
+
+// ErrInvalidRules is returned for rules that cannot be used.
+var ErrInvalidRules = errors.New("invalid rules")
+
+// maxStreak bounds the streak multipliers can require, in days.
+const maxStreak = 365
+
+// Rules decide the XP earned: the reward of each action, multipliers applied
+// to earned XP, caps on the XP earned from a source per day and per hour,
+// and limits on earning actions. Events earn their own total_xp, subject to
+// multipliers and caps. Spent, refunded and prize XP is not subject to rules.
+type Rules struct {
+	// Rewards is the XP of each action.
+	Rewards map[string]float64 `yaml:"rewards" json:"rewards"`
+	// Multipliers apply together, multiplying their factors.
+	Multipliers []Multiplier `yaml:"multipliers" json:"multipliers"`
+	// DailyCaps bound the XP a user earns from a source in a day.
+	DailyCaps map[string]int64 `yaml:"daily_caps" json:"daily_caps"`
+	// HourlyCaps bound the XP a user earns from a source in the last hour.
+	HourlyCaps map[string]int64 `yaml:"hourly_caps" json:"hourly_caps"`
+	Limits     Limits           `yaml:"limits" json:"limits"`
+}
+
+// Limits bound how often users take earning actions. Zero is unlimited.
+type Limits struct {
+	// QuizzesPerDay bounds the quizzes a user starts in a day, event quizzes
+	// aside.
+	QuizzesPerDay int `yaml:"quizzes_per_day" json:"quizzes_per_day"`
+	// QuizCooldown is the least number of seconds between starting quizzes.
+	QuizCooldown int `yaml:"quiz_cooldown" json:"quiz_cooldown"`
+}
+
+// Multiplier multiplies earned XP by Factor while all of its conditions hold.
+// Conditions left empty always hold.
+type Multiplier struct {
+	Name   string  `yaml:"name" json:"name"`
+	Factor float64 `yaml:"factor" json:"factor"`
+	// Sources are the sources it applies to, all of RuledSources when empty.
+	Sources []string `yaml:"sources" json:"sources,omitempty"`
+	// Weekdays are English day names, e.g. saturday.
+	Weekdays []string `yaml:"weekdays" json:"weekdays,omitempty"`
+	// MinStreak is the number of consecutive days, ending with the day of the
+	// award, on which the user earned XP.
+	MinStreak int `yaml:"min_streak" json:"min_streak,omitempty"`
+	// From and Until bound campaigns. Until is exclusive.
+	From  *time.Time `yaml:"from" json:"from,omitempty"`
+	Until *time.Time `yaml:"until" json:"until,omitempty"`
+	// Season is the ID of a season it applies during.
+	Season int `yaml:"season" json:"season,omitempty"`
+}
+
+// DefaultRules apply when neither a rule set is active nor a rules file is
+// configured.
+var DefaultRules = Rules{
+	Rewards: map[string]float64{ActionSignup: 5, ActionQuizAnswer: 1},
+}
+
+// ParseRules reads rules from YAML, or JSON, and validates them.
+func ParseRules(raw []byte) (Rules, error) {
+	var r Rules
+	decoder := yaml.NewDecoder(bytes.NewReader(raw))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&r); err != nil {
+		return r, fmt.Errorf("%w: %v", ErrInvalidRules, err)
+	}
+	return r, r.Validate()
+}
+
+// ReadRulesFile reads the rules of a YAML file.
+func ReadRulesFile(path string) (Rules, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return Rules{}, err
+	}
+	return ParseRules(raw)
+}
+
+// Validate normalizes the rules and checks that they refer to known actions,
+// sources and weekdays.
+func (r *Rules) Validate() error {
+	for action, reward := range r.Rewards {
+		if !contains(actions, action) {
+			return fmt.Errorf("%w: unknown action %q, want one of %s", ErrInvalidRules, action, strings.Join(actions, ", "))
+		}
+		if reward < 0 || math.IsNaN(reward) || math.IsInf(reward, 0) {
+			return fmt.Errorf("%w: reward of %s must not be negative", ErrInvalidRules, action)
+		}
+	}
+	for name, caps := range map[string]map[string]int64{"daily": r.DailyCaps, "hourly": r.HourlyCaps} {
+		for source, limit := range caps {
+			if !contains(RuledSources, source) {
+				return fmt.Errorf("%w: %s cap of unknown source %q", ErrInvalidRules, name, source)
+			}
+			if limit < 0 {
+				return fmt.Errorf("%w: %s cap of %s must not be negative", ErrInvalidRules, name, source)
+			}
+		}
+	}
+	if r.Limits.QuizzesPerDay < 0 || r.Limits.QuizCooldown < 0 {
+		return fmt.Errorf("%w: limits must not be negative", ErrInvalidRules)
+	}
+
+	names := map[string]bool{}
+	for i := range r.Multipliers {
+		m := &r.Multipliers[i]
+		m.Name = strings.TrimSpace(m.Name)
+		switch {
+		case m.Name == "":
+			return fmt.Errorf("%w: multiplier %d has no name", ErrInvalidRules, i+1)
+		case names[m.Name]:
+			return fmt.Errorf("%w: multiplier %q is defined twice", ErrInvalidRules, m.Name)
+		case m.Factor <= 0 || math.IsNaN(m.Factor) || math.IsInf(m.Factor, 0):
+			return fmt.Errorf("%w: factor of multiplier %q must be positive", ErrInvalidRules, m.Name)
+		case m.MinStreak < 0 || m.MinStreak > maxStreak:
+			return fmt.Errorf("%w: min_streak of multiplier %q must be between 0 and %d", ErrInvalidRules, m.Name, maxStreak)
+		case m.From != nil && m.Until != nil && !m.Until.After(*m.From):
+			return fmt.Errorf("%w: multiplier %q ends before it starts", ErrInvalidRules, m.Name)
+		case m.Season < 0:
+			return fmt.Errorf("%w: multiplier %q has an invalid season", ErrInvalidRules, m.Name)
+		}
+		names[m.Name] = true
+		for _, source := range m.Sources {
+			if !contains(RuledSources, source) {
+				return fmt.Errorf("%w: multiplier %q applies to unknown source %q", ErrInvalidRules, m.Name, source)
+			}
+		}
+		for j, day := range m.Weekdays {
+			m.Weekdays[j] = strings.ToLower(strings.TrimSpace(day))
+			if _, ok := weekdays[m.Weekdays[j]]; !ok {
+				return fmt.Errorf("%w: multiplier %q has unknown weekday %q", ErrInvalidRules, m.Name, day)
+			}
+		}
+	}
+	return nil
+}
+
+// Value stores rules as JSON.
+func (r Rules) Value() (driver.Value, error) {
+	return json.Marshal(r)
+}
+
+// Scan reads rules stored as JSON.
+func (r *Rules) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case []byte:
+		return json.Unmarshal(v, r)
+	case string:
+		return json.Unmarshal([]byte(v), r)
+	}
+	return errors.New("rules must be JSON")
+}
+
+var weekdays = map[string]time.Weekday{}
+
+func init() {
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		weekdays[strings.ToLower(day.String())] = day
+	}
+}
+
+// window is the time span of a season.
+type window struct {
+	StartsAt time.Time `db:"starts_at"`
+	EndsAt   time.Time `db:"ends_at"`
+}
+
+// compiled are rules with the seasons they refer to.
+type compiled struct {
+	Rules
+	seasons map[int]window
+}
+
+// applies reports whether a multiplier applies to XP from a source earned at
+// a time by a user with a streak.
+func (c *compiled) applies(m Multiplier, source string, at time.Time, streak int) bool {
+	if len(m.Sources) > 0 && !contains(m.Sources, source) {
+		return false
+	}
+	if len(m.Weekdays) > 0 && !contains(m.Weekdays, strings.ToLower(at.In(time.Local).Weekday().String())) {
+		return false
+	}
+	if streak < m.MinStreak {
+		return false
+	}
+	if (m.From != nil && at.Before(*m.From)) || (m.Until != nil && !at.Before(*m.Until)) {
+		return false
+	}
+	if m.Season != 0 {
+		w, ok := c.seasons[m.Season]
+		if !ok || at.Before(w.StartsAt) || !at.Before(w.EndsAt) {
+			return false
+		}
+	}
+	return true
+}
+
+// factor returns the product of the multipliers applying to XP from a source
+// earned at a time by a user with a streak, and their names.
+func (c *compiled) factor(source string, at time.Time, streak int) (float64, []string) {
+	factor := 1.0
+	names := []string{}
+	if !contains(RuledSources, source) {
+		return factor, names
+	}
+	for _, m := range c.Multipliers {
+		if c.applies(m, source, at, streak) {
+			factor *= m.Factor
+			names = append(names, m.Name)
+		}
+	}
+	return factor, names
+}
+
+// streakNeeded returns the longest streak a multiplier applying to a source
+// requires.
+func (c *compiled) streakNeeded(source string) int {
+	needed := 0
+	for _, m := range c.Multipliers {
+		if (len(m.Sources) == 0 || contains(m.Sources, source)) && m.MinStreak > needed {
+			needed = m.MinStreak
+		}
+	}
+	return needed
+}
+
+// DayStart returns the start of the local day of t. Daily caps and limits
+// count from it.
+func DayStart(t time.Time) time.Time {
+	y, m, d := t.In(time.Local).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.Local)
+}
+
+func dayKey(t time.Time) string {
+	return t.In(time.Local).Format("2006-01-02")
+}
+
+// streak returns the number of consecutive days, ending with the day of at,
+// found in days, up to limit.
+func streak(days map[string]bool, at time.Time, limit int) int {
+	n := 0
+	for day := DayStart(at); n < limit && days[dayKey(day)]; day = day.AddDate(0, 0, -1) {
+		n++
+	}
+	return n
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}